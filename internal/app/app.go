@@ -7,7 +7,9 @@ import (
 	"partiuFit/internal/handlers"
 	"partiuFit/internal/middlewares"
 	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
 	"partiuFit/migrations"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -48,6 +50,12 @@ func NewApplication(logger *zap.SugaredLogger) (*Application, error) {
 	errorHandlerMiddleware := middlewares.NewErrorHandlerMiddleware(logger)
 	securityMiddleware := middlewares.NewSecurityMiddleware(logger)
 
+	sessionTimeout := utils.ReadDurationEnv("SESSION_TIMEOUT", store.DefaultSessionTimeout)
+	go closeAbandonedSessions(appStore.WorkoutSessionStore, sessionTimeout, logger)
+
+	trashRetention := utils.ReadDurationEnv("TRASH_RETENTION", store.DefaultTrashRetention)
+	go purgeDeletedWorkouts(appStore.WorkoutStore, trashRetention, logger)
+
 	app := &Application{
 		Logger:   logger,
 		Handlers: appHandlers,
@@ -61,3 +69,43 @@ func NewApplication(logger *zap.SugaredLogger) (*Application, error) {
 
 	return app, nil
 }
+
+// closeAbandonedSessions finishes, once a minute, the live workout sessions
+// left without activity for longer than timeout.
+func closeAbandonedSessions(sessionStore store.WorkoutSessionStore, timeout time.Duration, logger *zap.SugaredLogger) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		closed, err := sessionStore.CloseAbandonedSessions(timeout)
+
+		if err != nil {
+			logger.Error("failed to close abandoned workout sessions", zap.Error(err))
+			continue
+		}
+
+		if closed > 0 {
+			logger.Info("closed abandoned workout sessions", zap.Int("count", closed))
+		}
+	}
+}
+
+// purgeDeletedWorkouts removes, once an hour, the workouts that have been in
+// the trash for longer than retention.
+func purgeDeletedWorkouts(workoutStore store.WorkoutStore, retention time.Duration, logger *zap.SugaredLogger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		purged, err := workoutStore.PurgeDeletedWorkouts(time.Now().Add(-retention))
+
+		if err != nil {
+			logger.Error("failed to purge deleted workouts", zap.Error(err))
+			continue
+		}
+
+		if purged > 0 {
+			logger.Info("purged deleted workouts", zap.Int("count", purged))
+		}
+	}
+}
@@ -44,8 +44,11 @@ func TeardownTestDB(db *sql.DB) error {
 
 	return db.Close()
 }
+
+// truncateTables keeps the global exercise catalog seeded by the migrations,
+// so users are deleted row by row instead of truncated with cascade.
 func truncateTables(db *sql.DB) error {
-	_, err := db.Exec("truncate workouts, workout_entries, users cascade")
+	_, err := db.Exec("truncate workouts, workout_entries, tokens cascade; delete from users")
 
 	if err != nil {
 		return fmt.Errorf("failed to truncate tables: %w", err)
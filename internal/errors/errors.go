@@ -8,12 +8,23 @@ import (
 )
 
 var (
-	ErrNoRows             = sql.ErrNoRows
-	ErrUserAlreadyExists  = errors.New("já existe um usuário com esse username ou email")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrForbidden          = errors.New("você não tem permissao para realizar essa operação")
-	ErrInvalidIDParam     = errors.New("parametro de id invalido")
-	ErrInvalidIDType      = errors.New("tipo de id invalido")
+	ErrNoRows                   = sql.ErrNoRows
+	ErrUserAlreadyExists        = errors.New("já existe um usuário com esse username ou email")
+	ErrInvalidCredentials       = errors.New("invalid credentials")
+	ErrForbidden                = errors.New("você não tem permissao para realizar essa operação")
+	ErrInvalidIDParam           = errors.New("parametro de id invalido")
+	ErrInvalidIDType            = errors.New("tipo de id invalido")
+	ErrInvalidQueryParam        = errors.New("parametro de consulta invalido")
+	ErrInvalidCursor            = errors.New("cursor de paginação invalido")
+	ErrExerciseExists           = errors.New("já existe um exercício com esse nome")
+	ErrInvalidEntryGroup        = errors.New("agrupamento de exercícios inválido")
+	ErrInvalidEntryOrder        = errors.New("a nova ordem deve conter cada exercício do treino uma única vez")
+	ErrActiveSessionExists      = errors.New("já existe um treino em andamento")
+	ErrInvalidSessionTransition = errors.New("transição de sessão inválida")
+	ErrInvalidImportFile        = errors.New("arquivo de importação inválido")
+	ErrTrackAlreadyImported     = errors.New("esse arquivo já foi importado")
+	ErrCannotFollowSelf         = errors.New("você não pode seguir a si mesmo")
+	ErrWeightsAlreadyConverted  = errors.New("os pesos desse usuário já foram convertidos")
 )
 
 func isPgDuplicateUserError(err error) bool {
@@ -22,6 +33,29 @@ func isPgDuplicateUserError(err error) bool {
 	return errors.As(err, &pgErr)
 }
 
+func isPgUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func HandleExerciseDatabaseError(err error) error {
+	if isPgUniqueViolation(err) {
+		return ErrExerciseExists
+	}
+
+	return err
+}
+
+// HandleSessionDatabaseError reports the unique index that keeps a single live session per user.
+func HandleSessionDatabaseError(err error) error {
+	if isPgUniqueViolation(err) {
+		return ErrActiveSessionExists
+	}
+
+	return err
+}
+
 func HandleDatabaseError(err error) error {
 	if isPgDuplicateUserError(err) {
 		return ErrUserAlreadyExists
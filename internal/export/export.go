@@ -0,0 +1,165 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+	FormatXLSX  = "xlsx"
+)
+
+var contentTypes = map[string]string{
+	FormatCSV:   "text/csv; charset=utf-8",
+	FormatJSONL: "application/x-ndjson",
+	FormatXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Writer streams rows of a fixed set of columns. Values may be nil, strings,
+// bools, numbers, times or pointers to them. Close must be called to flush the
+// trailing bytes of the format.
+type Writer interface {
+	WriteRow(values []any) error
+	Close() error
+}
+
+// NewWriter writes the header of the given format right away, so an empty
+// export still names its columns.
+func NewWriter(format string, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatJSONL:
+		return &jsonlWriter{w: w, columns: columns}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("unknown export format: %s", format)
+	}
+}
+
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// normalize dereferences pointers, so nil pointers become empty cells.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case *int:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *float64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	default:
+		return v
+	}
+}
+
+func formatValue(value any) string {
+	switch v := normalize(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	writer := &csvWriter{w: csv.NewWriter(w)}
+
+	return writer, writer.w.Write(columns)
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	record := make([]string, len(values))
+
+	for i, value := range values {
+		record[i] = formatValue(value)
+	}
+
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+
+	return c.w.Error()
+}
+
+// jsonlWriter writes one object per line, keeping the order of the columns.
+type jsonlWriter struct {
+	w       io.Writer
+	columns []string
+}
+
+func (j *jsonlWriter) WriteRow(values []any) error {
+	line := &bytes.Buffer{}
+	encoder := json.NewEncoder(line)
+	encoder.SetEscapeHTML(false)
+	line.WriteByte('{')
+
+	for i, value := range values {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+
+		if err := encoder.Encode(j.columns[i]); err != nil {
+			return err
+		}
+
+		// Encode terminates every value with a newline
+		line.Truncate(line.Len() - 1)
+		line.WriteByte(':')
+
+		if err := encoder.Encode(normalize(value)); err != nil {
+			return err
+		}
+
+		line.Truncate(line.Len() - 1)
+	}
+
+	line.WriteString("}\n")
+	_, err := j.w.Write(line.Bytes())
+
+	return err
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
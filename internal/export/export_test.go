@@ -0,0 +1,76 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testColumns = []string{"title", "date", "reps", "weight", "notes"}
+	testDate    = time.Date(2025, 10, 17, 18, 30, 0, 0, time.UTC)
+)
+
+func writeRows(t *testing.T, format string) []byte {
+	buffer := &bytes.Buffer{}
+	writer, err := NewWriter(format, buffer, testColumns)
+	assert.NoError(t, err)
+
+	reps := 8
+	assert.NoError(t, writer.WriteRow([]any{"Push, day", testDate, &reps, 82.5, nil}))
+	assert.NoError(t, writer.WriteRow([]any{"Legs", &testDate, (*int)(nil), 120.0, "<heavy> & slow"}))
+	assert.NoError(t, writer.Close())
+
+	return buffer.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	expected := "title,date,reps,weight,notes\n" +
+		"\"Push, day\",2025-10-17T18:30:00Z,8,82.5,\n" +
+		"Legs,2025-10-17T18:30:00Z,,120,<heavy> & slow\n"
+
+	assert.Equal(t, expected, string(writeRows(t, FormatCSV)))
+}
+
+func TestJSONLWriter(t *testing.T) {
+	expected := `{"title":"Push, day","date":"2025-10-17T18:30:00Z","reps":8,"weight":82.5,"notes":null}` + "\n" +
+		`{"title":"Legs","date":"2025-10-17T18:30:00Z","reps":null,"weight":120,"notes":"<heavy> & slow"}` + "\n"
+
+	assert.Equal(t, expected, string(writeRows(t, FormatJSONL)))
+}
+
+func TestXLSXWriter(t *testing.T) {
+	content := writeRows(t, FormatXLSX)
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	assert.NoError(t, err)
+
+	parts := make(map[string]string)
+
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		assert.NoError(t, err)
+
+		data, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+
+		parts[file.Name] = string(data)
+	}
+
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts, "xl/workbook.xml")
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row><c t="inlineStr"><is><t xml:space="preserve">title</t></is></c>`)
+	assert.Contains(t, sheet, `<c><v>8</v></c><c><v>82.5</v></c><c/></row>`)
+	assert.Contains(t, sheet, `&lt;heavy&gt; &amp; slow`)
+	assert.Contains(t, sheet, `</sheetData></worksheet>`)
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := NewWriter("pdf", &bytes.Buffer{}, testColumns)
+	assert.Error(t, err)
+}
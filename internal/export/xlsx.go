@@ -0,0 +1,136 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// The smallest package spreadsheet apps accept: a workbook with a single sheet
+// of inline strings, so no shared strings table has to be kept in memory.
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxWriter streams the sheet as the last part of the zip, row by row.
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet io.Writer
+}
+
+func newXLSXWriter(w io.Writer, columns []string) (*xlsxWriter, error) {
+	writer := &xlsxWriter{zip: zip.NewWriter(w)}
+
+	for _, part := range xlsxParts {
+		partWriter, err := writer.zip.Create(part.name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := io.WriteString(partWriter, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := writer.zip.Create("xl/worksheets/sheet1.xml")
+
+	if err != nil {
+		return nil, err
+	}
+
+	writer.sheet = sheet
+	_, err = io.WriteString(sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]any, len(columns))
+
+	for i, column := range columns {
+		header[i] = column
+	}
+
+	return writer, writer.WriteRow(header)
+}
+
+func (x *xlsxWriter) WriteRow(values []any) error {
+	if _, err := io.WriteString(x.sheet, "<row>"); err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		if err := x.writeCell(value); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(x.sheet, "</row>")
+
+	return err
+}
+
+// writeCell keeps numbers numeric so they can be summed in the spreadsheet,
+// everything else is written as text.
+func (x *xlsxWriter) writeCell(value any) error {
+	var cell string
+
+	switch v := normalize(value).(type) {
+	case nil:
+		cell = "<c/>"
+	case int:
+		cell = "<c><v>" + strconv.Itoa(v) + "</v></c>"
+	case float64:
+		cell = "<c><v>" + strconv.FormatFloat(v, 'f', -1, 64) + "</v></c>"
+	case bool:
+		cell = `<c t="b"><v>0</v></c>`
+
+		if v {
+			cell = `<c t="b"><v>1</v></c>`
+		}
+	case time.Time:
+		cell = `<c t="inlineStr"><is><t>` + v.Format(time.RFC3339) + `</t></is></c>`
+	default:
+		if _, err := io.WriteString(x.sheet, `<c t="inlineStr"><is><t xml:space="preserve">`); err != nil {
+			return err
+		}
+
+		if err := xml.EscapeText(x.sheet, []byte(formatValue(v))); err != nil {
+			return err
+		}
+
+		cell = "</t></is></c>"
+	}
+
+	_, err := io.WriteString(x.sheet, cell)
+
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, "</sheetData></worksheet>"); err != nil {
+		return err
+	}
+
+	return x.zip.Close()
+}
@@ -0,0 +1,60 @@
+package fitness
+
+import "math"
+
+// CaloriesFormulaMET is recorded with every estimated value, so estimates can
+// be told apart (and recomputed) if the formula changes.
+const CaloriesFormulaMET = "met-v1"
+
+// DefaultBodyweight is used when the user never logged their weight.
+const DefaultBodyweight = 70.0
+
+// Activity is a part of a workout with its MET value. Activities without a
+// duration share the workout time that the timed ones leave.
+type Activity struct {
+	MET             float64
+	DurationSeconds *int
+}
+
+// EstimateCalories applies kcal = MET × kg × hours to every activity. A workout
+// without activities is estimated from its duration with defaultMET.
+func EstimateCalories(activities []Activity, workoutMinutes int, defaultMET float64, bodyweight float64) int {
+	if bodyweight <= 0 {
+		bodyweight = DefaultBodyweight
+	}
+
+	if len(activities) == 0 {
+		return int(math.Round(defaultMET * bodyweight * float64(workoutMinutes) / 60))
+	}
+
+	timedMinutes := 0.0
+	untimed := 0
+
+	for _, activity := range activities {
+		if activity.DurationSeconds != nil && *activity.DurationSeconds > 0 {
+			timedMinutes += float64(*activity.DurationSeconds) / 60
+		} else {
+			untimed++
+		}
+	}
+
+	sharedMinutes := 0.0
+
+	if untimed > 0 {
+		sharedMinutes = math.Max(float64(workoutMinutes)-timedMinutes, 0) / float64(untimed)
+	}
+
+	calories := 0.0
+
+	for _, activity := range activities {
+		minutes := sharedMinutes
+
+		if activity.DurationSeconds != nil && *activity.DurationSeconds > 0 {
+			minutes = float64(*activity.DurationSeconds) / 60
+		}
+
+		calories += activity.MET * bodyweight * minutes / 60
+	}
+
+	return int(math.Round(calories))
+}
@@ -0,0 +1,40 @@
+package fitness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateCalories(t *testing.T) {
+	seconds := func(value int) *int { return &value }
+
+	t.Run("Workout without entries uses the default MET", func(t *testing.T) {
+		assert.Equal(t, 280, EstimateCalories(nil, 60, 4, 70))
+	})
+
+	t.Run("Timed entries use their own duration", func(t *testing.T) {
+		activities := []Activity{{MET: 9.8, DurationSeconds: seconds(1800)}}
+
+		assert.Equal(t, 392, EstimateCalories(activities, 90, 4, 80))
+	})
+
+	t.Run("Untimed entries share the remaining workout time", func(t *testing.T) {
+		activities := []Activity{
+			{MET: 7, DurationSeconds: seconds(600)},
+			{MET: 5},
+			{MET: 3.5},
+		}
+
+		// 10 min of cardio, then 20 min for each lift
+		assert.Equal(t, 340, EstimateCalories(activities, 50, 4, 85))
+	})
+
+	t.Run("Missing bodyweight falls back to the default", func(t *testing.T) {
+		assert.Equal(t, EstimateCalories(nil, 30, 4, DefaultBodyweight), EstimateCalories(nil, 30, 4, 0))
+	})
+
+	t.Run("No duration estimates nothing", func(t *testing.T) {
+		assert.Zero(t, EstimateCalories([]Activity{{MET: 5}}, 0, 4, 70))
+	})
+}
@@ -0,0 +1,30 @@
+package fitness
+
+const (
+	FormulaEpley   = "epley"
+	FormulaBrzycki = "brzycki"
+)
+
+// EstimateOneRepMax estimates the heaviest single repetition from a set of
+// reps at weight. A single rep is its own 1RM.
+func EstimateOneRepMax(weight float64, reps int, formula string) float64 {
+	if reps <= 0 || weight <= 0 {
+		return 0
+	}
+
+	if reps == 1 {
+		return weight
+	}
+
+	switch formula {
+	case FormulaBrzycki:
+		// Brzycki diverges as reps approach 37, cap it where it is still meaningful
+		if reps >= 37 {
+			reps = 36
+		}
+
+		return weight * 36 / float64(37-reps)
+	default:
+		return weight * (1 + float64(reps)/30)
+	}
+}
@@ -0,0 +1,28 @@
+package fitness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateOneRepMax(t *testing.T) {
+	t.Run("Epley", func(t *testing.T) {
+		assert.InDelta(t, 133.33, EstimateOneRepMax(100, 10, FormulaEpley), 0.01)
+	})
+
+	t.Run("Brzycki", func(t *testing.T) {
+		assert.InDelta(t, 133.33, EstimateOneRepMax(100, 10, FormulaBrzycki), 0.01)
+		assert.InDelta(t, 112.5, EstimateOneRepMax(100, 5, FormulaBrzycki), 0.01)
+	})
+
+	t.Run("Single rep is the 1RM", func(t *testing.T) {
+		assert.Equal(t, 140.0, EstimateOneRepMax(140, 1, FormulaEpley))
+		assert.Equal(t, 140.0, EstimateOneRepMax(140, 1, FormulaBrzycki))
+	})
+
+	t.Run("No reps or weight", func(t *testing.T) {
+		assert.Zero(t, EstimateOneRepMax(100, 0, FormulaEpley))
+		assert.Zero(t, EstimateOneRepMax(0, 10, FormulaEpley))
+	})
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type BodyMeasurementsHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewBodyMeasurementsHandlers(store *store.Store, logger *zap.SugaredLogger) *BodyMeasurementsHandlers {
+	return &BodyMeasurementsHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+func (mh *BodyMeasurementsHandlers) GetMeasurements(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+
+	from, to, err := utils.ReadTimeRangeQueryParams(r)
+	utils.MustIfError(err)
+
+	measurements := utils.Must(mh.Store.BodyMeasurementStore.GetAllMeasurements(user.ID, from, to))
+	unitSystem := negotiateUnitSystem(w, r, user)
+
+	for i := range measurements {
+		weightsFromKilograms(unitSystem, measurementWeights(&measurements[i]))
+	}
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"measurements": measurements})
+}
+
+// GetLatestMeasurements returns the most recent value of each metric.
+func (mh *BodyMeasurementsHandlers) GetLatestMeasurements(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	latest := utils.Must(mh.Store.BodyMeasurementStore.GetLatestMeasurements(user.ID))
+
+	if latest["weight"] != nil {
+		weightsFromKilograms(negotiateUnitSystem(w, r, user), []*float64{&latest["weight"].Value})
+	}
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"latest": latest})
+}
+
+// GetMeasurementSeries returns the time series of the metric given in "metric".
+func (mh *BodyMeasurementsHandlers) GetMeasurementSeries(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	seriesRequest := &requests.MeasurementSeriesRequest{Metric: r.URL.Query().Get("metric")}
+	utils.MustValidateStruct(seriesRequest)
+
+	from, to, err := utils.ReadTimeRangeQueryParams(r)
+	utils.MustIfError(err)
+
+	series := utils.Must(mh.Store.BodyMeasurementStore.GetMeasurementSeries(user.ID, seriesRequest.Metric, from, to))
+
+	if seriesRequest.Metric == "weight" {
+		weightsFromKilograms(negotiateUnitSystem(w, r, user), measurementPointWeights(series))
+	}
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"metric": seriesRequest.Metric, "series": series})
+}
+
+func (mh *BodyMeasurementsHandlers) GetMeasurementByID(w http.ResponseWriter, r *http.Request) {
+	measurementID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfMeasurement(mh, user, measurementID))
+	measurement := utils.Must(mh.Store.BodyMeasurementStore.GetMeasurementById(measurementID))
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), measurementWeights(measurement))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"measurement": measurement})
+}
+
+func (mh *BodyMeasurementsHandlers) CreateMeasurement(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	unitSystem := negotiateUnitSystem(w, r, user)
+	measurementRequest := &requests.BodyMeasurementRequest{}
+
+	utils.MustReadJSON(w, r, measurementRequest)
+	// the weight limit is in kilograms, whatever unit the weight was sent in
+	weightsToKilograms(unitSystem, measurementRequestWeights(measurementRequest))
+	utils.MustValidateStruct(measurementRequest)
+
+	measurement := (&store.BodyMeasurement{UserID: user.ID}).FromBodyMeasurementRequest(measurementRequest)
+	createdMeasurement, err := mh.Store.BodyMeasurementStore.CreateMeasurement(measurement)
+
+	if err != nil {
+		mh.Logger.Errorf("failed to create measurement: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to create measurement"})
+		return
+	}
+
+	weightsFromKilograms(unitSystem, measurementWeights(createdMeasurement))
+
+	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"measurement": createdMeasurement})
+}
+
+func (mh *BodyMeasurementsHandlers) UpdateMeasurement(w http.ResponseWriter, r *http.Request) {
+	measurementID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfMeasurement(mh, user, measurementID))
+
+	unitSystem := negotiateUnitSystem(w, r, user)
+	measurementRequest := &requests.BodyMeasurementRequest{}
+	utils.MustReadJSON(w, r, measurementRequest)
+	weightsToKilograms(unitSystem, measurementRequestWeights(measurementRequest))
+	utils.MustValidateStruct(measurementRequest)
+
+	measurement := (&store.BodyMeasurement{UserID: user.ID}).FromBodyMeasurementRequest(measurementRequest)
+	updatedMeasurement, err := mh.Store.BodyMeasurementStore.UpdateMeasurement(measurementID, measurement)
+
+	if err != nil {
+		mh.Logger.Errorf("failed to update measurement: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to update measurement"})
+		return
+	}
+
+	weightsFromKilograms(unitSystem, measurementWeights(updatedMeasurement))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"measurement": updatedMeasurement})
+}
+
+func (mh *BodyMeasurementsHandlers) DeleteMeasurement(w http.ResponseWriter, r *http.Request) {
+	measurementID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfMeasurement(mh, user, measurementID))
+	utils.MustIfError(mh.Store.BodyMeasurementStore.DeleteMeasurement(measurementID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func checkOwnerOfMeasurement(mh *BodyMeasurementsHandlers, user *store.User, measurementID int) error {
+	isMeasurementOwner := utils.Must(mh.Store.BodyMeasurementStore.OwnsMeasurement(measurementID, user.ID))
+
+	if !isMeasurementOwner {
+		mh.Logger.Error("user does not own this measurement")
+		return internalErrors.ErrForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type ExercisesHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewExercisesHandlers(store *store.Store, logger *zap.SugaredLogger) *ExercisesHandlers {
+	return &ExercisesHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+func (eh *ExercisesHandlers) SearchExercises(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	query := r.URL.Query()
+
+	searchRequest := &requests.SearchExercisesRequest{
+		Search:       query.Get("q"),
+		MuscleGroup:  query.Get("muscle_group"),
+		Equipment:    query.Get("equipment"),
+		MovementType: query.Get("movement_type"),
+	}
+	utils.MustValidateStruct(searchRequest)
+
+	exercises := utils.Must(eh.Store.ExerciseStore.SearchExercises(user.ID, store.ExerciseFilters{
+		Search:       searchRequest.Search,
+		MuscleGroup:  searchRequest.MuscleGroup,
+		Equipment:    searchRequest.Equipment,
+		MovementType: searchRequest.MovementType,
+		CustomOnly:   query.Get("custom") == "true",
+	}))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"exercises": exercises})
+}
+
+func (eh *ExercisesHandlers) GetExerciseByID(w http.ResponseWriter, r *http.Request) {
+	exerciseID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	exercise := utils.Must(visibleExercise(eh.Store, user, exerciseID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"exercise": exercise})
+}
+
+func (eh *ExercisesHandlers) CreateExercise(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	exerciseRequest := &requests.ExerciseRequest{}
+
+	utils.MustReadJSON(w, r, exerciseRequest)
+	utils.MustValidateStruct(exerciseRequest)
+
+	exercise := (&store.Exercise{UserID: &user.ID}).FromExerciseRequest(exerciseRequest)
+
+	eh.Logger.Info("creating exercise", zap.String("name", exercise.Name))
+	createdExercise, err := eh.Store.ExerciseStore.CreateExercise(exercise)
+
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrExerciseExists) {
+			utils.MustWriteJSON(w, http.StatusBadRequest, utils.Envelope{"error": err.Error()})
+			return
+		}
+
+		eh.Logger.Errorf("failed to create exercise: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to create exercise"})
+		return
+	}
+
+	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"exercise": createdExercise})
+}
+
+func (eh *ExercisesHandlers) UpdateExercise(w http.ResponseWriter, r *http.Request) {
+	exerciseID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfExercise(eh, user, exerciseID))
+
+	exerciseRequest := &requests.ExerciseRequest{}
+	utils.MustReadJSON(w, r, exerciseRequest)
+	utils.MustValidateStruct(exerciseRequest)
+
+	exercise := (&store.Exercise{UserID: &user.ID}).FromExerciseRequest(exerciseRequest)
+	updatedExercise, err := eh.Store.ExerciseStore.UpdateExercise(exerciseID, exercise)
+
+	if err != nil {
+		if errors.Is(err, internalErrors.ErrExerciseExists) {
+			utils.MustWriteJSON(w, http.StatusBadRequest, utils.Envelope{"error": err.Error()})
+			return
+		}
+
+		eh.Logger.Errorf("failed to update exercise: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to update exercise"})
+		return
+	}
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"exercise": updatedExercise})
+}
+
+func (eh *ExercisesHandlers) DeleteExercise(w http.ResponseWriter, r *http.Request) {
+	exerciseID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfExercise(eh, user, exerciseID))
+	utils.MustIfError(eh.Store.ExerciseStore.DeleteExercise(exerciseID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkOwnerOfExercise only lets users change their custom exercises, never the global catalog.
+func checkOwnerOfExercise(eh *ExercisesHandlers, user *store.User, exerciseID int) error {
+	isExerciseOwner := utils.Must(eh.Store.ExerciseStore.OwnsExercise(exerciseID, user.ID))
+
+	if !isExerciseOwner {
+		eh.Logger.Error("user does not own this exercise")
+		return internalErrors.ErrForbidden
+	}
+
+	return nil
+}
+
+// visibleExercise loads an exercise only when it belongs to the global catalog or to the user.
+func visibleExercise(appStore *store.Store, user *store.User, exerciseID int) (*store.Exercise, error) {
+	canUse, err := appStore.ExerciseStore.CanUseExercise(exerciseID, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !canUse {
+		return nil, internalErrors.ErrForbidden
+	}
+
+	return appStore.ExerciseStore.GetExerciseById(exerciseID)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type FollowsHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewFollowsHandlers(store *store.Store, logger *zap.SugaredLogger) *FollowsHandlers {
+	return &FollowsHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+// Follow asks to follow a user. The follow stays pending until that user
+// approves it.
+func (fh *FollowsHandlers) Follow(w http.ResponseWriter, r *http.Request) {
+	followeeID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	fh.Logger.Info("following user", zap.Int("follower_id", user.ID), zap.Int("followee_id", followeeID))
+	status := utils.Must(fh.Store.FollowStore.Follow(user.ID, followeeID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"status": status})
+}
+
+func (fh *FollowsHandlers) Unfollow(w http.ResponseWriter, r *http.Request) {
+	followeeID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(fh.Store.FollowStore.Unfollow(user.ID, followeeID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (fh *FollowsHandlers) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	userID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkCanSeeFollows(fh, user, userID))
+	followers := utils.Must(fh.Store.FollowStore.GetFollowers(userID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"followers": followers})
+}
+
+func (fh *FollowsHandlers) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	userID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkCanSeeFollows(fh, user, userID))
+	following := utils.Must(fh.Store.FollowStore.GetFollowing(userID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"following": following})
+}
+
+// GetFollowRequests lists who is waiting for the user to approve their follow.
+func (fh *FollowsHandlers) GetFollowRequests(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	followRequests := utils.Must(fh.Store.FollowStore.GetFollowRequests(user.ID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"requests": followRequests})
+}
+
+func (fh *FollowsHandlers) ApproveFollowRequest(w http.ResponseWriter, r *http.Request) {
+	followerID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	fh.Logger.Info("approving follow request", zap.Int("follower_id", followerID), zap.Int("followee_id", user.ID))
+	utils.MustIfError(fh.Store.FollowStore.ApproveFollower(user.ID, followerID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (fh *FollowsHandlers) RejectFollowRequest(w http.ResponseWriter, r *http.Request) {
+	followerID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(fh.Store.FollowStore.RejectFollower(user.ID, followerID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkCanSeeFollows keeps who a user follows and is followed by to the user
+// and their approved followers.
+func checkCanSeeFollows(fh *FollowsHandlers, user *store.User, userID int) error {
+	if user.ID == userID {
+		return nil
+	}
+
+	if !utils.Must(fh.Store.FollowStore.IsFollowing(user.ID, userID)) {
+		fh.Logger.Error("user does not follow this user")
+		return internalErrors.ErrForbidden
+	}
+
+	return nil
+}
+
+// GetFeed lists the workouts of the users the user follows, newest first,
+// paginated like GET /workouts.
+func (fh *FollowsHandlers) GetFeed(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	filters := readFeedFilters(r)
+
+	items, pagination, err := fh.Store.FeedStore.GetFeed(user.ID, filters)
+	utils.MustIfError(err)
+
+	writeFeed(w, r, user, items, pagination)
+}
+
+// GetUserWorkouts lists the workouts of a user that the requesting user is
+// allowed to see.
+func (fh *FollowsHandlers) GetUserWorkouts(w http.ResponseWriter, r *http.Request) {
+	userID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+	filters := readFeedFilters(r)
+
+	items, pagination, err := fh.Store.FeedStore.GetUserWorkouts(user.ID, userID, filters)
+	utils.MustIfError(err)
+
+	writeFeed(w, r, user, items, pagination)
+}
+
+func readFeedFilters(r *http.Request) store.FeedFilters {
+	feedRequest := &requests.FeedRequest{
+		Limit:  utils.Must(utils.ReadIntQueryParam(r, "limit", store.DefaultPageLimit)),
+		Cursor: r.URL.Query().Get("cursor"),
+	}
+	utils.MustValidateStruct(feedRequest)
+
+	return store.FeedFilters{Limit: feedRequest.Limit, Cursor: feedRequest.Cursor}
+}
+
+func writeFeed(w http.ResponseWriter, r *http.Request, user *store.User, items []store.FeedItem, pagination *store.Pagination) {
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), feedWeights(items))
+	utils.SetNextLinkHeader(w, r, pagination.NextCursor)
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"workouts": items, "pagination": pagination})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type GoalsHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewGoalsHandlers(store *store.Store, logger *zap.SugaredLogger) *GoalsHandlers {
+	return &GoalsHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+// GetGoals lists the goals of the user with their progress evaluated right now.
+func (gh *GoalsHandlers) GetGoals(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	goals := utils.Must(gh.Store.GoalStore.GetAllGoals(user.ID))
+	unitSystem := negotiateUnitSystem(w, r, user)
+
+	for i := range goals {
+		weightsFromKilograms(unitSystem, goalWeights(&goals[i]))
+	}
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"goals": goals})
+}
+
+func (gh *GoalsHandlers) GetGoalByID(w http.ResponseWriter, r *http.Request) {
+	goalID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfGoal(gh, user, goalID))
+	goal := utils.Must(gh.Store.GoalStore.GetGoalById(goalID))
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), goalWeights(goal))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"goal": goal})
+}
+
+func (gh *GoalsHandlers) CreateGoal(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	unitSystem := negotiateUnitSystem(w, r, user)
+	goalRequest := &requests.GoalRequest{}
+
+	utils.MustReadJSON(w, r, goalRequest)
+	utils.MustValidateStruct(goalRequest)
+	utils.MustIfError(checkGoalExercise(gh, user, goalRequest))
+
+	goal := (&store.Goal{UserID: user.ID}).FromGoalRequest(goalRequest)
+	weightsToKilograms(unitSystem, goalWeights(goal))
+
+	gh.Logger.Info("creating goal", zap.String("title", goal.Title))
+	createdGoal, err := gh.Store.GoalStore.CreateGoal(goal)
+
+	if err != nil {
+		gh.Logger.Errorf("failed to create goal: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to create goal"})
+		return
+	}
+
+	weightsFromKilograms(unitSystem, goalWeights(createdGoal))
+
+	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"goal": createdGoal})
+}
+
+func (gh *GoalsHandlers) UpdateGoal(w http.ResponseWriter, r *http.Request) {
+	goalID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfGoal(gh, user, goalID))
+
+	unitSystem := negotiateUnitSystem(w, r, user)
+	goalRequest := &requests.GoalRequest{}
+	utils.MustReadJSON(w, r, goalRequest)
+	utils.MustValidateStruct(goalRequest)
+	utils.MustIfError(checkGoalExercise(gh, user, goalRequest))
+
+	goal := (&store.Goal{UserID: user.ID}).FromGoalRequest(goalRequest)
+	weightsToKilograms(unitSystem, goalWeights(goal))
+	updatedGoal, err := gh.Store.GoalStore.UpdateGoal(goalID, goal)
+
+	if err != nil {
+		gh.Logger.Errorf("failed to update goal: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to update goal"})
+		return
+	}
+
+	weightsFromKilograms(unitSystem, goalWeights(updatedGoal))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"goal": updatedGoal})
+}
+
+func (gh *GoalsHandlers) DeleteGoal(w http.ResponseWriter, r *http.Request) {
+	goalID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfGoal(gh, user, goalID))
+	utils.MustIfError(gh.Store.GoalStore.DeleteGoal(goalID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkGoalExercise makes sure lift goals point to an exercise the user can log.
+func checkGoalExercise(gh *GoalsHandlers, user *store.User, goalRequest *requests.GoalRequest) error {
+	if goalRequest.ExerciseID == nil {
+		return nil
+	}
+
+	_, err := visibleExercise(gh.Store, user, *goalRequest.ExerciseID)
+
+	return err
+}
+
+func checkOwnerOfGoal(gh *GoalsHandlers, user *store.User, goalID int) error {
+	isGoalOwner := utils.Must(gh.Store.GoalStore.OwnsGoal(goalID, user.ID))
+
+	if !isGoalOwner {
+		gh.Logger.Error("user does not own this goal")
+		return internalErrors.ErrForbidden
+	}
+
+	return nil
+}
@@ -7,17 +7,47 @@ import (
 )
 
 type Handlers struct {
-	WorkoutHandlers *WorkoutsHandlers
-	UserHandlers    *UserHandlers
-	TokensHandlers  *TokensHandlers
-	Logger          *zap.SugaredLogger
+	WorkoutHandlers         *WorkoutsHandlers
+	UserHandlers            *UserHandlers
+	TokensHandlers          *TokensHandlers
+	ExerciseHandlers        *ExercisesHandlers
+	TemplateHandlers        *TemplatesHandlers
+	ScheduleHandlers        *SchedulesHandlers
+	PersonalRecordHandlers  *PersonalRecordsHandlers
+	StatsHandlers           *StatsHandlers
+	GoalHandlers            *GoalsHandlers
+	BodyMeasurementHandlers *BodyMeasurementsHandlers
+	WorkoutSessionHandlers  *WorkoutSessionsHandlers
+	ImportHandlers          *ImportsHandlers
+	TrackHandlers           *TracksHandlers
+	WorkoutRevisionHandlers *WorkoutRevisionsHandlers
+	TagHandlers             *TagsHandlers
+	SearchHandlers          *SearchHandlers
+	WorkoutShareHandlers    *WorkoutSharesHandlers
+	FollowHandlers          *FollowsHandlers
+	Logger                  *zap.SugaredLogger
 }
 
 func NewHandlers(store *store.Store, logger *zap.SugaredLogger) *Handlers {
 	return &Handlers{
-		WorkoutHandlers: NewWorkoutsHandlers(store, logger),
-		UserHandlers:    NewUserHandlers(store, logger),
-		TokensHandlers:  NewTokensHandlers(store, logger),
-		Logger:          logger,
+		WorkoutHandlers:         NewWorkoutsHandlers(store, logger),
+		UserHandlers:            NewUserHandlers(store, logger),
+		TokensHandlers:          NewTokensHandlers(store, logger),
+		ExerciseHandlers:        NewExercisesHandlers(store, logger),
+		TemplateHandlers:        NewTemplatesHandlers(store, logger),
+		ScheduleHandlers:        NewSchedulesHandlers(store, logger),
+		PersonalRecordHandlers:  NewPersonalRecordsHandlers(store, logger),
+		StatsHandlers:           NewStatsHandlers(store, logger),
+		GoalHandlers:            NewGoalsHandlers(store, logger),
+		BodyMeasurementHandlers: NewBodyMeasurementsHandlers(store, logger),
+		WorkoutSessionHandlers:  NewWorkoutSessionsHandlers(store, logger),
+		ImportHandlers:          NewImportsHandlers(store, logger),
+		TrackHandlers:           NewTracksHandlers(store, logger),
+		WorkoutRevisionHandlers: NewWorkoutRevisionsHandlers(store, logger),
+		TagHandlers:             NewTagsHandlers(store, logger),
+		SearchHandlers:          NewSearchHandlers(store, logger),
+		WorkoutShareHandlers:    NewWorkoutSharesHandlers(store, logger),
+		FollowHandlers:          NewFollowsHandlers(store, logger),
+		Logger:                  logger,
 	}
 }
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/importer"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// MaxImportSize caps the uploaded export, years of logs fit well below it.
+const MaxImportSize = 10 << 20
+
+type ImportsHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+// ImportSummary reports what an import did, or would do on a dry run.
+type ImportSummary struct {
+	Source             string              `json:"source"`
+	DryRun             bool                `json:"dry_run"`
+	Rows               int                 `json:"rows"`
+	Workouts           int                 `json:"workouts"`
+	Imported           int                 `json:"imported"`
+	Duplicates         int                 `json:"duplicates"`
+	UnmatchedExercises []string            `json:"unmatched_exercises"`
+	Errors             []importer.RowError `json:"errors"`
+	Preview            []ImportPreview     `json:"preview,omitempty"`
+}
+
+type ImportPreview struct {
+	Title           string    `json:"title"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	Exercises       []string  `json:"exercises"`
+	Sets            int       `json:"sets"`
+}
+
+func NewImportsHandlers(store *store.Store, logger *zap.SugaredLogger) *ImportsHandlers {
+	return &ImportsHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+// ImportWorkouts reads a Strong or Hevy CSV export sent as the "file" field of
+// a multipart form. Nothing is written when a row is invalid; with dry_run=true
+// the summary comes with a preview and nothing is written either.
+func (ih *ImportsHandlers) ImportWorkouts(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	dryRun := utils.Must(utils.ReadBoolQueryParam(r, "dry_run"))
+	unitSystem := negotiateUnitSystem(w, r, user)
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImportSize)
+	file, _, err := r.FormFile("file")
+
+	if err != nil {
+		panic(fmt.Errorf("%w: %v", internalErrors.ErrInvalidImportFile, err))
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	parsed := utils.Must(importer.Parse(file, user.Location(), unitSystem))
+	unmatched := utils.Must(matchImportedExercises(ih.Store, user, parsed.Workouts))
+
+	summary := &ImportSummary{
+		Source:             parsed.Source,
+		DryRun:             dryRun,
+		Rows:               parsed.Rows,
+		Workouts:           len(parsed.Workouts),
+		UnmatchedExercises: unmatched,
+		Errors:             parsed.Errors,
+	}
+
+	if len(parsed.Errors) > 0 && !dryRun {
+		utils.MustWriteJSON(w, http.StatusUnprocessableEntity, utils.Envelope{"import": summary})
+		return
+	}
+
+	if dryRun {
+		summary.Preview = importPreview(parsed.Workouts)
+	}
+
+	ih.Logger.Info("importing workouts", zap.String("source", parsed.Source), zap.Int("workouts", len(parsed.Workouts)), zap.Bool("dry_run", dryRun))
+	result := utils.Must(ih.Store.WorkoutStore.ImportWorkouts(user.ID, parsed.Workouts, dryRun))
+	summary.Imported = result.Imported
+	summary.Duplicates = result.Duplicates
+
+	status := http.StatusCreated
+
+	if dryRun {
+		status = http.StatusOK
+	}
+
+	utils.MustWriteJSON(w, status, utils.Envelope{"import": summary})
+}
+
+// matchImportedExercises links the entries to the catalog through the names
+// the other app uses and returns the names that matched nothing. Those entries
+// are still imported, as free-text exercises.
+func matchImportedExercises(appStore *store.Store, user *store.User, workouts []store.Workout) ([]string, error) {
+	matches := make(map[string]*store.Exercise)
+	unmatched := make([]string, 0)
+
+	for i := range workouts {
+		for j := range workouts[i].Entries {
+			entry := &workouts[i].Entries[j]
+			entry.UserID = user.ID
+			exercise, seen := matches[entry.ExerciseName]
+
+			if !seen {
+				var err error
+				exercise, err = findImportedExercise(appStore, user, entry.ExerciseName)
+
+				if err != nil {
+					return nil, err
+				}
+
+				matches[entry.ExerciseName] = exercise
+
+				if exercise == nil {
+					unmatched = append(unmatched, entry.ExerciseName)
+				}
+			}
+
+			if exercise != nil {
+				entry.ExerciseID = &exercise.ID
+				entry.ExerciseName = exercise.Name
+			}
+		}
+	}
+
+	slices.Sort(unmatched)
+
+	return unmatched, nil
+}
+
+func findImportedExercise(appStore *store.Store, user *store.User, name string) (*store.Exercise, error) {
+	for _, candidate := range importer.ExerciseNameCandidates(name) {
+		exercise, err := appStore.ExerciseStore.FindExerciseByName(user.ID, candidate)
+
+		if errors.Is(err, internalErrors.ErrNoRows) {
+			continue
+		}
+
+		return exercise, err
+	}
+
+	return nil, nil
+}
+
+func importPreview(workouts []store.Workout) []ImportPreview {
+	preview := make([]ImportPreview, 0, len(workouts))
+
+	for _, workout := range workouts {
+		item := ImportPreview{
+			Title:           workout.Title,
+			StartedAt:       *workout.StartedAt,
+			DurationMinutes: workout.DurationMinutes,
+			Exercises:       make([]string, 0, len(workout.Entries)),
+		}
+
+		for _, entry := range workout.Entries {
+			item.Exercises = append(item.Exercises, entry.ExerciseName)
+			item.Sets += len(entry.WorkoutSets)
+		}
+
+		preview = append(preview, item)
+	}
+
+	return preview
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type PersonalRecordsHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewPersonalRecordsHandlers(store *store.Store, logger *zap.SugaredLogger) *PersonalRecordsHandlers {
+	return &PersonalRecordsHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+// GetPersonalRecords lists the current records of the user, optionally for a single exercise.
+func (ph *PersonalRecordsHandlers) GetPersonalRecords(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	var exerciseID *int
+
+	if r.URL.Query().Has("exercise_id") {
+		exerciseID = utils.ValueToPointer(utils.Must(utils.ReadIntQueryParam(r, "exercise_id", 0)))
+	}
+
+	records := utils.Must(ph.Store.PersonalRecordStore.GetPersonalRecords(user.ID, exerciseID))
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), personalRecordWeights(records))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"personal_records": records})
+}
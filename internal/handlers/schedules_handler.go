@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// maxOccurrencesRange bounds how many days a single calendar request can expand.
+const maxOccurrencesRange = 366
+
+type SchedulesHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewSchedulesHandlers(store *store.Store, logger *zap.SugaredLogger) *SchedulesHandlers {
+	return &SchedulesHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+func (sh *SchedulesHandlers) GetSchedules(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	schedules := utils.Must(sh.Store.ScheduleStore.GetAllSchedules(user.ID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"schedules": schedules})
+}
+
+func (sh *SchedulesHandlers) GetScheduleByID(w http.ResponseWriter, r *http.Request) {
+	scheduleID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfSchedule(sh, user, scheduleID))
+	schedule := utils.Must(sh.Store.ScheduleStore.GetScheduleById(scheduleID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"schedule": schedule})
+}
+
+func (sh *SchedulesHandlers) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	scheduleRequest := &requests.ScheduledWorkoutRequest{}
+
+	utils.MustReadJSON(w, r, scheduleRequest)
+	utils.MustValidateStruct(scheduleRequest)
+	utils.MustIfError(checkScheduleTemplate(sh, user, scheduleRequest))
+
+	schedule := (&store.ScheduledWorkout{UserID: user.ID}).FromScheduleRequest(scheduleRequest)
+
+	sh.Logger.Info("creating schedule", zap.String("title", schedule.Title))
+	createdSchedule, err := sh.Store.ScheduleStore.CreateSchedule(schedule)
+
+	if err != nil {
+		sh.Logger.Errorf("failed to create schedule: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to create schedule"})
+		return
+	}
+
+	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"schedule": createdSchedule})
+}
+
+func (sh *SchedulesHandlers) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfSchedule(sh, user, scheduleID))
+
+	scheduleRequest := &requests.ScheduledWorkoutRequest{}
+	utils.MustReadJSON(w, r, scheduleRequest)
+	utils.MustValidateStruct(scheduleRequest)
+	utils.MustIfError(checkScheduleTemplate(sh, user, scheduleRequest))
+
+	schedule := (&store.ScheduledWorkout{UserID: user.ID}).FromScheduleRequest(scheduleRequest)
+	updatedSchedule, err := sh.Store.ScheduleStore.UpdateSchedule(scheduleID, schedule)
+
+	if err != nil {
+		sh.Logger.Errorf("failed to update schedule: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to update schedule"})
+		return
+	}
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"schedule": updatedSchedule})
+}
+
+func (sh *SchedulesHandlers) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfSchedule(sh, user, scheduleID))
+	utils.MustIfError(sh.Store.ScheduleStore.DeleteSchedule(scheduleID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetOccurrences expands the calendar of the user. Without a range it covers
+// the 30 days before and after today; "status" narrows the result, e.g. to missed sessions.
+func (sh *SchedulesHandlers) GetOccurrences(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	today := store.NewDate(time.Now().In(user.Location()))
+
+	from, to, err := utils.ReadTimeRangeQueryParams(r)
+	utils.MustIfError(err)
+
+	rangeStart := today.AddDate(0, 0, -30)
+	rangeEnd := today.AddDate(0, 0, 30)
+
+	if from != nil {
+		rangeStart = *from
+	}
+
+	if to != nil {
+		// the range helper returns an exclusive bound, calendar days are inclusive
+		rangeEnd = to.Add(-time.Nanosecond)
+	}
+
+	if rangeEnd.Sub(rangeStart) > maxOccurrencesRange*24*time.Hour {
+		panic(fmt.Errorf("%w: range cannot exceed %d days", internalErrors.ErrInvalidQueryParam, maxOccurrencesRange))
+	}
+
+	status := r.URL.Query().Get("status")
+
+	if status != "" && status != store.OccurrencePlanned && status != store.OccurrenceCompleted && status != store.OccurrenceMissed {
+		panic(fmt.Errorf("%w: status", internalErrors.ErrInvalidQueryParam))
+	}
+
+	occurrences := utils.Must(sh.Store.ScheduleStore.GetOccurrences(
+		user.ID,
+		store.NewDate(rangeStart),
+		store.NewDate(rangeEnd),
+		today,
+		user.Location(),
+	))
+
+	if status != "" {
+		filtered := make([]store.ScheduledOccurrence, 0, len(occurrences))
+
+		for _, occurrence := range occurrences {
+			if occurrence.Status == status {
+				filtered = append(filtered, occurrence)
+			}
+		}
+
+		occurrences = filtered
+	}
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"occurrences": occurrences})
+}
+
+func (sh *SchedulesHandlers) CompleteOccurrence(w http.ResponseWriter, r *http.Request) {
+	scheduleID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfSchedule(sh, user, scheduleID))
+
+	day, err := store.ParseDate(chi.URLParam(r, "date"))
+
+	if err != nil {
+		panic(fmt.Errorf("%w: date", internalErrors.ErrInvalidQueryParam))
+	}
+
+	schedule := utils.Must(sh.Store.ScheduleStore.GetScheduleById(scheduleID))
+
+	if !schedule.OccursOn(day) {
+		panic(internalErrors.ErrNoRows)
+	}
+
+	completeRequest := &requests.CompleteOccurrenceRequest{}
+
+	if r.ContentLength > 0 {
+		utils.MustReadJSON(w, r, completeRequest)
+	}
+
+	if completeRequest.WorkoutID != nil && !utils.Must(sh.Store.WorkoutStore.OwnsWorkout(*completeRequest.WorkoutID, user.ID)) {
+		sh.Logger.Error("user does not own this workout")
+		panic(internalErrors.ErrForbidden)
+	}
+
+	utils.MustIfError(sh.Store.ScheduleStore.CompleteOccurrence(scheduleID, day, completeRequest.WorkoutID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func checkScheduleTemplate(sh *SchedulesHandlers, user *store.User, scheduleRequest *requests.ScheduledWorkoutRequest) error {
+	if scheduleRequest.TemplateID == nil {
+		return nil
+	}
+
+	if !utils.Must(sh.Store.TemplateStore.OwnsTemplate(*scheduleRequest.TemplateID, user.ID)) {
+		sh.Logger.Error("user does not own this template")
+		return internalErrors.ErrForbidden
+	}
+
+	return nil
+}
+
+func checkOwnerOfSchedule(sh *SchedulesHandlers, user *store.User, scheduleID int) error {
+	isScheduleOwner := utils.Must(sh.Store.ScheduleStore.OwnsSchedule(scheduleID, user.ID))
+
+	if !isScheduleOwner {
+		sh.Logger.Error("user does not own this schedule")
+		return internalErrors.ErrForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type SearchHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewSearchHandlers(store *store.Store, logger *zap.SugaredLogger) *SearchHandlers {
+	return &SearchHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+// Search looks for the text in the titles, descriptions, exercise names and
+// notes of the user's workouts.
+func (sh *SearchHandlers) Search(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+
+	searchRequest := &requests.SearchRequest{
+		Query: strings.TrimSpace(r.URL.Query().Get("q")),
+		Limit: utils.Must(utils.ReadIntQueryParam(r, "limit", store.DefaultSearchLimit)),
+	}
+	utils.MustValidateStruct(searchRequest)
+
+	results := utils.Must(sh.Store.SearchStore.SearchWorkouts(user.ID, searchRequest.Query, searchRequest.Limit))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"results": results})
+}
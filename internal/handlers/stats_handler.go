@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/fitness"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// maxSummaryPeriods bounds how many weeks or months a single summary request can cover.
+const maxSummaryPeriods = 104
+
+type StatsHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewStatsHandlers(store *store.Store, logger *zap.SugaredLogger) *StatsHandlers {
+	return &StatsHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+// GetExerciseProgress returns the progress time series of an exercise, identified by catalog id or by name.
+func (sh *StatsHandlers) GetExerciseProgress(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	query := r.URL.Query()
+
+	progressRequest := &requests.ExerciseProgressRequest{
+		Bucket:  cmp.Or(query.Get("bucket"), store.BucketWeek),
+		Formula: cmp.Or(query.Get("formula"), fitness.FormulaEpley),
+	}
+	utils.MustValidateStruct(progressRequest)
+
+	from, to, err := utils.ReadTimeRangeQueryParams(r)
+	utils.MustIfError(err)
+
+	exercise, name, err := resolveProgressExercise(sh.Store, user, chi.URLParam(r, "exercise"))
+	utils.MustIfError(err)
+
+	progress := utils.Must(sh.Store.StatsStore.GetExerciseProgress(user.ID, store.ExerciseKey(exercise, name), store.ProgressFilters{
+		Bucket:   progressRequest.Bucket,
+		Formula:  progressRequest.Formula,
+		TimeZone: user.Location().String(),
+		From:     from,
+		To:       to,
+	}))
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), progressWeights(progress))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{
+		"exercise": utils.Envelope{"id": exerciseIDOrNil(exercise), "name": name},
+		"bucket":   progressRequest.Bucket,
+		"formula":  progressRequest.Formula,
+		"progress": progress,
+	})
+}
+
+// GetSummary aggregates the user's training per ISO week or calendar month in
+// the user's time zone. Without a range it covers the last 12 periods.
+func (sh *StatsHandlers) GetSummary(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	summaryRequest := &requests.SummaryRequest{
+		Period: cmp.Or(r.URL.Query().Get("period"), store.BucketWeek),
+	}
+	utils.MustValidateStruct(summaryRequest)
+
+	today := store.NewDate(time.Now().In(user.Location()))
+	to := utils.Must(readDateQueryParam(r, "to", today))
+	from := utils.Must(readDateQueryParam(r, "from", store.AddPeriods(store.PeriodStart(to, summaryRequest.Period), summaryRequest.Period, -11)))
+
+	if from.After(to.Time) || store.AddPeriods(from, summaryRequest.Period, maxSummaryPeriods).Before(to.Time) {
+		panic(fmt.Errorf("%w: from/to", internalErrors.ErrInvalidQueryParam))
+	}
+
+	summary := utils.Must(sh.Store.StatsStore.GetSummary(user.ID, store.SummaryFilters{
+		Period:   summaryRequest.Period,
+		TimeZone: user.Location().String(),
+		From:     from,
+		To:       to,
+	}))
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), summaryWeights(summary))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{
+		"period":    summaryRequest.Period,
+		"time_zone": user.Location().String(),
+		"summary":   summary,
+	})
+}
+
+// resolveProgressExercise accepts a catalog id or a free-form name. Names that
+// are not in the catalog still match entries logged with that exact name.
+func resolveProgressExercise(appStore *store.Store, user *store.User, param string) (*store.Exercise, string, error) {
+	param = strings.TrimSpace(param)
+
+	if id, err := strconv.Atoi(param); err == nil {
+		exercise, err := visibleExercise(appStore, user, id)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		return exercise, exercise.Name, nil
+	}
+
+	exercise, err := appStore.ExerciseStore.FindExerciseByName(user.ID, param)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, param, nil
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return exercise, exercise.Name, nil
+}
+
+func exerciseIDOrNil(exercise *store.Exercise) *int {
+	if exercise == nil {
+		return nil
+	}
+
+	return &exercise.ID
+}
+
+func readDateQueryParam(r *http.Request, key string, defaultValue store.Date) (store.Date, error) {
+	value := r.URL.Query().Get(key)
+
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	date, err := store.ParseDate(value)
+
+	if err != nil {
+		return store.Date{}, fmt.Errorf("%w: %s", internalErrors.ErrInvalidQueryParam, key)
+	}
+
+	return date, nil
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type TagsHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewTagsHandlers(store *store.Store, logger *zap.SugaredLogger) *TagsHandlers {
+	return &TagsHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+// GetTags lists the tags of the user with how many workouts use each one.
+func (th *TagsHandlers) GetTags(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	tags := utils.Must(th.Store.TagStore.GetTags(user.ID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"tags": tags})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type TemplatesHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewTemplatesHandlers(store *store.Store, logger *zap.SugaredLogger) *TemplatesHandlers {
+	return &TemplatesHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+func (th *TemplatesHandlers) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	templates := utils.Must(th.Store.TemplateStore.GetAllTemplates(user.ID))
+	unitSystem := negotiateUnitSystem(w, r, user)
+
+	for i := range templates {
+		weightsFromKilograms(unitSystem, templateWeights(&templates[i]))
+	}
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"templates": templates})
+}
+
+func (th *TemplatesHandlers) GetTemplateByID(w http.ResponseWriter, r *http.Request) {
+	templateID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfTemplate(th, user, templateID))
+	template := utils.Must(th.Store.TemplateStore.GetTemplateById(templateID))
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), templateWeights(template))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"template": template})
+}
+
+func (th *TemplatesHandlers) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	unitSystem := negotiateUnitSystem(w, r, user)
+	templateRequest := &requests.WorkoutTemplateRequest{}
+
+	utils.MustReadJSON(w, r, templateRequest)
+	utils.MustValidateStruct(templateRequest)
+
+	template := (&store.WorkoutTemplate{UserID: user.ID}).FromTemplateRequest(templateRequest)
+	weightsToKilograms(unitSystem, templateWeights(template))
+	utils.MustIfError(prepareTemplateEntries(th, user, template.Entries))
+
+	th.Logger.Info("creating template", zap.String("title", template.Title))
+	createdTemplate, err := th.Store.TemplateStore.CreateTemplate(template)
+
+	if err != nil {
+		th.Logger.Errorf("failed to create template: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to create template"})
+		return
+	}
+
+	weightsFromKilograms(unitSystem, templateWeights(createdTemplate))
+
+	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"template": createdTemplate})
+}
+
+func (th *TemplatesHandlers) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfTemplate(th, user, templateID))
+
+	unitSystem := negotiateUnitSystem(w, r, user)
+	templateRequest := &requests.WorkoutTemplateRequest{}
+	utils.MustReadJSON(w, r, templateRequest)
+	utils.MustValidateStruct(templateRequest)
+
+	template := (&store.WorkoutTemplate{UserID: user.ID}).FromTemplateRequest(templateRequest)
+	weightsToKilograms(unitSystem, templateWeights(template))
+	utils.MustIfError(prepareTemplateEntries(th, user, template.Entries))
+
+	updatedTemplate, err := th.Store.TemplateStore.UpdateTemplate(templateID, template)
+
+	if err != nil {
+		th.Logger.Errorf("failed to update template: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to update template"})
+		return
+	}
+
+	weightsFromKilograms(unitSystem, templateWeights(updatedTemplate))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"template": updatedTemplate})
+}
+
+func (th *TemplatesHandlers) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	templateID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfTemplate(th, user, templateID))
+	utils.MustIfError(th.Store.TemplateStore.DeleteTemplate(templateID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// StartWorkout creates a new workout pre-filled with the planned values of the template.
+func (th *TemplatesHandlers) StartWorkout(w http.ResponseWriter, r *http.Request) {
+	templateID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfTemplate(th, user, templateID))
+	template := utils.Must(th.Store.TemplateStore.GetTemplateById(templateID))
+
+	th.Logger.Info("starting workout from template", zap.Int("template_id", templateID))
+	createdWorkout, err := th.Store.WorkoutStore.CreateWorkout(template.ToWorkout(user.ID))
+
+	if err != nil {
+		th.Logger.Errorf("failed to start workout from template: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to start workout"})
+		return
+	}
+
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), workoutWeights(createdWorkout))
+
+	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"workout": createdWorkout})
+}
+
+func prepareTemplateEntries(th *TemplatesHandlers, user *store.User, entries []store.WorkoutTemplateEntry) error {
+	for i := range entries {
+		if entries[i].ExerciseID == nil {
+			continue
+		}
+
+		exercise, err := visibleExercise(th.Store, user, *entries[i].ExerciseID)
+
+		if err != nil {
+			th.Logger.Error("user cannot use this exercise")
+			return err
+		}
+
+		if entries[i].ExerciseName == "" {
+			entries[i].ExerciseName = exercise.Name
+		}
+	}
+
+	return nil
+}
+
+func checkOwnerOfTemplate(th *TemplatesHandlers, user *store.User, templateID int) error {
+	isTemplateOwner := utils.Must(th.Store.TemplateStore.OwnsTemplate(templateID, user.ID))
+
+	if !isTemplateOwner {
+		th.Logger.Error("user does not own this template")
+		return internalErrors.ErrForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/store"
+	"partiuFit/internal/tracks"
+	"partiuFit/internal/units"
+	"partiuFit/internal/utils"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// trackExercises names the catalog exercise logged for each sport. Sports
+// without one are logged as a free-text entry.
+var trackExercises = map[string]string{
+	tracks.SportRunning: "Running",
+	tracks.SportCycling: "Cycling",
+	tracks.SportWalking: "Walking",
+	tracks.SportOther:   "Cardio",
+}
+
+var trackTitles = map[string]string{
+	tracks.SportRunning: "Corrida",
+	tracks.SportCycling: "Pedalada",
+	tracks.SportWalking: "Caminhada",
+	tracks.SportOther:   "Cardio",
+}
+
+type TracksHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewTracksHandlers(store *store.Store, logger *zap.SugaredLogger) *TracksHandlers {
+	return &TracksHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+// ImportTrack creates a cardio workout from a GPX or TCX file sent as the
+// "file" field of a multipart form. The "sport" field overrides the activity
+// type written by the device.
+func (th *TracksHandlers) ImportTrack(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	unitSystem := negotiateUnitSystem(w, r, user)
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImportSize)
+	file, _, err := r.FormFile("file")
+
+	if err != nil {
+		panic(fmt.Errorf("%w: %v", internalErrors.ErrInvalidImportFile, err))
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	importTrackRequest := &requests.ImportTrackRequest{Sport: r.FormValue("sport")}
+	utils.MustValidateStruct(importTrackRequest)
+
+	data := utils.Must(io.ReadAll(file))
+	track, err := tracks.Parse(data)
+
+	if err != nil {
+		panic(fmt.Errorf("%w: %v", internalErrors.ErrInvalidImportFile, err))
+	}
+
+	if importTrackRequest.Sport != "" {
+		track.Sport = importTrackRequest.Sport
+	}
+
+	summary := tracks.Analyze(track.Points)
+
+	if summary.StartedAt == nil {
+		panic(fmt.Errorf("%w: o trajeto não tem horários", internalErrors.ErrInvalidImportFile))
+	}
+
+	workout := utils.Must(trackWorkout(th.Store, user, track, summary))
+	workoutTrack := &store.WorkoutTrack{
+		Sport:           track.Sport,
+		SourceFormat:    track.Format,
+		Distance:        math.Round(summary.DistanceMeters) / 1000,
+		DurationSeconds: summary.DurationSeconds,
+		AveragePace:     summary.AveragePace,
+		BestPace:        summary.BestPace,
+		ElevationGain:   summary.ElevationGain,
+		Points:          track.Points,
+	}
+
+	th.Logger.Info("importing track", zap.String("format", track.Format), zap.Int("points", len(track.Points)))
+	createdWorkout := utils.Must(th.Store.WorkoutTrackStore.CreateTrackWorkout(workout, workoutTrack))
+
+	// the points were just sent by the client, GET /workouts/{id}/track has them
+	createdWorkout.Track.Points = nil
+	trackFromKilometers(unitSystem, createdWorkout.Track)
+	weightsFromKilograms(unitSystem, workoutWeights(createdWorkout))
+
+	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"workout": createdWorkout})
+}
+
+// GetTrack returns the recorded points of a workout with its splits, every
+// kilometer or every mile depending on the unit system.
+func (th *TracksHandlers) GetTrack(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	unitSystem := negotiateUnitSystem(w, r, user)
+
+	isWorkoutOwner := utils.Must(th.Store.WorkoutStore.OwnsWorkout(workoutID, user.ID))
+
+	if !isWorkoutOwner {
+		th.Logger.Error("user does not own this workout")
+		panic(internalErrors.ErrForbidden)
+	}
+
+	track := utils.Must(th.Store.WorkoutTrackStore.GetTrack(workoutID))
+	splitMeters := 1000.0
+
+	if unitSystem == units.Imperial {
+		splitMeters = 1000 / units.MilesPerKilometer
+	}
+
+	splits := tracks.Splits(track.Points, splitMeters)
+
+	for i := range splits {
+		splits[i].Distance = units.FromKilometers(splits[i].Distance, unitSystem)
+		splits[i].Pace = paceFromSecondsPerKilometer(unitSystem, splits[i].Pace)
+	}
+
+	trackFromKilometers(unitSystem, track)
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"track": track, "splits": splits})
+}
+
+// trackWorkout builds the workout of a track, a single timed entry of the
+// exercise matching its sport.
+func trackWorkout(appStore *store.Store, user *store.User, track *tracks.Track, summary tracks.Summary) (*store.Workout, error) {
+	exerciseName := trackExercises[track.Sport]
+	exercise, err := appStore.ExerciseStore.FindExerciseByName(user.ID, exerciseName)
+
+	if err != nil && !errors.Is(err, internalErrors.ErrNoRows) {
+		return nil, err
+	}
+
+	title := track.Name
+
+	if title == "" {
+		title = trackTitles[track.Sport]
+	}
+
+	startedAt := summary.StartedAt.UTC()
+	finishedAt := startedAt.Add(time.Duration(summary.DurationSeconds) * time.Second)
+	importKey := fmt.Sprintf("%s:%s", track.Format, startedAt.Format(time.RFC3339))
+	durationSeconds := summary.DurationSeconds
+
+	entry := store.WorkoutEntry{
+		ExerciseName:    exerciseName,
+		Sets:            1,
+		DurationSeconds: &durationSeconds,
+		UserID:          user.ID,
+		WorkoutSets: []store.WorkoutSet{{
+			SetNumber:       1,
+			SetType:         store.SetTypeWorking,
+			DurationSeconds: &durationSeconds,
+			Completed:       true,
+		}},
+	}
+
+	if exercise != nil {
+		entry.ExerciseID = &exercise.ID
+		entry.ExerciseName = exercise.Name
+	}
+
+	return &store.Workout{
+		UserID:          user.ID,
+		Title:           title,
+		DurationMinutes: int(math.Round(float64(summary.DurationSeconds) / 60)),
+		Status:          store.WorkoutCompleted,
+		CreatedAt:       &startedAt,
+		StartedAt:       &startedAt,
+		FinishedAt:      &finishedAt,
+		ImportKey:       &importKey,
+		Entries:         []store.WorkoutEntry{entry},
+	}, nil
+}
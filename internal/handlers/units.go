@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"cmp"
+	"net/http"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/store"
+	"partiuFit/internal/units"
+	"partiuFit/internal/utils"
+)
+
+// UnitSystemHeader lets a single request read and write in another unit
+// system. Responses echo the system their values are expressed in.
+const UnitSystemHeader = "X-Unit-System"
+
+// negotiateUnitSystem picks the unit system of the request: the "units" query
+// param, then the X-Unit-System header, then the user's preference.
+func negotiateUnitSystem(w http.ResponseWriter, r *http.Request, user *store.User) string {
+	unitsRequest := &requests.UnitsRequest{
+		UnitSystem: cmp.Or(r.URL.Query().Get("units"), r.Header.Get(UnitSystemHeader)),
+	}
+	utils.MustValidateStruct(unitsRequest)
+
+	system := cmp.Or(unitsRequest.UnitSystem, user.UnitSystem, units.Metric)
+	w.Header().Set(UnitSystemHeader, system)
+
+	return system
+}
+
+func weightsToKilograms(system string, weights []*float64) {
+	for _, weight := range weights {
+		*weight = units.ToKilograms(*weight, system)
+	}
+}
+
+func weightsFromKilograms(system string, weights []*float64) {
+	for _, weight := range weights {
+		*weight = units.FromKilograms(*weight, system)
+	}
+}
+
+// The functions below point to every weight of a response or request body, so
+// the same list serves the conversion in both directions.
+
+func workoutWeights(workout *store.Workout) []*float64 {
+	weights := entryWeights(workout.Entries)
+
+	return append(weights, personalRecordWeights(workout.PersonalRecords)...)
+}
+
+func entryWeights(entries []store.WorkoutEntry) []*float64 {
+	weights := make([]*float64, 0)
+
+	for i := range entries {
+		weights = append(weights, &entries[i].Weight)
+
+		for j := range entries[i].WorkoutSets {
+			weights = append(weights, &entries[i].WorkoutSets[j].Weight)
+		}
+	}
+
+	return weights
+}
+
+func feedWeights(items []store.FeedItem) []*float64 {
+	weights := make([]*float64, 0)
+
+	for _, item := range items {
+		entries := item.Workout.Entries
+
+		for i := range entries {
+			weights = append(weights, &entries[i].Weight)
+
+			for j := range entries[i].WorkoutSets {
+				weights = append(weights, &entries[i].WorkoutSets[j].Weight)
+			}
+		}
+	}
+
+	return weights
+}
+
+func templateWeights(templates ...*store.WorkoutTemplate) []*float64 {
+	weights := make([]*float64, 0)
+
+	for _, template := range templates {
+		for i := range template.Entries {
+			weights = append(weights, &template.Entries[i].TargetWeight)
+		}
+	}
+
+	return weights
+}
+
+// personalRecordWeights skips the value of max_reps records, which counts reps.
+func personalRecordWeights(records []store.PersonalRecord) []*float64 {
+	weights := make([]*float64, 0)
+
+	for i := range records {
+		if records[i].RecordType != store.RecordMaxReps {
+			weights = append(weights, &records[i].Value)
+		}
+
+		if records[i].Weight != nil {
+			weights = append(weights, records[i].Weight)
+		}
+	}
+
+	return weights
+}
+
+func progressWeights(buckets []store.ProgressBucket) []*float64 {
+	weights := make([]*float64, 0, len(buckets)*3)
+
+	for i := range buckets {
+		weights = append(weights, &buckets[i].TotalVolume, &buckets[i].MaxWeight, &buckets[i].BestEstimated1RM)
+	}
+
+	return weights
+}
+
+func summaryWeights(periods []store.SummaryPeriod) []*float64 {
+	weights := make([]*float64, 0, len(periods)*2)
+
+	for i := range periods {
+		weights = append(weights, &periods[i].TotalVolume, &periods[i].Deltas.TotalVolume)
+	}
+
+	return weights
+}
+
+// goalWeights only converts lift goals, the other goal types count workouts,
+// calories or minutes.
+func goalWeights(goals ...*store.Goal) []*float64 {
+	weights := make([]*float64, 0)
+
+	for _, goal := range goals {
+		if goal.GoalType != store.GoalLiftWeight {
+			continue
+		}
+
+		weights = append(weights, &goal.Target)
+
+		if goal.Progress != nil {
+			weights = append(weights, &goal.Progress.Current, &goal.Progress.Projected)
+		}
+	}
+
+	return weights
+}
+
+// measurementWeights converts bodyweight only; circumferences stay in centimeters.
+func measurementWeights(measurements ...*store.BodyMeasurement) []*float64 {
+	weights := make([]*float64, 0, len(measurements))
+
+	for _, measurement := range measurements {
+		if measurement.Weight != nil {
+			weights = append(weights, measurement.Weight)
+		}
+	}
+
+	return weights
+}
+
+func measurementRequestWeights(measurementRequest *requests.BodyMeasurementRequest) []*float64 {
+	if measurementRequest.Weight == nil {
+		return nil
+	}
+
+	return []*float64{measurementRequest.Weight}
+}
+
+func measurementPointWeights(points []store.MeasurementPoint) []*float64 {
+	weights := make([]*float64, 0, len(points))
+
+	for i := range points {
+		weights = append(weights, &points[i].Value)
+	}
+
+	return weights
+}
+
+// comparisonWeights also covers volumes, which scale with the weight.
+func comparisonWeights(comparison *store.WorkoutComparison) []*float64 {
+	weights := make([]*float64, 0)
+	exercises := []*store.ExerciseComparison{&comparison.Totals}
+
+	for i := range comparison.Exercises {
+		exercises = append(exercises, &comparison.Exercises[i])
+	}
+
+	for _, exercise := range exercises {
+		for _, totals := range []*store.ExerciseTotals{&exercise.A, &exercise.B, &exercise.Delta} {
+			weights = append(weights, &totals.TopWeight, &totals.Volume)
+		}
+	}
+
+	return weights
+}
+
+// trackFromKilometers converts the distance and paces of a track, which are
+// stored per kilometer.
+func trackFromKilometers(system string, track *store.WorkoutTrack) {
+	if track == nil {
+		return
+	}
+
+	track.Distance = units.FromKilometers(track.Distance, system)
+	track.AveragePace = paceFromSecondsPerKilometer(system, track.AveragePace)
+	track.BestPace = paceFromSecondsPerKilometer(system, track.BestPace)
+}
+
+func paceFromSecondsPerKilometer(system string, pace *float64) *float64 {
+	if pace == nil {
+		return nil
+	}
+
+	converted := units.PaceFromSecondsPerKilometer(*pace, system)
+
+	return &converted
+}
@@ -8,6 +8,7 @@ import (
 	"partiuFit/internal/requests"
 	"partiuFit/internal/store"
 	"partiuFit/internal/utils"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -55,6 +56,9 @@ func (uh *UserHandlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	userRequest := &requests.UserRequest{}
 
 	utils.MustReadJSON(w, r, userRequest)
+	utils.MustIfError(utils.Validation.Var(userRequest.TimeZone, "omitempty,timezone"))
+	utils.MustIfError(utils.Validation.Var(userRequest.UnitSystem, "omitempty,oneof=metric imperial"))
+	utils.MustIfError(utils.Validation.Var(userRequest.DefaultVisibility, "omitempty,oneof=public followers private"))
 	user.FromUserRequest(userRequest)
 
 	uh.Logger.Info("updating user", zap.String("name", userRequest.Name))
@@ -68,3 +72,36 @@ func (uh *UserHandlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"user": user})
 }
+
+// ConvertWeightHistory converts the weights logged before units existed from
+// pounds to kilograms, for users whose history was entered in pounds. It can
+// only run once per user.
+func (uh *UserHandlers) ConvertWeightHistory(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	convertRequest := &requests.ConvertWeightsRequest{}
+
+	utils.MustReadJSON(w, r, convertRequest)
+	utils.MustValidateStruct(convertRequest)
+
+	before, err := time.Parse(time.RFC3339, convertRequest.Before)
+
+	if err != nil {
+		utils.MustWriteJSON(w, http.StatusBadRequest, utils.Envelope{"error": "invalid before"})
+		return
+	}
+
+	uh.Logger.Info("converting weight history", zap.Int("user_id", user.ID), zap.Time("before", before))
+	converted, err := uh.Store.WorkoutStore.ConvertPoundsHistory(user.ID, before)
+
+	if errors.Is(err, internalErrors.ErrWeightsAlreadyConverted) {
+		panic(err)
+	}
+
+	if err != nil {
+		uh.Logger.Errorf("failed to convert weight history: %v", err)
+		utils.MustWriteJSON(w, http.StatusInternalServerError, utils.Envelope{"error": "failed to convert weight history"})
+		return
+	}
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"converted_workouts": converted})
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+// PatchWorkoutEntryRequest changes only the fields it carries. workout_sets
+// replaces the sets, keeping the ids sent back; the legacy sets, reps, weight
+// and duration_seconds fields alone rebuild them. "group": null takes the
+// entry out of its group.
+type PatchWorkoutEntryRequest struct {
+	ExerciseID      *int               `json:"exercise_id"`
+	ExerciseName    *string            `json:"exercise_name" validate:"omitempty,max=255"`
+	Sets            *int               `json:"sets" validate:"omitempty,min=1"`
+	Reps            *int               `json:"reps" validate:"omitempty,min=0"`
+	Weight          *float64           `json:"weight" validate:"omitempty,min=0"`
+	DurationSeconds *int               `json:"duration_seconds" validate:"omitempty,min=0"`
+	Notes           *string            `json:"notes"`
+	OrderIndex      *int               `json:"order_index"`
+	Group           optionalEntryGroup `json:"group"`
+	WorkoutSets     []store.WorkoutSet `json:"workout_sets" validate:"omitempty,dive"`
+}
+
+// optionalEntryGroup tells an omitted group, which is left alone, from a null
+// one, which removes it.
+type optionalEntryGroup struct {
+	Present bool
+	Group   *store.EntryGroup
+}
+
+func (g *optionalEntryGroup) UnmarshalJSON(data []byte) error {
+	g.Present = true
+
+	return json.Unmarshal(data, &g.Group)
+}
+
+type ReorderEntriesRequest struct {
+	EntryIDs []int `json:"entry_ids" validate:"required,min=1"`
+}
+
+func (wh *WorkoutsHandlers) GetEntries(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfWorkout(wh, w, user, workoutID))
+	entries := utils.Must(wh.Store.WorkoutEntryStore.GetEntries(workoutID))
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), entryWeights(entries))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"entries": entries})
+}
+
+func (wh *WorkoutsHandlers) GetEntry(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	entryID := utils.Must(utils.ReadIntParam(r, "entryID"))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfWorkout(wh, w, user, workoutID))
+	entry := utils.Must(wh.Store.WorkoutEntryStore.GetEntry(workoutID, entryID))
+	entries := []store.WorkoutEntry{*entry}
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), entryWeights(entries))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"entry": entries[0]})
+}
+
+// CreateEntry adds an entry to the workout, at the end unless order_index is
+// given.
+func (wh *WorkoutsHandlers) CreateEntry(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfWorkout(wh, w, user, workoutID))
+
+	unitSystem := negotiateUnitSystem(w, r, user)
+	entries := make([]store.WorkoutEntry, 1)
+	utils.MustReadJSON(w, r, &entries[0])
+	utils.MustValidateStruct(&entries[0])
+	weightsToKilograms(unitSystem, entryWeights(entries))
+	utils.MustIfError(prepareEntries(wh, user, entries))
+
+	entries[0].ID = 0
+	createdEntry := utils.Must(wh.Store.WorkoutEntryStore.CreateEntry(workoutID, &entries[0]))
+	weightsFromKilograms(unitSystem, entryWeights(entries))
+
+	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"entry": createdEntry})
+}
+
+// PatchEntry edits a single entry in place, so its id and the ids of the sets
+// sent back stay the same.
+func (wh *WorkoutsHandlers) PatchEntry(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	entryID := utils.Must(utils.ReadIntParam(r, "entryID"))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfWorkout(wh, w, user, workoutID))
+	existingEntry := utils.Must(wh.Store.WorkoutEntryStore.GetEntry(workoutID, entryID))
+
+	unitSystem := negotiateUnitSystem(w, r, user)
+	patch := &PatchWorkoutEntryRequest{}
+	utils.MustReadJSON(w, r, patch)
+	utils.MustValidateStruct(patch)
+
+	weightsToKilograms(unitSystem, patchWeights(patch))
+
+	entries := []store.WorkoutEntry{*existingEntry}
+	entry := &entries[0]
+	applyEntryPatch(entry, patch)
+
+	entry.ID = entryID
+	utils.MustIfError(prepareEntries(wh, user, entries))
+
+	updatedEntry := utils.Must(wh.Store.WorkoutEntryStore.UpdateEntry(workoutID, entry))
+	weightsFromKilograms(unitSystem, entryWeights(entries))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"entry": updatedEntry})
+}
+
+func (wh *WorkoutsHandlers) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	entryID := utils.Must(utils.ReadIntParam(r, "entryID"))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfWorkout(wh, w, user, workoutID))
+	utils.MustIfError(wh.Store.WorkoutEntryStore.DeleteEntry(workoutID, entryID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReorderEntries puts the entries in the order of entry_ids, which lists every
+// entry of the workout once.
+func (wh *WorkoutsHandlers) ReorderEntries(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfWorkout(wh, w, user, workoutID))
+
+	reorderRequest := &ReorderEntriesRequest{}
+	utils.MustReadJSON(w, r, reorderRequest)
+	utils.MustValidateStruct(reorderRequest)
+
+	wh.Logger.Info("reordering workout entries", zap.Int("workout_id", workoutID), zap.Int("entries", len(reorderRequest.EntryIDs)))
+	entries := utils.Must(wh.Store.WorkoutEntryStore.ReorderEntries(workoutID, reorderRequest.EntryIDs))
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), entryWeights(entries))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"entries": entries})
+}
+
+func patchWeights(patch *PatchWorkoutEntryRequest) []*float64 {
+	weights := make([]*float64, 0)
+
+	if patch.Weight != nil {
+		weights = append(weights, patch.Weight)
+	}
+
+	for i := range patch.WorkoutSets {
+		weights = append(weights, &patch.WorkoutSets[i].Weight)
+	}
+
+	return weights
+}
+
+// applyEntryPatch copies the fields of the patch onto the entry, both in
+// kilograms.
+func applyEntryPatch(entry *store.WorkoutEntry, patch *PatchWorkoutEntryRequest) {
+	if patch.ExerciseID != nil {
+		entry.ExerciseID = patch.ExerciseID
+		entry.ExerciseName = ""
+	}
+
+	if patch.ExerciseName != nil {
+		entry.ExerciseName = *patch.ExerciseName
+
+		// a new name is matched against the catalog again
+		if patch.ExerciseID == nil {
+			entry.ExerciseID = nil
+		}
+	}
+
+	if patch.Notes != nil {
+		entry.Notes = *patch.Notes
+	}
+
+	if patch.OrderIndex != nil {
+		entry.OrderIndex = *patch.OrderIndex
+	}
+
+	if patch.Group.Present {
+		entry.Group = patch.Group.Group
+	}
+
+	if patch.WorkoutSets != nil {
+		entry.WorkoutSets = patch.WorkoutSets
+		return
+	}
+
+	if patch.Sets == nil && patch.Reps == nil && patch.Weight == nil && patch.DurationSeconds == nil {
+		return
+	}
+
+	if patch.Sets != nil {
+		entry.Sets = *patch.Sets
+	}
+
+	if patch.Reps != nil {
+		entry.Reps = patch.Reps
+	}
+
+	if patch.Weight != nil {
+		entry.Weight = *patch.Weight
+	}
+
+	if patch.DurationSeconds != nil {
+		entry.DurationSeconds = patch.DurationSeconds
+	}
+
+	entry.WorkoutSets = nil
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type WorkoutRevisionsHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewWorkoutRevisionsHandlers(store *store.Store, logger *zap.SugaredLogger) *WorkoutRevisionsHandlers {
+	return &WorkoutRevisionsHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+// GetRevisions lists who changed the workout, when and which fields.
+func (rh *WorkoutRevisionsHandlers) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfRevisions(rh, user, workoutID))
+	revisions := utils.Must(rh.Store.WorkoutRevisionStore.GetRevisions(workoutID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"revisions": revisions})
+}
+
+// GetRevision returns the workout as it was at a revision.
+func (rh *WorkoutRevisionsHandlers) GetRevision(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	revisionNumber := utils.Must(utils.ReadIntParam(r, "rev"))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfRevisions(rh, user, workoutID))
+	revision := utils.Must(rh.Store.WorkoutRevisionStore.GetRevision(workoutID, revisionNumber))
+	unitSystem := negotiateUnitSystem(w, r, user)
+	weightsFromKilograms(unitSystem, workoutWeights(revision.Workout))
+	trackFromKilometers(unitSystem, revision.Workout.Track)
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"revision": revision})
+}
+
+// RevertWorkout brings the workout back to a revision, recording the revert
+// as a new revision.
+func (rh *WorkoutRevisionsHandlers) RevertWorkout(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	revisionNumber := utils.Must(utils.ReadIntParam(r, "rev"))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfRevisions(rh, user, workoutID))
+
+	rh.Logger.Info("reverting workout", zap.Int("workout_id", workoutID), zap.Int("revision", revisionNumber))
+	revertedWorkout := utils.Must(rh.Store.WorkoutRevisionStore.RevertWorkout(workoutID, revisionNumber, user.ID))
+	unitSystem := negotiateUnitSystem(w, r, user)
+	weightsFromKilograms(unitSystem, workoutWeights(revertedWorkout))
+	trackFromKilometers(unitSystem, revertedWorkout.Track)
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"workout": revertedWorkout})
+}
+
+func checkOwnerOfRevisions(rh *WorkoutRevisionsHandlers, user *store.User, workoutID int) error {
+	isWorkoutOwner := utils.Must(rh.Store.WorkoutStore.OwnsWorkout(workoutID, user.ID))
+
+	if !isWorkoutOwner {
+		rh.Logger.Error("user does not own this workout")
+		return internalErrors.ErrForbidden
+	}
+
+	return nil
+}
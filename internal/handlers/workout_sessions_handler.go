@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type WorkoutSessionsHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+// LogSetRequest logs a set into an existing entry of the session, or into a
+// new entry for the given exercise when entry_id is omitted.
+type LogSetRequest struct {
+	EntryID      *int             `json:"entry_id"`
+	ExerciseID   *int             `json:"exercise_id"`
+	ExerciseName string           `json:"exercise_name" validate:"required_without_all=EntryID ExerciseID,max=255"`
+	Set          store.WorkoutSet `json:"set"`
+}
+
+func NewWorkoutSessionsHandlers(store *store.Store, logger *zap.SugaredLogger) *WorkoutSessionsHandlers {
+	return &WorkoutSessionsHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+func (sh *WorkoutSessionsHandlers) GetSession(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfSession(sh, user, workoutID))
+	session := utils.Must(sh.Store.WorkoutSessionStore.GetSession(workoutID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"session": session})
+}
+
+func (sh *WorkoutSessionsHandlers) StartSession(w http.ResponseWriter, r *http.Request) {
+	sh.transition(w, r, store.SessionStart)
+}
+
+func (sh *WorkoutSessionsHandlers) PauseSession(w http.ResponseWriter, r *http.Request) {
+	sh.transition(w, r, store.SessionPause)
+}
+
+func (sh *WorkoutSessionsHandlers) ResumeSession(w http.ResponseWriter, r *http.Request) {
+	sh.transition(w, r, store.SessionResume)
+}
+
+func (sh *WorkoutSessionsHandlers) FinishSession(w http.ResponseWriter, r *http.Request) {
+	sh.transition(w, r, store.SessionFinish)
+}
+
+func (sh *WorkoutSessionsHandlers) transition(w http.ResponseWriter, r *http.Request, event string) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfSession(sh, user, workoutID))
+
+	sh.Logger.Info("workout session transition", zap.Int("workout_id", workoutID), zap.String("event", event))
+	session := utils.Must(sh.Store.WorkoutSessionStore.TransitionSession(workoutID, event))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"session": session})
+}
+
+// LogSet records a set while the session is running and returns the entry it
+// was added to, with every set logged so far.
+func (sh *WorkoutSessionsHandlers) LogSet(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfSession(sh, user, workoutID))
+
+	unitSystem := negotiateUnitSystem(w, r, user)
+	logSetRequest := &LogSetRequest{}
+	utils.MustReadJSON(w, r, logSetRequest)
+	utils.MustValidateStruct(logSetRequest)
+	weightsToKilograms(unitSystem, []*float64{&logSetRequest.Set.Weight})
+
+	var entry *store.WorkoutEntry
+
+	if logSetRequest.EntryID == nil {
+		entry = &store.WorkoutEntry{ExerciseID: logSetRequest.ExerciseID, ExerciseName: logSetRequest.ExerciseName}
+
+		if entry.ExerciseID != nil {
+			exercise := utils.Must(visibleExercise(sh.Store, user, *entry.ExerciseID))
+			entry.ExerciseName = exercise.Name
+		}
+	}
+
+	loggedEntry := utils.Must(sh.Store.WorkoutSessionStore.LogSessionSet(workoutID, logSetRequest.EntryID, entry, logSetRequest.Set))
+	weightsFromKilograms(unitSystem, entryWeights([]store.WorkoutEntry{*loggedEntry}))
+
+	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"entry": loggedEntry})
+}
+
+func checkOwnerOfSession(sh *WorkoutSessionsHandlers, user *store.User, workoutID int) error {
+	isWorkoutOwner := utils.Must(sh.Store.WorkoutStore.OwnsWorkout(workoutID, user.ID))
+
+	if !isWorkoutOwner {
+		sh.Logger.Error("user does not own this workout")
+		return internalErrors.ErrForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/middlewares"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/store"
+	"partiuFit/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type WorkoutSharesHandlers struct {
+	Store  *store.Store
+	Logger *zap.SugaredLogger
+}
+
+func NewWorkoutSharesHandlers(store *store.Store, logger *zap.SugaredLogger) *WorkoutSharesHandlers {
+	return &WorkoutSharesHandlers{
+		Store:  store,
+		Logger: logger,
+	}
+}
+
+// CreateShare creates a public link to the workout. The token comes back only
+// in this response; expires_at is optional.
+func (sh *WorkoutSharesHandlers) CreateShare(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfShares(sh, user, workoutID))
+
+	shareRequest := &requests.CreateShareRequest{}
+
+	if r.ContentLength > 0 {
+		utils.MustReadJSON(w, r, shareRequest)
+	}
+
+	utils.MustValidateStruct(shareRequest)
+
+	sh.Logger.Info("sharing workout", zap.Int("workout_id", workoutID))
+	share := utils.Must(sh.Store.WorkoutShareStore.CreateShare(workoutID, user.ID, shareRequest.ExpiresAt))
+
+	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"share": share})
+}
+
+// GetShares lists the links of the user that still open a workout.
+func (sh *WorkoutSharesHandlers) GetShares(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	shares := utils.Must(sh.Store.WorkoutShareStore.GetShares(user.ID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"shares": shares})
+}
+
+func (sh *WorkoutSharesHandlers) RevokeShare(w http.ResponseWriter, r *http.Request) {
+	shareID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(sh.Store.WorkoutShareStore.RevokeShare(shareID, user.ID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSharedWorkout is public: anyone with the token sees the workout, without
+// ids or anything about its owner. Weights are in kilograms unless the request
+// asks for another unit system.
+func (sh *WorkoutSharesHandlers) GetSharedWorkout(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(sh.Store.WorkoutShareStore.GetSharedWorkoutID(chi.URLParam(r, "token")))
+	workout := utils.Must(sh.Store.WorkoutStore.GetWorkoutById(workoutID))
+
+	unitSystem := negotiateUnitSystem(w, r, store.AnonymousUser)
+	weightsFromKilograms(unitSystem, workoutWeights(workout))
+	trackFromKilometers(unitSystem, workout.Track)
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"workout": store.NewSharedWorkout(workout)})
+}
+
+func checkOwnerOfShares(sh *WorkoutSharesHandlers, user *store.User, workoutID int) error {
+	isWorkoutOwner := utils.Must(sh.Store.WorkoutStore.OwnsWorkout(workoutID, user.ID))
+
+	if !isWorkoutOwner {
+		sh.Logger.Error("user does not own this workout")
+		return internalErrors.ErrForbidden
+	}
+
+	return nil
+}
@@ -1,11 +1,19 @@
 package handlers
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
 	"net/http"
 	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/export"
 	"partiuFit/internal/middlewares"
+	"partiuFit/internal/requests"
 	"partiuFit/internal/store"
+	"partiuFit/internal/units"
 	"partiuFit/internal/utils"
+	"slices"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -20,7 +28,9 @@ type UpdateWorkoutRequest struct {
 	Description     *string              `json:"description"`
 	DurationMinutes *int                 `json:"duration_minutes"`
 	CaloriesBurned  *int                 `json:"calories_burned"`
-	Entries         []store.WorkoutEntry `json:"entries"`
+	Entries         []store.WorkoutEntry `json:"entries" validate:"dive"`
+	Tags            []string             `json:"tags" validate:"max=20,dive,max=50"`
+	Visibility      *string              `json:"visibility" validate:"omitempty,oneof=public followers private"`
 }
 
 func NewWorkoutsHandlers(store *store.Store, logger *zap.SugaredLogger) *WorkoutsHandlers {
@@ -33,7 +43,37 @@ func NewWorkoutsHandlers(store *store.Store, logger *zap.SugaredLogger) *Workout
 func (wh *WorkoutsHandlers) GetWorkouts(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	user := middlewares.GetUser(r)
-	workouts, err := wh.Store.WorkoutStore.GetAllWorkouts(user.ID)
+	query := r.URL.Query()
+
+	listRequest := &requests.ListWorkoutsRequest{
+		Limit:    utils.Must(utils.ReadIntQueryParam(r, "limit", store.DefaultPageLimit)),
+		Cursor:   query.Get("cursor"),
+		Search:   query.Get("q"),
+		Sort:     query.Get("sort"),
+		Order:    query.Get("order"),
+		Tags:     utils.ReadListQueryParam(r, "tags"),
+		TagMatch: query.Get("tag_match"),
+	}
+	utils.MustValidateStruct(listRequest)
+
+	from, to, err := utils.ReadTimeRangeQueryParams(r)
+	utils.MustIfError(err)
+
+	workouts, pagination, err := wh.Store.WorkoutStore.GetAllWorkouts(user.ID, store.WorkoutFilters{
+		Limit:    listRequest.Limit,
+		Cursor:   listRequest.Cursor,
+		From:     from,
+		To:       to,
+		Search:   listRequest.Search,
+		Sort:     listRequest.Sort,
+		Order:    listRequest.Order,
+		Tags:     listRequest.Tags,
+		TagMatch: listRequest.TagMatch,
+	})
+
+	if errors.Is(err, internalErrors.ErrInvalidCursor) {
+		panic(err)
+	}
 
 	if err != nil {
 		wh.Logger.Error("failed to get workouts", zap.Error(err))
@@ -41,7 +81,8 @@ func (wh *WorkoutsHandlers) GetWorkouts(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"workouts": workouts})
+	utils.SetNextLinkHeader(w, r, pagination.NextCursor)
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"workouts": workouts, "pagination": pagination})
 }
 
 func (wh *WorkoutsHandlers) GetWorkoutByID(w http.ResponseWriter, r *http.Request) {
@@ -56,13 +97,30 @@ func (wh *WorkoutsHandlers) GetWorkoutByID(w http.ResponseWriter, r *http.Reques
 
 	utils.MustIfError(checkOwnerOfWorkout(wh, w, user, workoutID))
 	workout := utils.Must(wh.Store.WorkoutStore.GetWorkoutById(workoutID))
+	unitSystem := negotiateUnitSystem(w, r, user)
+	weightsFromKilograms(unitSystem, workoutWeights(workout))
+	trackFromKilometers(unitSystem, workout.Track)
 
 	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"workout": workout})
 }
 
 func (wh *WorkoutsHandlers) CreateWorkout(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	unitSystem := negotiateUnitSystem(w, r, user)
 	workout := &store.Workout{}
 	utils.MustReadJSON(w, r, workout)
+	utils.MustValidateStruct(workout)
+	utils.MustIfError(store.ValidateEntryGroups(workout.Entries))
+	weightsToKilograms(unitSystem, workoutWeights(workout))
+
+	workout.UserID = user.ID
+	workout.TemplateID = nil
+	workout.CaloriesEstimated = false
+	workout.CaloriesFormula = nil
+	workout.CreatedAt = nil
+	workout.StartedAt = nil
+	workout.FinishedAt = nil
+	utils.MustIfError(prepareEntries(wh, user, workout.Entries))
 
 	wh.Logger.Info("creating workout", zap.String("title", workout.Title))
 	createdWorkout, err := wh.Store.WorkoutStore.CreateWorkout(workout)
@@ -73,6 +131,7 @@ func (wh *WorkoutsHandlers) CreateWorkout(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	weightsFromKilograms(unitSystem, workoutWeights(createdWorkout))
 	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"workout": createdWorkout})
 }
 
@@ -89,8 +148,12 @@ func (wh *WorkoutsHandlers) UpdateWorkout(w http.ResponseWriter, r *http.Request
 
 	utils.MustIfError(checkOwnerOfWorkout(wh, w, user, workoutID))
 
+	unitSystem := negotiateUnitSystem(w, r, user)
 	workout := &UpdateWorkoutRequest{}
 	utils.MustReadJSON(w, r, workout)
+	utils.MustValidateStruct(workout)
+	utils.MustIfError(store.ValidateEntryGroups(workout.Entries))
+	weightsToKilograms(unitSystem, entryWeights(workout.Entries))
 
 	if workout.Title != nil {
 		existingWorkout.Title = *workout.Title
@@ -106,12 +169,23 @@ func (wh *WorkoutsHandlers) UpdateWorkout(w http.ResponseWriter, r *http.Request
 
 	if workout.CaloriesBurned != nil {
 		existingWorkout.CaloriesBurned = *workout.CaloriesBurned
+		existingWorkout.CaloriesEstimated = false
+		existingWorkout.CaloriesFormula = nil
 	}
 
 	if workout.Entries != nil {
+		utils.MustIfError(prepareEntries(wh, user, workout.Entries))
 		existingWorkout.Entries = workout.Entries
 	}
 
+	if workout.Tags != nil {
+		existingWorkout.Tags = workout.Tags
+	}
+
+	if workout.Visibility != nil {
+		existingWorkout.Visibility = workout.Visibility
+	}
+
 	updatedWorkout, err := wh.Store.WorkoutStore.UpdateWorkout(workoutID, existingWorkout)
 
 	if err != nil {
@@ -120,6 +194,8 @@ func (wh *WorkoutsHandlers) UpdateWorkout(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	weightsFromKilograms(unitSystem, workoutWeights(updatedWorkout))
+	trackFromKilometers(unitSystem, updatedWorkout.Track)
 	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"workout": updatedWorkout})
 }
 
@@ -133,6 +209,225 @@ func (wh *WorkoutsHandlers) DeleteWorkout(w http.ResponseWriter, r *http.Request
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// GetTrash lists the deleted workouts that can still be restored.
+func (wh *WorkoutsHandlers) GetTrash(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	workouts := utils.Must(wh.Store.WorkoutStore.GetTrash(user.ID))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"workouts": workouts})
+}
+
+// RestoreWorkout takes a workout out of the trash, with its entries and sets.
+func (wh *WorkoutsHandlers) RestoreWorkout(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	restoredWorkout := utils.Must(wh.Store.WorkoutStore.RestoreWorkout(workoutID, user.ID))
+	unitSystem := negotiateUnitSystem(w, r, user)
+	weightsFromKilograms(unitSystem, workoutWeights(restoredWorkout))
+	trackFromKilometers(unitSystem, restoredWorkout.Track)
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"workout": restoredWorkout})
+}
+
+// DuplicateWorkout copies a workout, entries and sets included, into a new
+// planned workout, or a completed one when asked to. With a date (YYYY-MM-DD)
+// the copy keeps the time of day of the original on that date; otherwise it is
+// dated now.
+func (wh *WorkoutsHandlers) DuplicateWorkout(w http.ResponseWriter, r *http.Request) {
+	workoutID := utils.Must(utils.ReadIDParam(r))
+	user := middlewares.GetUser(r)
+
+	utils.MustIfError(checkOwnerOfWorkout(wh, w, user, workoutID))
+
+	duplicateRequest := &requests.DuplicateWorkoutRequest{}
+
+	if r.ContentLength > 0 {
+		utils.MustReadJSON(w, r, duplicateRequest)
+	}
+
+	utils.MustValidateStruct(duplicateRequest)
+
+	workout := utils.Must(wh.Store.WorkoutStore.GetWorkoutById(workoutID))
+	var createdAt *time.Time
+
+	if duplicateRequest.Date != "" {
+		day := utils.Must(time.ParseInLocation(time.DateOnly, duplicateRequest.Date, user.Location()))
+		original := workout.CreatedAt.In(user.Location())
+		createdAt = utils.ValueToPointer(time.Date(
+			day.Year(), day.Month(), day.Day(), original.Hour(), original.Minute(), original.Second(), 0, user.Location(),
+		))
+	}
+
+	duplicate := workout.Duplicate(user.ID, createdAt, duplicateRequest.Completed)
+
+	if duplicateRequest.Title != nil {
+		duplicate.Title = *duplicateRequest.Title
+	}
+
+	wh.Logger.Info("duplicating workout", zap.Int("workout_id", workoutID))
+	createdWorkout := utils.Must(wh.Store.WorkoutStore.CreateWorkout(duplicate))
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), workoutWeights(createdWorkout))
+
+	utils.MustWriteJSON(w, http.StatusCreated, utils.Envelope{"workout": createdWorkout})
+}
+
+// CompareWorkouts tells, per exercise, how the sets, reps, top weight and
+// volume of workout b changed from workout a.
+func (wh *WorkoutsHandlers) CompareWorkouts(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+
+	compareRequest := &requests.CompareWorkoutsRequest{
+		A: utils.Must(utils.ReadIntQueryParam(r, "a", 0)),
+		B: utils.Must(utils.ReadIntQueryParam(r, "b", 0)),
+	}
+	utils.MustValidateStruct(compareRequest)
+
+	utils.MustIfError(checkOwnerOfWorkout(wh, w, user, compareRequest.A))
+	utils.MustIfError(checkOwnerOfWorkout(wh, w, user, compareRequest.B))
+
+	a := utils.Must(wh.Store.WorkoutStore.GetWorkoutById(compareRequest.A))
+	b := utils.Must(wh.Store.WorkoutStore.GetWorkoutById(compareRequest.B))
+	comparison := store.CompareWorkouts(a, b)
+	weightsFromKilograms(negotiateUnitSystem(w, r, user), comparisonWeights(comparison))
+
+	utils.MustWriteJSON(w, http.StatusOK, utils.Envelope{"comparison": comparison})
+}
+
+// ExportWorkouts streams the workouts of the user as a file for spreadsheets,
+// with one row per entry (or per set) repeating the workout columns.
+func (wh *WorkoutsHandlers) ExportWorkouts(w http.ResponseWriter, r *http.Request) {
+	user := middlewares.GetUser(r)
+	query := r.URL.Query()
+
+	exportRequest := &requests.ExportWorkoutsRequest{
+		Format: cmp.Or(query.Get("format"), export.FormatCSV),
+		Rows:   cmp.Or(query.Get("rows"), exportRowsEntries),
+	}
+	utils.MustValidateStruct(exportRequest)
+
+	from, to, err := utils.ReadTimeRangeQueryParams(r)
+	utils.MustIfError(err)
+
+	unitSystem := negotiateUnitSystem(w, r, user)
+	columns := workoutExportColumns
+
+	if exportRequest.Rows == exportRowsSets {
+		columns = workoutSetExportColumns
+	}
+
+	filename := fmt.Sprintf("workouts-%s.%s", time.Now().In(user.Location()).Format("20060102"), exportRequest.Format)
+	w.Header().Set("Content-Type", export.ContentType(exportRequest.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	writer := utils.Must(export.NewWriter(exportRequest.Format, w, columns))
+
+	err = wh.Store.WorkoutStore.ExportWorkouts(user.ID, from, to, func(workout *store.Workout) error {
+		weightsFromKilograms(unitSystem, entryWeights(workout.Entries))
+
+		for _, row := range workoutExportRows(workout, exportRequest.Rows, user.Location(), units.WeightUnit(unitSystem)) {
+			if err := writer.WriteRow(row); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err == nil {
+		err = writer.Close()
+	}
+
+	// the status was sent with the header row, the client gets a truncated file
+	if err != nil {
+		wh.Logger.Error("failed to export workouts", zap.Error(err))
+	}
+}
+
+const (
+	exportRowsEntries = "entries"
+	exportRowsSets    = "sets"
+)
+
+var workoutExportColumns = []string{
+	"workout_id", "workout_title", "workout_date", "duration_minutes", "calories_burned",
+	"entry_order", "exercise_id", "exercise_name", "group_type",
+	"sets", "reps", "weight", "weight_unit", "duration_seconds", "notes",
+}
+
+var workoutSetExportColumns = []string{
+	"workout_id", "workout_title", "workout_date", "duration_minutes", "calories_burned",
+	"entry_order", "exercise_id", "exercise_name", "group_type",
+	"set_number", "set_type", "reps", "weight", "weight_unit", "duration_seconds", "rpe", "rir", "completed",
+}
+
+// workoutExportRows flattens a workout into rows matching the export columns.
+// A workout without entries still gets a row, so its duration and calories add up.
+func workoutExportRows(workout *store.Workout, rows string, location *time.Location, weightUnit string) [][]any {
+	workoutColumns := []any{workout.ID, workout.Title, workout.CreatedAt.In(location), workout.DurationMinutes, workout.CaloriesBurned}
+	columnCount := len(workoutExportColumns)
+
+	if rows == exportRowsSets {
+		columnCount = len(workoutSetExportColumns)
+	}
+
+	if len(workout.Entries) == 0 {
+		return [][]any{append(workoutColumns, make([]any, columnCount-len(workoutColumns))...)}
+	}
+
+	result := make([][]any, 0, len(workout.Entries))
+
+	for _, entry := range workout.Entries {
+		var groupType *string
+
+		if entry.Group != nil {
+			groupType = &entry.Group.Type
+		}
+
+		entryColumns := append(slices.Clone(workoutColumns), entry.OrderIndex, entry.ExerciseID, entry.ExerciseName, groupType)
+
+		if rows != exportRowsSets {
+			result = append(result, append(entryColumns,
+				entry.Sets, entry.Reps, entry.Weight, weightUnit, entry.DurationSeconds, entry.Notes,
+			))
+			continue
+		}
+
+		for _, set := range entry.WorkoutSets {
+			result = append(result, append(slices.Clone(entryColumns),
+				set.SetNumber, set.SetType, set.Reps, set.Weight, weightUnit, set.DurationSeconds, set.RPE, set.RIR, set.Completed,
+			))
+		}
+	}
+
+	return result
+}
+
+// prepareEntries ties the entries to the authenticated user and makes sure any
+// referenced exercise is part of the catalog visible to them.
+func prepareEntries(wh *WorkoutsHandlers, user *store.User, entries []store.WorkoutEntry) error {
+	for i := range entries {
+		entries[i].UserID = user.ID
+
+		if entries[i].ExerciseID == nil {
+			continue
+		}
+
+		exercise, err := visibleExercise(wh.Store, user, *entries[i].ExerciseID)
+
+		if err != nil {
+			wh.Logger.Error("user cannot use this exercise")
+			return err
+		}
+
+		if entries[i].ExerciseName == "" {
+			entries[i].ExerciseName = exercise.Name
+		}
+	}
+
+	return nil
+}
+
 func checkOwnerOfWorkout(wh *WorkoutsHandlers, w http.ResponseWriter, user *store.User, workoutID int) error {
 	isWorkoutOwner := utils.Must(wh.Store.WorkoutStore.OwnsWorkout(workoutID, user.ID))
 
@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"partiuFit/internal/store"
+	"partiuFit/internal/units"
+	"time"
+)
+
+// Hevy has written its dates in a few layouts over time.
+var hevyDateLayouts = []string{"2 Jan 2006, 15:04", "2 Jan 2006 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+
+var hevySetTypes = map[string]string{
+	"normal":  store.SetTypeWorking,
+	"warmup":  store.SetTypeWarmUp,
+	"dropset": store.SetTypeDrop,
+	"failure": store.SetTypeFailure,
+}
+
+// hevyParser reads the columns title, start_time, end_time, description,
+// exercise_title, exercise_notes, set_type, weight_kg (or weight_lbs), reps,
+// duration_seconds and rpe.
+func hevyParser(columns map[string]int, location *time.Location) parser {
+	weightColumn, weightSystem := "weight_kg", units.Metric
+
+	if _, ok := columns["weight_lbs"]; ok {
+		weightColumn, weightSystem = "weight_lbs", units.Imperial
+	}
+
+	return func(value func(column string) string) (*row, error) {
+		startedAt, err := parseHevyDate(value("start_time"), location)
+
+		if err != nil {
+			return nil, err
+		}
+
+		durationMinutes := 0
+
+		if endTime := value("end_time"); endTime != "" {
+			finishedAt, err := parseHevyDate(endTime, location)
+
+			if err != nil {
+				return nil, err
+			}
+
+			durationMinutes = int(math.Round(finishedAt.Sub(startedAt).Minutes()))
+		}
+
+		exerciseName := value("exercise_title")
+
+		if exerciseName == "" {
+			return nil, fmt.Errorf("exercício não informado")
+		}
+
+		setType := store.SetTypeWorking
+
+		if rawType := value("set_type"); rawType != "" {
+			mapped, ok := hevySetTypes[rawType]
+
+			if !ok {
+				return nil, fmt.Errorf("tipo de série inválido: %q", rawType)
+			}
+
+			setType = mapped
+		}
+
+		set, weight, err := newSet(setType, value(weightColumn), value("reps"), value("duration_seconds"), value("rpe"))
+
+		if err != nil {
+			return nil, err
+		}
+
+		set.Weight = units.ToKilograms(weight, weightSystem)
+
+		return &row{
+			title:           value("title"),
+			date:            value("start_time"),
+			startedAt:       startedAt,
+			durationMinutes: max(durationMinutes, 0),
+			description:     value("description"),
+			exerciseName:    exerciseName,
+			notes:           value("exercise_notes"),
+			set:             set,
+		}, nil
+	}
+}
+
+func parseHevyDate(value string, location *time.Location) (time.Time, error) {
+	for _, layout := range hevyDateLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("data inválida: %q", value)
+}
@@ -0,0 +1,297 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/store"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SourceStrong = "strong"
+	SourceHevy   = "hevy"
+)
+
+// RowError points at a line of the uploaded file, the header being line 1.
+type RowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type Result struct {
+	Source   string
+	Rows     int
+	Workouts []store.Workout
+	Errors   []RowError
+}
+
+// row is a single set of an export, already normalized to kilograms. date is
+// the start time as written in the file.
+type row struct {
+	title           string
+	date            string
+	startedAt       time.Time
+	durationMinutes int
+	description     string
+	exerciseName    string
+	notes           string
+	set             store.WorkoutSet
+}
+
+// parser turns a record of a given app into a row. Records that carry no set
+// (such as Strong's rest timers) return nil without an error.
+type parser func(value func(column string) string) (*row, error)
+
+// Parse reads a Strong or Hevy CSV export, telling them apart by the header.
+// Neither app writes a time zone, so dates are read in location, and Strong
+// does not write the weight unit, so its weights are read in system. Invalid
+// rows are reported and left out of the workouts.
+func Parse(r io.Reader, location *time.Location, system string) (*Result, error) {
+	content, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	content = bytes.TrimPrefix(content, []byte("\ufeff"))
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+
+	// older Strong exports are separated by semicolons
+	firstLine, _, _ := bytes.Cut(content, []byte("\n"))
+
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", internalErrors.ErrInvalidImportFile, err)
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	result := &Result{Workouts: make([]store.Workout, 0), Errors: make([]RowError, 0)}
+	var parse parser
+
+	switch {
+	case hasColumns(columns, "workout name", "exercise name", "date"):
+		result.Source = SourceStrong
+		parse = strongParser(columns, location, system)
+	case hasColumns(columns, "title", "start_time", "exercise_title"):
+		result.Source = SourceHevy
+		parse = hevyParser(columns, location)
+	default:
+		return nil, fmt.Errorf("%w: o cabeçalho não é de uma exportação do Strong ou do Hevy", internalErrors.ErrInvalidImportFile)
+	}
+
+	builder := newWorkoutBuilder(result.Source)
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", internalErrors.ErrInvalidImportFile, err)
+		}
+
+		result.Rows++
+
+		value := func(column string) string {
+			index, ok := columns[column]
+
+			if !ok || index >= len(record) {
+				return ""
+			}
+
+			return strings.TrimSpace(record[index])
+		}
+
+		parsed, err := parse(value)
+
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Row: line, Message: err.Error()})
+			continue
+		}
+
+		if parsed != nil {
+			builder.add(parsed)
+		}
+	}
+
+	result.Workouts = builder.workouts
+
+	return result, nil
+}
+
+func hasColumns(columns map[string]int, names ...string) bool {
+	for _, name := range names {
+		if _, ok := columns[name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// workoutBuilder groups rows into workouts by start time and title, and the
+// consecutive sets of the same exercise into an entry. The import key is built
+// from the date as written in the file, so it does not change when the same
+// file is uploaded again after the user changes time zone.
+type workoutBuilder struct {
+	source   string
+	workouts []store.Workout
+	indexes  map[string]int
+}
+
+func newWorkoutBuilder(source string) *workoutBuilder {
+	return &workoutBuilder{source: source, workouts: make([]store.Workout, 0), indexes: make(map[string]int)}
+}
+
+func (b *workoutBuilder) add(r *row) {
+	key := fmt.Sprintf("%s:%s:%s", b.source, r.date, r.title)
+	index, ok := b.indexes[key]
+
+	if !ok {
+		startedAt := r.startedAt
+		workout := store.Workout{
+			Title:           r.title,
+			Description:     r.description,
+			DurationMinutes: r.durationMinutes,
+			Status:          store.WorkoutCompleted,
+			CreatedAt:       &startedAt,
+			StartedAt:       &startedAt,
+			ImportKey:       &key,
+			Entries:         make([]store.WorkoutEntry, 0),
+		}
+
+		if r.durationMinutes > 0 {
+			finishedAt := startedAt.Add(time.Duration(r.durationMinutes) * time.Minute)
+			workout.FinishedAt = &finishedAt
+		}
+
+		b.workouts = append(b.workouts, workout)
+		index = len(b.workouts) - 1
+		b.indexes[key] = index
+	}
+
+	workout := &b.workouts[index]
+	entries := workout.Entries
+
+	if len(entries) == 0 || entries[len(entries)-1].ExerciseName != r.exerciseName {
+		workout.Entries = append(workout.Entries, store.WorkoutEntry{
+			ExerciseName: r.exerciseName,
+			OrderIndex:   len(entries) + 1,
+			WorkoutSets:  make([]store.WorkoutSet, 0),
+		})
+	}
+
+	entry := &workout.Entries[len(workout.Entries)-1]
+	r.set.SetNumber = len(entry.WorkoutSets) + 1
+	entry.WorkoutSets = append(entry.WorkoutSets, r.set)
+
+	if entry.Notes == "" {
+		entry.Notes = r.notes
+	}
+}
+
+// ExerciseNameCandidates lists the names to look up in the catalog for a name
+// written by Strong or Hevy, which append the equipment in parentheses:
+// "Bench Press (Dumbbell)" is tried as itself, "Bench Press" and
+// "Dumbbell Bench Press".
+func ExerciseNameCandidates(name string) []string {
+	candidates := []string{name}
+	base, equipment, found := strings.Cut(name, " (")
+
+	if !found || !strings.HasSuffix(equipment, ")") {
+		return candidates
+	}
+
+	equipment = strings.TrimSuffix(equipment, ")")
+
+	return append(candidates, base, equipment+" "+base)
+}
+
+func parseOptionalFloat(value string, column string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+
+	if err != nil || parsed < 0 {
+		return nil, fmt.Errorf("valor inválido em %s: %q", column, value)
+	}
+
+	return &parsed, nil
+}
+
+// parseOptionalInt accepts "10" as well as "10.0", which both apps write.
+func parseOptionalInt(value string, column string) (*int, error) {
+	parsed, err := parseOptionalFloat(value, column)
+
+	if err != nil || parsed == nil {
+		return nil, err
+	}
+
+	rounded := int(*parsed)
+
+	return &rounded, nil
+}
+
+func newSet(setType string, weight, reps, seconds, rpe string) (store.WorkoutSet, float64, error) {
+	set := store.WorkoutSet{SetType: setType, Completed: true}
+
+	parsedWeight, err := parseOptionalFloat(weight, "peso")
+
+	if err != nil {
+		return set, 0, err
+	}
+
+	if set.Reps, err = parseOptionalInt(reps, "repetições"); err != nil {
+		return set, 0, err
+	}
+
+	if set.DurationSeconds, err = parseOptionalInt(seconds, "duração"); err != nil {
+		return set, 0, err
+	}
+
+	if set.RPE, err = parseOptionalFloat(rpe, "RPE"); err != nil {
+		return set, 0, err
+	}
+
+	if set.DurationSeconds != nil && *set.DurationSeconds == 0 {
+		set.DurationSeconds = nil
+	}
+
+	if set.Reps != nil && *set.Reps == 0 && set.DurationSeconds != nil {
+		set.Reps = nil
+	}
+
+	if set.Reps == nil && set.DurationSeconds == nil {
+		return set, 0, fmt.Errorf("série sem repetições nem duração")
+	}
+
+	if set.RPE != nil && (*set.RPE < 1 || *set.RPE > 10) {
+		return set, 0, fmt.Errorf("RPE deve estar entre 1 e 10")
+	}
+
+	if parsedWeight == nil {
+		return set, 0, nil
+	}
+
+	return set, *parsedWeight, nil
+}
@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"errors"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/store"
+	"partiuFit/internal/units"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var saoPaulo, _ = time.LoadLocation("America/Sao_Paulo")
+
+const strongExport = `Date,Workout Name,Duration,Exercise Name,Set Order,Weight,Reps,Distance,Seconds,Notes,Workout Notes,RPE
+2024-03-04 18:30:00,Push,1h 5m,Bench Press (Barbell),W,40.0,12.0,0,0,,Felt strong,
+2024-03-04 18:30:00,Push,1h 5m,Bench Press (Barbell),1,80.0,8.0,0,0,Paused reps,Felt strong,8.5
+2024-03-04 18:30:00,Push,1h 5m,Bench Press (Barbell),Rest Timer,0,0,0,90,,Felt strong,
+2024-03-04 18:30:00,Push,1h 5m,Plank,1,0,0,0,60,,Felt strong,
+2024-03-04 18:30:00,Push,1h 5m,Bench Press (Barbell),2,80.0,abc,0,0,,Felt strong,
+2024-03-06 07:00:00,Legs,45m,Squat (Barbell),1,100.0,5.0,0,0,,,
+not a date,Legs,45m,Squat (Barbell),2,100.0,5.0,0,0,,,
+`
+
+const hevyExport = `"title","start_time","end_time","description","exercise_title","superset_id","exercise_notes","set_index","set_type","weight_lbs","reps","distance_miles","duration_seconds","rpe"
+"Upper","4 Mar 2024, 18:30","4 Mar 2024, 19:20","","Bench Press (Dumbbell)",,"",0,"warmup",45,12,,,
+"Upper","4 Mar 2024, 18:30","4 Mar 2024, 19:20","","Bench Press (Dumbbell)",,"",1,"normal",135,8,,,9
+"Upper","4 Mar 2024, 18:30","4 Mar 2024, 19:20","","Pull Up",,"",0,"jumbo",0,10,,,
+`
+
+func TestParseStrong(t *testing.T) {
+	result, err := Parse(strings.NewReader(strongExport), saoPaulo, units.Metric)
+	assert.NoError(t, err)
+
+	assert.Equal(t, SourceStrong, result.Source)
+	assert.Equal(t, 7, result.Rows)
+	assert.Equal(t, []RowError{
+		{Row: 6, Message: `valor inválido em repetições: "abc"`},
+		{Row: 8, Message: `data inválida: "not a date"`},
+	}, result.Errors)
+	assert.Len(t, result.Workouts, 2)
+
+	push := result.Workouts[0]
+	assert.Equal(t, "Push", push.Title)
+	assert.Equal(t, "Felt strong", push.Description)
+	assert.Equal(t, 65, push.DurationMinutes)
+	assert.Equal(t, time.Date(2024, 3, 4, 21, 30, 0, 0, time.UTC), push.CreatedAt.UTC())
+	assert.Equal(t, "strong:2024-03-04 18:30:00:Push", *push.ImportKey)
+
+	assert.Len(t, push.Entries, 2)
+	assert.Equal(t, "Bench Press (Barbell)", push.Entries[0].ExerciseName)
+	assert.Equal(t, "Paused reps", push.Entries[0].Notes)
+	assert.Len(t, push.Entries[0].WorkoutSets, 2)
+	assert.Equal(t, store.SetTypeWarmUp, push.Entries[0].WorkoutSets[0].SetType)
+	assert.Equal(t, 8.5, *push.Entries[0].WorkoutSets[1].RPE)
+	assert.Equal(t, 60, *push.Entries[1].WorkoutSets[0].DurationSeconds)
+	assert.Nil(t, push.Entries[1].WorkoutSets[0].Reps)
+}
+
+func TestParseKeepsKeysAcrossTimeZones(t *testing.T) {
+	inSaoPaulo, err := Parse(strings.NewReader(strongExport), saoPaulo, units.Metric)
+	assert.NoError(t, err)
+
+	inUTC, err := Parse(strings.NewReader(strongExport), time.UTC, units.Metric)
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, inSaoPaulo.Workouts[0].CreatedAt.UTC(), inUTC.Workouts[0].CreatedAt.UTC())
+	assert.Equal(t, *inSaoPaulo.Workouts[0].ImportKey, *inUTC.Workouts[0].ImportKey)
+}
+
+func TestParseStrongInPounds(t *testing.T) {
+	result, err := Parse(strings.NewReader(strongExport), time.UTC, units.Imperial)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 36.287, result.Workouts[0].Entries[0].WorkoutSets[1].Weight)
+}
+
+func TestParseHevy(t *testing.T) {
+	result, err := Parse(strings.NewReader(hevyExport), time.UTC, units.Metric)
+	assert.NoError(t, err)
+
+	assert.Equal(t, SourceHevy, result.Source)
+	assert.Equal(t, []RowError{{Row: 4, Message: `tipo de série inválido: "jumbo"`}}, result.Errors)
+	assert.Len(t, result.Workouts, 1)
+
+	upper := result.Workouts[0]
+	assert.Equal(t, 50, upper.DurationMinutes)
+	assert.Len(t, upper.Entries, 1)
+	assert.Equal(t, 61.235, upper.Entries[0].WorkoutSets[1].Weight, "weight_lbs is converted to kilograms")
+	assert.Equal(t, 2, upper.Entries[0].WorkoutSets[1].SetNumber)
+}
+
+func TestParseUnknownFile(t *testing.T) {
+	_, err := Parse(strings.NewReader("a,b,c\n1,2,3\n"), time.UTC, units.Metric)
+	assert.True(t, errors.Is(err, internalErrors.ErrInvalidImportFile))
+}
+
+func TestExerciseNameCandidates(t *testing.T) {
+	assert.Equal(t, []string{"Bench Press (Dumbbell)", "Bench Press", "Dumbbell Bench Press"}, ExerciseNameCandidates("Bench Press (Dumbbell)"))
+	assert.Equal(t, []string{"Plank"}, ExerciseNameCandidates("Plank"))
+}
+
+func TestParseStrongDuration(t *testing.T) {
+	assert.Equal(t, 65, parseStrongDuration("1h 5m"))
+	assert.Equal(t, 45, parseStrongDuration("45m"))
+	assert.Equal(t, 1, parseStrongDuration("50s"))
+	assert.Equal(t, 30, parseStrongDuration("1800"))
+	assert.Zero(t, parseStrongDuration(""))
+}
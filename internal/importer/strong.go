@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"fmt"
+	"partiuFit/internal/store"
+	"partiuFit/internal/units"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const strongDateLayout = "2006-01-02 15:04:05"
+
+// strongSetTypes maps the letters Strong writes in "Set Order" instead of a
+// number for special sets.
+var strongSetTypes = map[string]string{
+	"W": store.SetTypeWarmUp,
+	"D": store.SetTypeDrop,
+	"F": store.SetTypeFailure,
+}
+
+var strongDurationPart = regexp.MustCompile(`(\d+)\s*([hms])`)
+
+// strongParser reads the columns Date, Workout Name, Duration, Exercise Name,
+// Set Order, Weight, Reps, Seconds, Notes, Workout Notes and RPE. Exports that
+// have a "Weight Unit" column override system.
+func strongParser(columns map[string]int, location *time.Location, system string) parser {
+	return func(value func(column string) string) (*row, error) {
+		setOrder := value("set order")
+
+		if strings.EqualFold(setOrder, "rest timer") {
+			return nil, nil
+		}
+
+		startedAt, err := time.ParseInLocation(strongDateLayout, value("date"), location)
+
+		if err != nil {
+			return nil, fmt.Errorf("data inválida: %q", value("date"))
+		}
+
+		exerciseName := value("exercise name")
+
+		if exerciseName == "" {
+			return nil, fmt.Errorf("exercício não informado")
+		}
+
+		setType := store.SetTypeWorking
+
+		if special, ok := strongSetTypes[strings.ToUpper(setOrder)]; ok {
+			setType = special
+		} else if _, err := strconv.Atoi(setOrder); setOrder != "" && err != nil {
+			return nil, fmt.Errorf("ordem da série inválida: %q", setOrder)
+		}
+
+		set, weight, err := newSet(setType, value("weight"), value("reps"), value("seconds"), value("rpe"))
+
+		if err != nil {
+			return nil, err
+		}
+
+		weightSystem := system
+
+		if _, ok := columns["weight unit"]; ok {
+			weightSystem = units.Metric
+
+			if strings.HasPrefix(strings.ToLower(value("weight unit")), "lb") {
+				weightSystem = units.Imperial
+			}
+		}
+
+		set.Weight = units.ToKilograms(weight, weightSystem)
+
+		return &row{
+			title:           value("workout name"),
+			date:            value("date"),
+			startedAt:       startedAt,
+			durationMinutes: parseStrongDuration(value("duration")),
+			description:     value("workout notes"),
+			exerciseName:    exerciseName,
+			notes:           value("notes"),
+			set:             set,
+		}, nil
+	}
+}
+
+// parseStrongDuration reads durations such as "1h 5m", "45m" or "30s", rounded
+// to minutes. Older exports write plain seconds.
+func parseStrongDuration(duration string) int {
+	if seconds, err := strconv.Atoi(duration); err == nil {
+		return (seconds + 30) / 60
+	}
+
+	seconds := 0
+
+	for _, part := range strongDurationPart.FindAllStringSubmatch(duration, -1) {
+		amount, _ := strconv.Atoi(part[1])
+
+		switch part[2] {
+		case "h":
+			seconds += amount * 3600
+		case "m":
+			seconds += amount * 60
+		default:
+			seconds += amount
+		}
+	}
+
+	return (seconds + 30) / 60
+}
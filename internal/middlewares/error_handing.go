@@ -33,7 +33,7 @@ func (em *ErrorHandlerMiddleware) Handle(next http.Handler) http.Handler {
 				}
 
 				err := rvr.(error)
-				validationErrors := &validator.ValidationErrors{}
+				var validationErrors validator.ValidationErrors
 
 				logEntry := middleware.GetLogEntry(r)
 				if logEntry != nil {
@@ -72,10 +72,30 @@ func (em *ErrorHandlerMiddleware) Handle(next http.Handler) http.Handler {
 					return
 				}
 
+				if errors.Is(err, internalErrors.ErrInvalidQueryParam) || errors.Is(err, internalErrors.ErrInvalidCursor) {
+					em.Logger.Error(err)
+					utils.MustWriteJSON(w, http.StatusBadRequest, utils.Envelope{"error": err.Error()})
+					return
+				}
+
+				if errors.Is(err, internalErrors.ErrActiveSessionExists) || errors.Is(err, internalErrors.ErrInvalidSessionTransition) ||
+					errors.Is(err, internalErrors.ErrTrackAlreadyImported) || errors.Is(err, internalErrors.ErrWeightsAlreadyConverted) {
+					em.Logger.Error(err)
+					utils.MustWriteJSON(w, http.StatusConflict, utils.Envelope{"error": err.Error()})
+					return
+				}
+
+				if errors.Is(err, internalErrors.ErrInvalidEntryGroup) || errors.Is(err, internalErrors.ErrInvalidEntryOrder) ||
+					errors.Is(err, internalErrors.ErrInvalidImportFile) || errors.Is(err, internalErrors.ErrCannotFollowSelf) {
+					em.Logger.Error(err)
+					utils.MustWriteJSON(w, http.StatusBadRequest, utils.Envelope{"error": err.Error()})
+					return
+				}
+
 				if errors.As(err, &validationErrors) {
 					validationMap := make(map[string][]string)
 
-					for _, validationError := range *validationErrors {
+					for _, validationError := range validationErrors {
 						fieldName := validationError.Field()
 						validationMap[fieldName] = append(validationMap[fieldName], validationError.Translate(utils.Trans))
 					}
@@ -0,0 +1,22 @@
+package requests
+
+// BodyMeasurementRequest is validated after its weight is converted to
+// kilograms, so the limit holds in every unit system.
+type BodyMeasurementRequest struct {
+	MeasuredAt        string   `json:"measured_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Weight            *float64 `json:"weight" validate:"required_without_all=BodyFatPercentage Waist Chest Hips Neck Arm Forearm Thigh Calf,omitempty,gt=0,lte=500"`
+	BodyFatPercentage *float64 `json:"body_fat_percentage" validate:"omitempty,gt=0,lt=100"`
+	Waist             *float64 `json:"waist" validate:"omitempty,gt=0,lte=300"`
+	Chest             *float64 `json:"chest" validate:"omitempty,gt=0,lte=300"`
+	Hips              *float64 `json:"hips" validate:"omitempty,gt=0,lte=300"`
+	Neck              *float64 `json:"neck" validate:"omitempty,gt=0,lte=300"`
+	Arm               *float64 `json:"arm" validate:"omitempty,gt=0,lte=300"`
+	Forearm           *float64 `json:"forearm" validate:"omitempty,gt=0,lte=300"`
+	Thigh             *float64 `json:"thigh" validate:"omitempty,gt=0,lte=300"`
+	Calf              *float64 `json:"calf" validate:"omitempty,gt=0,lte=300"`
+	Notes             string   `json:"notes"`
+}
+
+type MeasurementSeriesRequest struct {
+	Metric string `json:"metric" validate:"required,oneof=weight body_fat_percentage waist chest hips neck arm forearm thigh calf"`
+}
@@ -0,0 +1,17 @@
+package requests
+
+type ExerciseRequest struct {
+	Name                  string   `json:"name" validate:"required,max=255"`
+	Aliases               []string `json:"aliases" validate:"max=20,dive,required,max=255"`
+	PrimaryMuscleGroups   []string `json:"primary_muscle_groups" validate:"required,min=1,dive,oneof=chest back shoulders biceps triceps forearms abs lower_back quadriceps hamstrings glutes calves full_body cardio"`
+	SecondaryMuscleGroups []string `json:"secondary_muscle_groups" validate:"dive,oneof=chest back shoulders biceps triceps forearms abs lower_back quadriceps hamstrings glutes calves full_body cardio"`
+	Equipment             string   `json:"equipment" validate:"required,oneof=barbell dumbbell machine cable kettlebell band bodyweight none other"`
+	MovementType          string   `json:"movement_type" validate:"required,oneof=compound isolation cardio mobility"`
+}
+
+type SearchExercisesRequest struct {
+	Search       string `json:"q" validate:"max=255"`
+	MuscleGroup  string `json:"muscle_group" validate:"omitempty,oneof=chest back shoulders biceps triceps forearms abs lower_back quadriceps hamstrings glutes calves full_body cardio"`
+	Equipment    string `json:"equipment" validate:"omitempty,oneof=barbell dumbbell machine cable kettlebell band bodyweight none other"`
+	MovementType string `json:"movement_type" validate:"omitempty,oneof=compound isolation cardio mobility"`
+}
@@ -0,0 +1,6 @@
+package requests
+
+type FeedRequest struct {
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+	Cursor string `json:"cursor"`
+}
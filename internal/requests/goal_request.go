@@ -0,0 +1,10 @@
+package requests
+
+type GoalRequest struct {
+	Title      string  `json:"title" validate:"required,max=255"`
+	GoalType   string  `json:"goal_type" validate:"required,oneof=workouts calories_burned duration_minutes lift_weight"`
+	Target     float64 `json:"target" validate:"required,gt=0"`
+	Period     string  `json:"period" validate:"required_unless=GoalType lift_weight,excluded_if=GoalType lift_weight,omitempty,oneof=week month"`
+	ExerciseID *int    `json:"exercise_id" validate:"required_if=GoalType lift_weight,excluded_unless=GoalType lift_weight"`
+	Deadline   string  `json:"deadline" validate:"omitempty,datetime=2006-01-02"`
+}
@@ -0,0 +1,13 @@
+package requests
+
+type ScheduledWorkoutRequest struct {
+	TemplateID *int   `json:"template_id"`
+	Title      string `json:"title" validate:"required,max=255"`
+	Notes      string `json:"notes"`
+	StartsOn   string `json:"starts_on" validate:"required,datetime=2006-01-02"`
+	RRule      string `json:"rrule" validate:"omitempty,rrule"`
+}
+
+type CompleteOccurrenceRequest struct {
+	WorkoutID *int `json:"workout_id"`
+}
@@ -0,0 +1,6 @@
+package requests
+
+type SearchRequest struct {
+	Query string `json:"q" validate:"required,max=255"`
+	Limit int    `json:"limit" validate:"min=1,max=50"`
+}
@@ -0,0 +1,7 @@
+package requests
+
+import "time"
+
+type CreateShareRequest struct {
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty,gt"`
+}
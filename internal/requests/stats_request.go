@@ -0,0 +1,10 @@
+package requests
+
+type ExerciseProgressRequest struct {
+	Bucket  string `json:"bucket" validate:"oneof=day week month"`
+	Formula string `json:"formula" validate:"oneof=epley brzycki"`
+}
+
+type SummaryRequest struct {
+	Period string `json:"period" validate:"oneof=week month"`
+}
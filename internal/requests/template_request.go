@@ -0,0 +1,19 @@
+package requests
+
+type WorkoutTemplateRequest struct {
+	Title                    string                        `json:"title" validate:"required,max=255"`
+	Description              string                        `json:"description"`
+	EstimatedDurationMinutes *int                          `json:"estimated_duration_minutes" validate:"omitempty,min=1"`
+	Entries                  []WorkoutTemplateEntryRequest `json:"entries" validate:"required,min=1,dive"`
+}
+
+type WorkoutTemplateEntryRequest struct {
+	ExerciseID            *int    `json:"exercise_id"`
+	ExerciseName          string  `json:"exercise_name" validate:"required_without=ExerciseID,max=255"`
+	TargetSets            int     `json:"target_sets" validate:"required,min=1"`
+	TargetReps            *int    `json:"target_reps" validate:"required_without=TargetDurationSeconds,excluded_with=TargetDurationSeconds,omitempty,min=1"`
+	TargetDurationSeconds *int    `json:"target_duration_seconds" validate:"omitempty,min=1"`
+	TargetWeight          float64 `json:"target_weight" validate:"min=0"`
+	RestSeconds           *int    `json:"rest_seconds" validate:"omitempty,min=0"`
+	Notes                 string  `json:"notes"`
+}
@@ -1,8 +1,23 @@
 package requests
 
 type UserRequest struct {
-	Name     string `json:"name" validate:"required"`
-	Username string `json:"username" validate:"required"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Name              string `json:"name" validate:"required"`
+	Username          string `json:"username" validate:"required"`
+	Email             string `json:"email" validate:"required,email"`
+	Password          string `json:"password" validate:"required"`
+	TimeZone          string `json:"time_zone" validate:"omitempty,timezone"`
+	UnitSystem        string `json:"unit_system" validate:"omitempty,oneof=metric imperial"`
+	DefaultVisibility string `json:"default_visibility" validate:"omitempty,oneof=public followers private"`
+}
+
+// UnitsRequest is the unit system a single request overrides the user's preference with.
+type UnitsRequest struct {
+	UnitSystem string `json:"units" validate:"omitempty,oneof=metric imperial"`
+}
+
+// ConvertWeightsRequest reinterprets the weights logged before Before as
+// having been entered in UnitSystem, for history recorded before units existed.
+type ConvertWeightsRequest struct {
+	UnitSystem string `json:"unit_system" validate:"required,oneof=imperial"`
+	Before     string `json:"before" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
 }
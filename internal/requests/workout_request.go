@@ -0,0 +1,31 @@
+package requests
+
+type ListWorkoutsRequest struct {
+	Limit    int      `json:"limit" validate:"min=1,max=100"`
+	Cursor   string   `json:"cursor"`
+	Search   string   `json:"q" validate:"max=255"`
+	Sort     string   `json:"sort" validate:"omitempty,oneof=created_at title duration_minutes calories_burned"`
+	Order    string   `json:"order" validate:"omitempty,oneof=asc desc"`
+	Tags     []string `json:"tags" validate:"max=20,dive,max=50"`
+	TagMatch string   `json:"tag_match" validate:"omitempty,oneof=any all"`
+}
+
+type ExportWorkoutsRequest struct {
+	Format string `json:"format" validate:"required,oneof=csv jsonl xlsx"`
+	Rows   string `json:"rows" validate:"required,oneof=entries sets"`
+}
+
+type ImportTrackRequest struct {
+	Sport string `json:"sport" validate:"omitempty,oneof=running cycling walking other"`
+}
+
+type DuplicateWorkoutRequest struct {
+	Date      string  `json:"date" validate:"omitempty,datetime=2006-01-02"`
+	Title     *string `json:"title" validate:"omitempty,min=1,max=255"`
+	Completed bool    `json:"completed"`
+}
+
+type CompareWorkoutsRequest struct {
+	A int `json:"a" validate:"required,min=1"`
+	B int `json:"b" validate:"required,min=1,nefield=A"`
+}
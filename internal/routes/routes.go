@@ -2,6 +2,7 @@ package routes
 
 import (
 	"partiuFit/internal/app"
+	"partiuFit/internal/handlers"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -18,9 +19,9 @@ func RegisterRoutes(app *app.Application) *chi.Mux {
 	// Security middlewares
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:3000", "http://localhost:3001", "http://localhost:8080"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", handlers.UnitSystemHeader},
+		ExposedHeaders:   []string{"Link", handlers.UnitSystemHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -39,6 +40,7 @@ func RegisterRoutes(app *app.Application) *chi.Mux {
 	r.Use(app.Middlewares.ErrorHandlerMiddleware.Handle)
 
 	r.Get("/health", app.Handlers.HeathCheck)
+	r.Get("/shared/{token}", app.Handlers.WorkoutShareHandlers.GetSharedWorkout)
 
 	r.Group(func(r chi.Router) {
 		r.Use(app.Middlewares.UserMiddleware.Authenticate)
@@ -47,15 +49,116 @@ func RegisterRoutes(app *app.Application) *chi.Mux {
 		r.Route("/workouts", func(r chi.Router) {
 			r.Get("/", app.Handlers.WorkoutHandlers.GetWorkouts)
 			r.Post("/", app.Handlers.WorkoutHandlers.CreateWorkout)
+			r.Get("/export", app.Handlers.WorkoutHandlers.ExportWorkouts)
+			r.Get("/trash", app.Handlers.WorkoutHandlers.GetTrash)
+			r.Get("/compare", app.Handlers.WorkoutHandlers.CompareWorkouts)
 			r.Get("/{id}", app.Handlers.WorkoutHandlers.GetWorkoutByID)
 			r.Put("/{id}", app.Handlers.WorkoutHandlers.UpdateWorkout)
 			r.Delete("/{id}", app.Handlers.WorkoutHandlers.DeleteWorkout)
+			r.Post("/{id}/restore", app.Handlers.WorkoutHandlers.RestoreWorkout)
+			r.Post("/{id}/duplicate", app.Handlers.WorkoutHandlers.DuplicateWorkout)
+			r.Get("/{id}/session", app.Handlers.WorkoutSessionHandlers.GetSession)
+			r.Post("/{id}/start", app.Handlers.WorkoutSessionHandlers.StartSession)
+			r.Post("/{id}/pause", app.Handlers.WorkoutSessionHandlers.PauseSession)
+			r.Post("/{id}/resume", app.Handlers.WorkoutSessionHandlers.ResumeSession)
+			r.Post("/{id}/finish", app.Handlers.WorkoutSessionHandlers.FinishSession)
+			r.Post("/{id}/sets", app.Handlers.WorkoutSessionHandlers.LogSet)
+			r.Get("/{id}/track", app.Handlers.TrackHandlers.GetTrack)
+			r.Get("/{id}/entries", app.Handlers.WorkoutHandlers.GetEntries)
+			r.Post("/{id}/entries", app.Handlers.WorkoutHandlers.CreateEntry)
+			r.Put("/{id}/entries/order", app.Handlers.WorkoutHandlers.ReorderEntries)
+			r.Get("/{id}/entries/{entryID}", app.Handlers.WorkoutHandlers.GetEntry)
+			r.Patch("/{id}/entries/{entryID}", app.Handlers.WorkoutHandlers.PatchEntry)
+			r.Delete("/{id}/entries/{entryID}", app.Handlers.WorkoutHandlers.DeleteEntry)
+			r.Get("/{id}/revisions", app.Handlers.WorkoutRevisionHandlers.GetRevisions)
+			r.Get("/{id}/revisions/{rev}", app.Handlers.WorkoutRevisionHandlers.GetRevision)
+			r.Post("/{id}/revisions/{rev}/revert", app.Handlers.WorkoutRevisionHandlers.RevertWorkout)
+			r.Post("/{id}/shares", app.Handlers.WorkoutShareHandlers.CreateShare)
+		})
+
+		r.Route("/exercises", func(r chi.Router) {
+			r.Get("/", app.Handlers.ExerciseHandlers.SearchExercises)
+			r.Post("/", app.Handlers.ExerciseHandlers.CreateExercise)
+			r.Get("/{id}", app.Handlers.ExerciseHandlers.GetExerciseByID)
+			r.Put("/{id}", app.Handlers.ExerciseHandlers.UpdateExercise)
+			r.Delete("/{id}", app.Handlers.ExerciseHandlers.DeleteExercise)
+		})
+
+		r.Route("/templates", func(r chi.Router) {
+			r.Get("/", app.Handlers.TemplateHandlers.GetTemplates)
+			r.Post("/", app.Handlers.TemplateHandlers.CreateTemplate)
+			r.Get("/{id}", app.Handlers.TemplateHandlers.GetTemplateByID)
+			r.Put("/{id}", app.Handlers.TemplateHandlers.UpdateTemplate)
+			r.Delete("/{id}", app.Handlers.TemplateHandlers.DeleteTemplate)
+			r.Post("/{id}/start", app.Handlers.TemplateHandlers.StartWorkout)
+		})
+
+		r.Route("/schedules", func(r chi.Router) {
+			r.Get("/", app.Handlers.ScheduleHandlers.GetSchedules)
+			r.Post("/", app.Handlers.ScheduleHandlers.CreateSchedule)
+			r.Get("/occurrences", app.Handlers.ScheduleHandlers.GetOccurrences)
+			r.Get("/{id}", app.Handlers.ScheduleHandlers.GetScheduleByID)
+			r.Put("/{id}", app.Handlers.ScheduleHandlers.UpdateSchedule)
+			r.Delete("/{id}", app.Handlers.ScheduleHandlers.DeleteSchedule)
+			r.Post("/{id}/occurrences/{date}/complete", app.Handlers.ScheduleHandlers.CompleteOccurrence)
+		})
+
+		r.Post("/imports", app.Handlers.ImportHandlers.ImportWorkouts)
+		r.Post("/imports/tracks", app.Handlers.TrackHandlers.ImportTrack)
+
+		r.Get("/personal-records", app.Handlers.PersonalRecordHandlers.GetPersonalRecords)
+		r.Get("/tags", app.Handlers.TagHandlers.GetTags)
+		r.Get("/search", app.Handlers.SearchHandlers.Search)
+		r.Get("/feed", app.Handlers.FollowHandlers.GetFeed)
+		r.Route("/follow-requests", func(r chi.Router) {
+			r.Get("/", app.Handlers.FollowHandlers.GetFollowRequests)
+			r.Post("/{id}/approve", app.Handlers.FollowHandlers.ApproveFollowRequest)
+			r.Delete("/{id}", app.Handlers.FollowHandlers.RejectFollowRequest)
+		})
+		r.Route("/shares", func(r chi.Router) {
+			r.Get("/", app.Handlers.WorkoutShareHandlers.GetShares)
+			r.Delete("/{id}", app.Handlers.WorkoutShareHandlers.RevokeShare)
+		})
+		r.Route("/goals", func(r chi.Router) {
+			r.Get("/", app.Handlers.GoalHandlers.GetGoals)
+			r.Post("/", app.Handlers.GoalHandlers.CreateGoal)
+			r.Get("/{id}", app.Handlers.GoalHandlers.GetGoalByID)
+			r.Put("/{id}", app.Handlers.GoalHandlers.UpdateGoal)
+			r.Delete("/{id}", app.Handlers.GoalHandlers.DeleteGoal)
+		})
+
+		r.Route("/measurements", func(r chi.Router) {
+			r.Get("/", app.Handlers.BodyMeasurementHandlers.GetMeasurements)
+			r.Post("/", app.Handlers.BodyMeasurementHandlers.CreateMeasurement)
+			r.Get("/latest", app.Handlers.BodyMeasurementHandlers.GetLatestMeasurements)
+			r.Get("/series", app.Handlers.BodyMeasurementHandlers.GetMeasurementSeries)
+			r.Get("/{id}", app.Handlers.BodyMeasurementHandlers.GetMeasurementByID)
+			r.Put("/{id}", app.Handlers.BodyMeasurementHandlers.UpdateMeasurement)
+			r.Delete("/{id}", app.Handlers.BodyMeasurementHandlers.DeleteMeasurement)
+		})
+
+		r.Route("/stats", func(r chi.Router) {
+			r.Get("/summary", app.Handlers.StatsHandlers.GetSummary)
+			r.Get("/exercises/{exercise}/progress", app.Handlers.StatsHandlers.GetExerciseProgress)
 		})
 	})
 
 	r.Route("/users", func(r chi.Router) {
 		r.Post("/", app.Handlers.UserHandlers.RegisterUser)
 		r.Put("/", app.Handlers.UserHandlers.UpdateUser)
+		r.With(app.Middlewares.UserMiddleware.Authenticate, app.Middlewares.UserMiddleware.RequireUser).
+			Post("/weights/convert", app.Handlers.UserHandlers.ConvertWeightHistory)
+
+		r.Group(func(r chi.Router) {
+			r.Use(app.Middlewares.UserMiddleware.Authenticate)
+			r.Use(app.Middlewares.UserMiddleware.RequireUser)
+
+			r.Post("/{id}/follow", app.Handlers.FollowHandlers.Follow)
+			r.Delete("/{id}/follow", app.Handlers.FollowHandlers.Unfollow)
+			r.Get("/{id}/followers", app.Handlers.FollowHandlers.GetFollowers)
+			r.Get("/{id}/following", app.Handlers.FollowHandlers.GetFollowing)
+			r.Get("/{id}/workouts", app.Handlers.FollowHandlers.GetUserWorkouts)
+		})
 	})
 
 	r.Route("/tokens", func(r chi.Router) {
@@ -0,0 +1,381 @@
+// Package rrule implements the subset of RFC 5545 recurrence rules used by
+// the training calendar: FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL,
+// COUNT, UNTIL, BYDAY (with ordinals for MONTHLY), BYMONTHDAY and WKST.
+// Occurrences are whole dates, times of day are not part of the schedule.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// MaxOccurrences bounds every expansion so a bad rule cannot exhaust memory.
+const MaxOccurrences = 1000
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// WeekdayNum is a BYDAY element. N is the optional ordinal, e.g. -1 in -1FR.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	WeekStart  time.Weekday
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRule, fmt.Sprintf(format, args...))
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;BYDAY=MO,WE,FR". A leading "RRULE:" is accepted.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+
+	if value == "" {
+		return nil, invalid("empty rule")
+	}
+
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+
+	for _, part := range strings.Split(value, ";") {
+		key, partValue, found := strings.Cut(part, "=")
+
+		if !found || partValue == "" {
+			return nil, invalid("malformed part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(partValue))
+
+			if !slices.Contains([]Frequency{Daily, Weekly, Monthly, Yearly}, rule.Freq) {
+				return nil, invalid("unsupported FREQ %q", partValue)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(partValue)
+
+			if err != nil || interval < 1 {
+				return nil, invalid("INTERVAL must be a positive integer")
+			}
+
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(partValue)
+
+			if err != nil || count < 1 {
+				return nil, invalid("COUNT must be a positive integer")
+			}
+
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseUntil(partValue)
+
+			if err != nil {
+				return nil, err
+			}
+
+			rule.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(partValue, ",") {
+				weekdayNum, err := parseWeekdayNum(day)
+
+				if err != nil {
+					return nil, err
+				}
+
+				rule.ByDay = append(rule.ByDay, weekdayNum)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(partValue, ",") {
+				monthDay, err := strconv.Atoi(day)
+
+				if err != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return nil, invalid("BYMONTHDAY must be between -31 and 31")
+				}
+
+				rule.ByMonthDay = append(rule.ByMonthDay, monthDay)
+			}
+		case "WKST":
+			weekStart, ok := weekdays[strings.ToUpper(partValue)]
+
+			if !ok {
+				return nil, invalid("invalid WKST %q", partValue)
+			}
+
+			rule.WeekStart = weekStart
+		default:
+			return nil, invalid("unsupported part %q", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, invalid("FREQ is required")
+	}
+
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, invalid("COUNT and UNTIL cannot be combined")
+	}
+
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly {
+			return nil, invalid("BYDAY ordinals are only supported with FREQ=MONTHLY")
+		}
+	}
+
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			return truncate(until), nil
+		}
+	}
+
+	return time.Time{}, invalid("invalid UNTIL %q", value)
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	if len(value) < 2 {
+		return WeekdayNum{}, invalid("invalid BYDAY %q", value)
+	}
+
+	weekday, ok := weekdays[value[len(value)-2:]]
+
+	if !ok {
+		return WeekdayNum{}, invalid("invalid BYDAY %q", value)
+	}
+
+	weekdayNum := WeekdayNum{Weekday: weekday}
+
+	if ordinal := value[:len(value)-2]; ordinal != "" {
+		n, err := strconv.Atoi(ordinal)
+
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, invalid("invalid BYDAY ordinal %q", value)
+		}
+
+		weekdayNum.N = n
+	}
+
+	return weekdayNum, nil
+}
+
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+
+		for _, day := range r.ByDay {
+			code := strings.ToUpper(day.Weekday.String()[:2])
+
+			if day.N != 0 {
+				code = strconv.Itoa(day.N) + code
+			}
+
+			days = append(days, code)
+		}
+
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+strings.ToUpper(r.WeekStart.String()[:2]))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Between returns the occurrences of the rule starting at dtstart that fall
+// within [from, to], both inclusive. COUNT is always counted from dtstart.
+func (r *Rule) Between(dtstart, from, to time.Time) []time.Time {
+	dtstart, from, to = truncate(dtstart), truncate(from), truncate(to)
+	occurrences := make([]time.Time, 0)
+	seen := 0
+
+	if r.Until != nil && r.Until.Before(to) {
+		to = *r.Until
+	}
+
+	for day := dtstart; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !r.matches(dtstart, day) {
+			continue
+		}
+
+		seen++
+
+		if r.Count > 0 && seen > r.Count {
+			break
+		}
+
+		if !day.Before(from) {
+			occurrences = append(occurrences, day)
+
+			if len(occurrences) >= MaxOccurrences {
+				break
+			}
+		}
+	}
+
+	return occurrences
+}
+
+func (r *Rule) matches(dtstart, day time.Time) bool {
+	switch r.Freq {
+	case Daily:
+		if daysBetween(dtstart, day)%r.Interval != 0 {
+			return false
+		}
+
+		return r.matchesByDay(day) && r.matchesByMonthDay(day)
+	case Weekly:
+		weeks := daysBetween(r.startOfWeek(dtstart), r.startOfWeek(day)) / 7
+
+		if weeks%r.Interval != 0 {
+			return false
+		}
+
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == dtstart.Weekday()
+		}
+
+		return r.matchesByDay(day)
+	case Monthly:
+		months := (day.Year()-dtstart.Year())*12 + int(day.Month()-dtstart.Month())
+
+		if months%r.Interval != 0 {
+			return false
+		}
+
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			return day.Day() == dtstart.Day()
+		}
+
+		return r.matchesByDay(day) && r.matchesByMonthDay(day)
+	case Yearly:
+		if (day.Year()-dtstart.Year())%r.Interval != 0 {
+			return false
+		}
+
+		return day.Month() == dtstart.Month() && day.Day() == dtstart.Day()
+	}
+
+	return false
+}
+
+func (r *Rule) matchesByDay(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+
+	for _, weekdayNum := range r.ByDay {
+		if weekdayNum.Weekday != day.Weekday() {
+			continue
+		}
+
+		if weekdayNum.N == 0 {
+			return true
+		}
+
+		if weekdayNum.N > 0 && (day.Day()-1)/7+1 == weekdayNum.N {
+			return true
+		}
+
+		if weekdayNum.N < 0 && (daysInMonth(day)-day.Day())/7+1 == -weekdayNum.N {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rule) matchesByMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay > 0 && day.Day() == monthDay {
+			return true
+		}
+
+		if monthDay < 0 && daysInMonth(day)+monthDay+1 == day.Day() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rule) startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) - int(r.WeekStart) + 7) % 7
+
+	return day.AddDate(0, 0, -offset)
+}
+
+func truncate(value time.Time) time.Time {
+	return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+func daysInMonth(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,130 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func formatDates(dates []time.Time) []string {
+	formatted := make([]string, 0, len(dates))
+
+	for _, value := range dates {
+		formatted = append(formatted, value.Format("2006-01-02"))
+	}
+
+	return formatted
+}
+
+func TestParse(t *testing.T) {
+	t.Run("Weekly with days", func(t *testing.T) {
+		rule, err := Parse("RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR")
+
+		assert.NoError(t, err)
+		assert.Equal(t, Weekly, rule.Freq)
+		assert.Equal(t, 1, rule.Interval)
+		assert.Len(t, rule.ByDay, 3)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,WE,FR", rule.String())
+	})
+
+	t.Run("Monthly with ordinal weekday", func(t *testing.T) {
+		rule, err := Parse("FREQ=MONTHLY;BYDAY=-1FR;COUNT=3")
+
+		assert.NoError(t, err)
+		assert.Equal(t, WeekdayNum{N: -1, Weekday: time.Friday}, rule.ByDay[0])
+		assert.Equal(t, "FREQ=MONTHLY;COUNT=3;BYDAY=-1FR", rule.String())
+	})
+
+	invalidRules := []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=HOURLY",
+		"FREQ=WEEKLY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ",
+	}
+
+	for _, value := range invalidRules {
+		t.Run("Invalid "+value, func(t *testing.T) {
+			_, err := Parse(value)
+
+			assert.True(t, errors.Is(err, ErrInvalidRule))
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	t.Run("Every Monday, Wednesday and Friday", func(t *testing.T) {
+		rule, _ := Parse("FREQ=WEEKLY;BYDAY=MO,WE,FR")
+
+		// 2025-10-01 is a Wednesday
+		occurrences := rule.Between(date(2025, 10, 1), date(2025, 10, 6), date(2025, 10, 12))
+
+		assert.Equal(t, []string{"2025-10-06", "2025-10-08", "2025-10-10"}, formatDates(occurrences))
+	})
+
+	t.Run("Every other week defaults to the start weekday", func(t *testing.T) {
+		rule, _ := Parse("FREQ=WEEKLY;INTERVAL=2")
+
+		occurrences := rule.Between(date(2025, 10, 1), date(2025, 10, 1), date(2025, 10, 31))
+
+		assert.Equal(t, []string{"2025-10-01", "2025-10-15", "2025-10-29"}, formatDates(occurrences))
+	})
+
+	t.Run("Count is applied from the start date", func(t *testing.T) {
+		rule, _ := Parse("FREQ=DAILY;COUNT=5")
+
+		occurrences := rule.Between(date(2025, 10, 1), date(2025, 10, 4), date(2025, 10, 31))
+
+		assert.Equal(t, []string{"2025-10-04", "2025-10-05"}, formatDates(occurrences))
+	})
+
+	t.Run("Until is inclusive", func(t *testing.T) {
+		rule, _ := Parse("FREQ=DAILY;INTERVAL=3;UNTIL=20251007T235959Z")
+
+		occurrences := rule.Between(date(2025, 10, 1), date(2025, 9, 1), date(2025, 12, 31))
+
+		assert.Equal(t, []string{"2025-10-01", "2025-10-04", "2025-10-07"}, formatDates(occurrences))
+	})
+
+	t.Run("Last Friday of the month", func(t *testing.T) {
+		rule, _ := Parse("FREQ=MONTHLY;BYDAY=-1FR")
+
+		occurrences := rule.Between(date(2025, 10, 1), date(2025, 10, 1), date(2025, 12, 31))
+
+		assert.Equal(t, []string{"2025-10-31", "2025-11-28", "2025-12-26"}, formatDates(occurrences))
+	})
+
+	t.Run("Monthly on the last day", func(t *testing.T) {
+		rule, _ := Parse("FREQ=MONTHLY;BYMONTHDAY=-1")
+
+		occurrences := rule.Between(date(2025, 1, 1), date(2025, 1, 1), date(2025, 3, 31))
+
+		assert.Equal(t, []string{"2025-01-31", "2025-02-28", "2025-03-31"}, formatDates(occurrences))
+	})
+
+	t.Run("Yearly on the start date", func(t *testing.T) {
+		rule, _ := Parse("FREQ=YEARLY")
+
+		occurrences := rule.Between(date(2024, 3, 10), date(2024, 1, 1), date(2026, 12, 31))
+
+		assert.Equal(t, []string{"2024-03-10", "2025-03-10", "2026-03-10"}, formatDates(occurrences))
+	})
+
+	t.Run("Nothing before the start date", func(t *testing.T) {
+		rule, _ := Parse("FREQ=DAILY")
+
+		occurrences := rule.Between(date(2025, 10, 10), date(2025, 10, 1), date(2025, 10, 9))
+
+		assert.Empty(t, occurrences)
+	})
+}
@@ -0,0 +1,353 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/requests"
+	"time"
+)
+
+// MeasurementMetrics lists the measured columns. Weight is in kilograms, body
+// fat in percent and every circumference in centimeters.
+var MeasurementMetrics = []string{"weight", "body_fat_percentage", "waist", "chest", "hips", "neck", "arm", "forearm", "thigh", "calf"}
+
+type BodyMeasurement struct {
+	ID                int        `json:"id"`
+	UserID            int        `json:"user_id"`
+	MeasuredAt        time.Time  `json:"measured_at"`
+	Weight            *float64   `json:"weight"`
+	BodyFatPercentage *float64   `json:"body_fat_percentage"`
+	Waist             *float64   `json:"waist"`
+	Chest             *float64   `json:"chest"`
+	Hips              *float64   `json:"hips"`
+	Neck              *float64   `json:"neck"`
+	Arm               *float64   `json:"arm"`
+	Forearm           *float64   `json:"forearm"`
+	Thigh             *float64   `json:"thigh"`
+	Calf              *float64   `json:"calf"`
+	Notes             string     `json:"notes"`
+	CreatedAt         *time.Time `json:"created_at"`
+	UpdatedAt         *time.Time `json:"updated_at"`
+}
+
+// MeasurementPoint is one value of a metric, either in a time series or as
+// the latest known value.
+type MeasurementPoint struct {
+	MeasuredAt time.Time `json:"measured_at"`
+	Value      float64   `json:"value"`
+}
+
+func (m *BodyMeasurement) FromBodyMeasurementRequest(measurementRequest *requests.BodyMeasurementRequest) *BodyMeasurement {
+	m.MeasuredAt = time.Now()
+
+	if measurementRequest.MeasuredAt != "" {
+		m.MeasuredAt, _ = time.Parse(time.RFC3339, measurementRequest.MeasuredAt)
+	}
+
+	m.Weight = measurementRequest.Weight
+	m.BodyFatPercentage = measurementRequest.BodyFatPercentage
+	m.Waist = measurementRequest.Waist
+	m.Chest = measurementRequest.Chest
+	m.Hips = measurementRequest.Hips
+	m.Neck = measurementRequest.Neck
+	m.Arm = measurementRequest.Arm
+	m.Forearm = measurementRequest.Forearm
+	m.Thigh = measurementRequest.Thigh
+	m.Calf = measurementRequest.Calf
+	m.Notes = measurementRequest.Notes
+
+	return m
+}
+
+// metricValues keeps the same order as MeasurementMetrics.
+func (m *BodyMeasurement) metricValues() []*float64 {
+	return []*float64{m.Weight, m.BodyFatPercentage, m.Waist, m.Chest, m.Hips, m.Neck, m.Arm, m.Forearm, m.Thigh, m.Calf}
+}
+
+type BodyMeasurementStore interface {
+	CreateMeasurement(measurement *BodyMeasurement) (*BodyMeasurement, error)
+	UpdateMeasurement(id int, measurement *BodyMeasurement) (*BodyMeasurement, error)
+	GetMeasurementById(id int) (*BodyMeasurement, error)
+	DeleteMeasurement(id int) error
+	GetAllMeasurements(userID int, from, to *time.Time) ([]BodyMeasurement, error)
+	OwnsMeasurement(id int, userID int) (bool, error)
+	GetLatestMeasurements(userID int) (map[string]*MeasurementPoint, error)
+	GetMeasurementSeries(userID int, metric string, from, to *time.Time) ([]MeasurementPoint, error)
+}
+
+type PostgresBodyMeasurementStore struct {
+	db *sql.DB
+}
+
+func NewPostgresBodyMeasurementStore(db *sql.DB) *PostgresBodyMeasurementStore {
+	return &PostgresBodyMeasurementStore{
+		db: db,
+	}
+}
+
+const bodyMeasurementColumns = `id, user_id, measured_at, weight, body_fat_percentage, waist, chest, hips, neck, arm, forearm, thigh, calf, notes, created_at, updated_at`
+
+func scanBodyMeasurement(scanner interface{ Scan(dest ...any) error }, measurement *BodyMeasurement) error {
+	return scanner.Scan(
+		&measurement.ID,
+		&measurement.UserID,
+		&measurement.MeasuredAt,
+		&measurement.Weight,
+		&measurement.BodyFatPercentage,
+		&measurement.Waist,
+		&measurement.Chest,
+		&measurement.Hips,
+		&measurement.Neck,
+		&measurement.Arm,
+		&measurement.Forearm,
+		&measurement.Thigh,
+		&measurement.Calf,
+		&measurement.Notes,
+		&measurement.CreatedAt,
+		&measurement.UpdatedAt,
+	)
+}
+
+func (s *PostgresBodyMeasurementStore) CreateMeasurement(measurement *BodyMeasurement) (*BodyMeasurement, error) {
+	query := `
+		insert into body_measurements (user_id, measured_at, weight, body_fat_percentage, waist, chest, hips, neck, arm, forearm, thigh, calf, notes)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		returning id, created_at, updated_at
+	`
+
+	args := append([]any{measurement.UserID, measurement.MeasuredAt}, measurementArgs(measurement)...)
+	err := s.db.QueryRow(query, args...).Scan(&measurement.ID, &measurement.CreatedAt, &measurement.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return measurement, nil
+}
+
+func (s *PostgresBodyMeasurementStore) UpdateMeasurement(id int, measurement *BodyMeasurement) (*BodyMeasurement, error) {
+	query := `
+		update body_measurements
+		set measured_at = $2, weight = $3, body_fat_percentage = $4, waist = $5, chest = $6, hips = $7, neck = $8,
+		    arm = $9, forearm = $10, thigh = $11, calf = $12, notes = $13, updated_at = now()
+		where id = $1
+		returning user_id, created_at, updated_at
+	`
+
+	measurement.ID = id
+	args := append([]any{id, measurement.MeasuredAt}, measurementArgs(measurement)...)
+	err := s.db.QueryRow(query, args...).Scan(&measurement.UserID, &measurement.CreatedAt, &measurement.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return measurement, nil
+}
+
+func measurementArgs(measurement *BodyMeasurement) []any {
+	args := make([]any, 0, len(MeasurementMetrics)+1)
+
+	for _, value := range measurement.metricValues() {
+		args = append(args, value)
+	}
+
+	return append(args, measurement.Notes)
+}
+
+func (s *PostgresBodyMeasurementStore) GetMeasurementById(id int) (*BodyMeasurement, error) {
+	measurement := &BodyMeasurement{}
+	query := fmt.Sprintf(`select %s from body_measurements where id = $1`, bodyMeasurementColumns)
+
+	err := scanBodyMeasurement(s.db.QueryRow(query, id), measurement)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return measurement, nil
+}
+
+func (s *PostgresBodyMeasurementStore) DeleteMeasurement(id int) error {
+	result, err := s.db.Exec("delete from body_measurements where id = $1", id)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return internalErrors.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetAllMeasurements lists the measurements of the user, most recent first.
+func (s *PostgresBodyMeasurementStore) GetAllMeasurements(userID int, from, to *time.Time) ([]BodyMeasurement, error) {
+	query := fmt.Sprintf(`
+		select %s
+		from body_measurements
+		where user_id = $1
+		  and ($2::timestamptz is null or measured_at >= $2)
+		  and ($3::timestamptz is null or measured_at < $3)
+		order by measured_at desc, id desc
+	`, bodyMeasurementColumns)
+
+	measurements := make([]BodyMeasurement, 0)
+
+	err := scanRows(s.db, query, []any{userID, from, to}, func(rows *sql.Rows) error {
+		measurement := BodyMeasurement{}
+
+		if err := scanBodyMeasurement(rows, &measurement); err != nil {
+			return err
+		}
+
+		measurements = append(measurements, measurement)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return measurements, nil
+}
+
+func (s *PostgresBodyMeasurementStore) OwnsMeasurement(id int, userID int) (bool, error) {
+	var exists bool
+	query := `select exists(select 1 from body_measurements where id = $1 and user_id = $2)`
+
+	err := s.db.QueryRow(query, id, userID).Scan(&exists)
+
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// GetLatestMeasurements returns the most recent value of every metric, which
+// may come from different measurements. Metrics never measured are nil.
+func (s *PostgresBodyMeasurementStore) GetLatestMeasurements(userID int) (map[string]*MeasurementPoint, error) {
+	latest := make(map[string]*MeasurementPoint, len(MeasurementMetrics))
+
+	for _, metric := range MeasurementMetrics {
+		latest[metric] = nil
+	}
+
+	measurements, err := s.GetAllMeasurements(userID, nil, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, measurement := range measurements {
+		for i, value := range measurement.metricValues() {
+			metric := MeasurementMetrics[i]
+
+			if value != nil && latest[metric] == nil {
+				latest[metric] = &MeasurementPoint{MeasuredAt: measurement.MeasuredAt, Value: *value}
+			}
+		}
+	}
+
+	return latest, nil
+}
+
+// GetMeasurementSeries returns the values of one metric in chronological
+// order. metric must be one of MeasurementMetrics.
+func (s *PostgresBodyMeasurementStore) GetMeasurementSeries(userID int, metric string, from, to *time.Time) ([]MeasurementPoint, error) {
+	if !isMeasurementMetric(metric) {
+		return nil, fmt.Errorf("%w: metric", internalErrors.ErrInvalidQueryParam)
+	}
+
+	return loadMeasurementSeries(s.db, userID, metric, from, to)
+}
+
+func isMeasurementMetric(metric string) bool {
+	for _, known := range MeasurementMetrics {
+		if known == metric {
+			return true
+		}
+	}
+
+	return false
+}
+
+func loadMeasurementSeries(q queryer, userID int, metric string, from, to *time.Time) ([]MeasurementPoint, error) {
+	query := fmt.Sprintf(`
+		select measured_at, %[1]s
+		from body_measurements
+		where user_id = $1
+		  and %[1]s is not null
+		  and ($2::timestamptz is null or measured_at >= $2)
+		  and ($3::timestamptz is null or measured_at < $3)
+		order by measured_at, id
+	`, metric)
+
+	points := make([]MeasurementPoint, 0)
+
+	err := scanRows(q, query, []any{userID, from, to}, func(rows *sql.Rows) error {
+		point := MeasurementPoint{}
+
+		if err := rows.Scan(&point.MeasuredAt, &point.Value); err != nil {
+			return err
+		}
+
+		points = append(points, point)
+
+		return nil
+	})
+
+	return points, err
+}
+
+// bodyweightAt picks the last bodyweight measured up to at from a
+// chronological series, falling back to the first one measured afterwards.
+func bodyweightAt(series []MeasurementPoint, at time.Time) *float64 {
+	if len(series) == 0 {
+		return nil
+	}
+
+	bodyweight := series[0].Value
+
+	for _, point := range series {
+		if point.MeasuredAt.After(at) {
+			break
+		}
+
+		bodyweight = point.Value
+	}
+
+	return &bodyweight
+}
+
+// latestBodyweight returns the most recent bodyweight of the user, or 0 when
+// they never logged one.
+func latestBodyweight(q dbtx, userID int) (float64, error) {
+	var bodyweight float64
+	query := `
+		select weight
+		from body_measurements
+		where user_id = $1 and weight is not null
+		order by measured_at desc
+		limit 1
+	`
+
+	err := q.QueryRow(query, userID).Scan(&bodyweight)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return bodyweight, err
+}
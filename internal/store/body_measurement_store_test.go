@@ -0,0 +1,113 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	"partiuFit/internal/fitness"
+	"partiuFit/internal/utils"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyMeasurementStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	measurementStore := NewPostgresBodyMeasurementStore(db)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	older := &BodyMeasurement{UserID: user.ID, MeasuredAt: now.AddDate(0, 0, -14), Weight: utils.ValueToPointer(82.5), Waist: utils.ValueToPointer(90.0)}
+	newer := &BodyMeasurement{UserID: user.ID, MeasuredAt: now.AddDate(0, 0, -1), Weight: utils.ValueToPointer(80.0), BodyFatPercentage: utils.ValueToPointer(18.5)}
+
+	t.Run("Create measurements", func(t *testing.T) {
+		var err error
+		older, err = measurementStore.CreateMeasurement(older)
+		assert.NoError(t, err)
+		assert.NotZero(t, older.ID)
+
+		newer, err = measurementStore.CreateMeasurement(newer)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Measurement without values is rejected", func(t *testing.T) {
+		_, err := measurementStore.CreateMeasurement(&BodyMeasurement{UserID: user.ID, MeasuredAt: now})
+		assert.Error(t, err)
+	})
+
+	t.Run("Latest values may come from different measurements", func(t *testing.T) {
+		latest, err := measurementStore.GetLatestMeasurements(user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 80.0, latest["weight"].Value)
+		assert.Equal(t, 90.0, latest["waist"].Value)
+		assert.True(t, latest["waist"].MeasuredAt.Equal(older.MeasuredAt))
+		assert.Nil(t, latest["chest"])
+	})
+
+	t.Run("Series are chronological and skip missing values", func(t *testing.T) {
+		series, err := measurementStore.GetMeasurementSeries(user.ID, "weight", nil, nil)
+		assert.NoError(t, err)
+		assert.Len(t, series, 2)
+		assert.Equal(t, 82.5, series[0].Value)
+
+		series, err = measurementStore.GetMeasurementSeries(user.ID, "body_fat_percentage", nil, nil)
+		assert.NoError(t, err)
+		assert.Len(t, series, 1)
+
+		_, err = measurementStore.GetMeasurementSeries(user.ID, "weight; drop table users", nil, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Relative strength uses the bodyweight of the period", func(t *testing.T) {
+		_, err := NewPostgresWorkoutStore(db).CreateWorkout(&Workout{
+			Title:  "Squat day",
+			UserID: user.ID,
+			Entries: []WorkoutEntry{{
+				ExerciseName: "Box Squat",
+				OrderIndex:   1,
+				UserID:       user.ID,
+				WorkoutSets:  []WorkoutSet{{SetType: SetTypeWorking, Reps: utils.ValueToPointer(1), Weight: 120, Completed: true}},
+			}},
+		})
+		assert.NoError(t, err)
+
+		progress, err := NewPostgresStatsStore(db).GetExerciseProgress(user.ID, ExerciseKey(nil, "Box Squat"), ProgressFilters{
+			Bucket:  BucketWeek,
+			Formula: fitness.FormulaEpley,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, progress, 1)
+		assert.Equal(t, 1.5, *progress[0].RelativeStrength, "the latest bodyweight applies to the current week")
+	})
+
+	t.Run("Update and delete measurements", func(t *testing.T) {
+		newer.Weight = utils.ValueToPointer(79.0)
+		updated, err := measurementStore.UpdateMeasurement(newer.ID, newer)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, updated.UserID)
+
+		assert.NoError(t, measurementStore.DeleteMeasurement(older.ID))
+		assert.Error(t, measurementStore.DeleteMeasurement(older.ID))
+
+		measurements, err := measurementStore.GetAllMeasurements(user.ID, nil, nil)
+		assert.NoError(t, err)
+		assert.Len(t, measurements, 1)
+		assert.Equal(t, 79.0, *measurements[0].Weight)
+	})
+}
+
+func TestBodyweightAt(t *testing.T) {
+	day := time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)
+	series := []MeasurementPoint{
+		{MeasuredAt: day, Value: 80},
+		{MeasuredAt: day.AddDate(0, 0, 7), Value: 78},
+	}
+
+	assert.Nil(t, bodyweightAt(nil, day))
+	assert.Equal(t, 80.0, *bodyweightAt(series, day.AddDate(0, 0, -30)), "falls back to the first measurement")
+	assert.Equal(t, 80.0, *bodyweightAt(series, day.AddDate(0, 0, 3)))
+	assert.Equal(t, 78.0, *bodyweightAt(series, day.AddDate(0, 1, 0)))
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"database/sql"
+	"partiuFit/internal/fitness"
+)
+
+// estimateCalories fills in the calories of a workout logged without them, or
+// whose value was itself an estimate, from the MET values of its exercises and
+// the latest bodyweight of the user. Timed entries count the time of all their
+// sets. Values sent by the user are kept.
+func estimateCalories(tx *sql.Tx, workout *Workout) error {
+	if workout.CaloriesBurned > 0 && !workout.CaloriesEstimated {
+		return nil
+	}
+
+	var defaultMET float64
+	err := tx.QueryRow("select met from met_values where exercise_id is null and movement_type is null").Scan(&defaultMET)
+
+	if err != nil {
+		return err
+	}
+
+	bodyweight, err := latestBodyweight(tx, workout.UserID)
+
+	if err != nil {
+		return err
+	}
+
+	query := `
+		select coalesce(exercise_met.met, movement_met.met, $2),
+		       (select sum(ws.duration_seconds) from workout_sets ws where ws.workout_entry_id = we.id)
+		from workout_entries we
+		left join exercises e on e.id = we.exercise_id
+		left join met_values exercise_met on exercise_met.exercise_id = we.exercise_id
+		left join met_values movement_met on movement_met.movement_type = e.movement_type
+		where we.workout_id = $1
+		order by we.order_index
+	`
+
+	activities := make([]fitness.Activity, 0)
+
+	err = scanRows(tx, query, []any{workout.ID, defaultMET}, func(rows *sql.Rows) error {
+		activity := fitness.Activity{}
+
+		if err := rows.Scan(&activity.MET, &activity.DurationSeconds); err != nil {
+			return err
+		}
+
+		activities = append(activities, activity)
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	workout.CaloriesBurned = fitness.EstimateCalories(activities, workout.DurationMinutes, defaultMET, bodyweight)
+	workout.CaloriesEstimated = workout.CaloriesBurned > 0
+	workout.CaloriesFormula = nil
+
+	if workout.CaloriesEstimated {
+		formula := fitness.CaloriesFormulaMET
+		workout.CaloriesFormula = &formula
+	}
+
+	_, err = tx.Exec(
+		"update workouts set calories_burned = $2, calories_estimated = $3, calories_formula = $4 where id = $1",
+		workout.ID, workout.CaloriesBurned, workout.CaloriesEstimated, workout.CaloriesFormula,
+	)
+
+	return err
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	internalErrors "partiuFit/internal/errors"
+)
+
+// cursor is the keyset position of the last row of a page. It is handed to
+// clients as an opaque base64 string.
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	data, err := json.Marshal(c)
+
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string, sort string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+
+	if err != nil {
+		return nil, internalErrors.ErrInvalidCursor
+	}
+
+	c := &cursor{}
+
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, internalErrors.ErrInvalidCursor
+	}
+
+	if c.Sort != sort {
+		return nil, internalErrors.ErrInvalidCursor
+	}
+
+	return c, nil
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day without time of day, stored in postgres date columns
+// and serialized as YYYY-MM-DD.
+type Date struct {
+	time.Time
+}
+
+func NewDate(value time.Time) Date {
+	return Date{time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+func ParseDate(value string) (Date, error) {
+	parsed, err := time.Parse(DateLayout, value)
+
+	if err != nil {
+		return Date{}, err
+	}
+
+	return Date{parsed}, nil
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) Scan(src any) error {
+	value, ok := src.(time.Time)
+
+	if !ok {
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+
+	*d = NewDate(value)
+
+	return nil
+}
+
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,301 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/requests"
+	"strings"
+	"time"
+)
+
+type Exercise struct {
+	ID                    int         `json:"id"`
+	UserID                *int        `json:"user_id"`
+	Name                  string      `json:"name"`
+	Aliases               StringArray `json:"aliases"`
+	PrimaryMuscleGroups   StringArray `json:"primary_muscle_groups"`
+	SecondaryMuscleGroups StringArray `json:"secondary_muscle_groups"`
+	Equipment             string      `json:"equipment"`
+	MovementType          string      `json:"movement_type"`
+	CreatedAt             *time.Time  `json:"created_at"`
+	UpdatedAt             *time.Time  `json:"updated_at"`
+}
+
+// IsCustom reports whether the exercise was created by a user instead of
+// belonging to the global catalog.
+func (e *Exercise) IsCustom() bool {
+	return e.UserID != nil
+}
+
+func (e *Exercise) FromExerciseRequest(exerciseRequest *requests.ExerciseRequest) *Exercise {
+	e.Name = exerciseRequest.Name
+	e.Aliases = exerciseRequest.Aliases
+	e.PrimaryMuscleGroups = exerciseRequest.PrimaryMuscleGroups
+	e.SecondaryMuscleGroups = exerciseRequest.SecondaryMuscleGroups
+	e.Equipment = exerciseRequest.Equipment
+	e.MovementType = exerciseRequest.MovementType
+
+	if e.Aliases == nil {
+		e.Aliases = StringArray{}
+	}
+
+	if e.SecondaryMuscleGroups == nil {
+		e.SecondaryMuscleGroups = StringArray{}
+	}
+
+	return e
+}
+
+type ExerciseFilters struct {
+	Search       string
+	MuscleGroup  string
+	Equipment    string
+	MovementType string
+	CustomOnly   bool
+}
+
+type ExerciseStore interface {
+	CreateExercise(exercise *Exercise) (*Exercise, error)
+	UpdateExercise(id int, exercise *Exercise) (*Exercise, error)
+	GetExerciseById(id int) (*Exercise, error)
+	DeleteExercise(id int) error
+	SearchExercises(userID int, filters ExerciseFilters) ([]Exercise, error)
+	FindExerciseByName(userID int, name string) (*Exercise, error)
+	OwnsExercise(id int, userID int) (bool, error)
+	CanUseExercise(id int, userID int) (bool, error)
+}
+
+type PostgresExerciseStore struct {
+	db *sql.DB
+}
+
+func NewPostgresExerciseStore(db *sql.DB) *PostgresExerciseStore {
+	return &PostgresExerciseStore{
+		db: db,
+	}
+}
+
+const exerciseColumns = `id, user_id, name, aliases, primary_muscle_groups, secondary_muscle_groups, equipment, movement_type, created_at, updated_at`
+
+// exerciseMatchesName is shared by every query that resolves a free-text
+// exercise name into the catalog. It expects the name to be bound to $2.
+const exerciseMatchesName = `(
+	lower(name) = lower(trim($2))
+	or exists (select 1 from unnest(aliases) alias where lower(alias) = lower(trim($2)))
+)`
+
+func scanExercise(scanner interface{ Scan(dest ...any) error }, exercise *Exercise) error {
+	return scanner.Scan(
+		&exercise.ID,
+		&exercise.UserID,
+		&exercise.Name,
+		&exercise.Aliases,
+		&exercise.PrimaryMuscleGroups,
+		&exercise.SecondaryMuscleGroups,
+		&exercise.Equipment,
+		&exercise.MovementType,
+		&exercise.CreatedAt,
+		&exercise.UpdatedAt,
+	)
+}
+
+func (s *PostgresExerciseStore) CreateExercise(exercise *Exercise) (*Exercise, error) {
+	query := `
+		insert into exercises (user_id, name, aliases, primary_muscle_groups, secondary_muscle_groups, equipment, movement_type)
+		values ($1, $2, $3, $4, $5, $6, $7)
+		returning id, created_at, updated_at
+	`
+
+	err := s.db.QueryRow(
+		query,
+		exercise.UserID,
+		exercise.Name,
+		[]string(exercise.Aliases),
+		[]string(exercise.PrimaryMuscleGroups),
+		[]string(exercise.SecondaryMuscleGroups),
+		exercise.Equipment,
+		exercise.MovementType,
+	).Scan(&exercise.ID, &exercise.CreatedAt, &exercise.UpdatedAt)
+
+	if err != nil {
+		return nil, internalErrors.HandleExerciseDatabaseError(err)
+	}
+
+	return exercise, nil
+}
+
+func (s *PostgresExerciseStore) UpdateExercise(id int, exercise *Exercise) (*Exercise, error) {
+	query := `
+		update exercises
+		set name = $2, aliases = $3, primary_muscle_groups = $4, secondary_muscle_groups = $5,
+		    equipment = $6, movement_type = $7, updated_at = now()
+		where id = $1
+		returning updated_at
+	`
+
+	exercise.ID = id
+
+	err := s.db.QueryRow(
+		query,
+		id,
+		exercise.Name,
+		[]string(exercise.Aliases),
+		[]string(exercise.PrimaryMuscleGroups),
+		[]string(exercise.SecondaryMuscleGroups),
+		exercise.Equipment,
+		exercise.MovementType,
+	).Scan(&exercise.UpdatedAt)
+
+	if err != nil {
+		return nil, internalErrors.HandleExerciseDatabaseError(err)
+	}
+
+	return exercise, nil
+}
+
+func (s *PostgresExerciseStore) GetExerciseById(id int) (*Exercise, error) {
+	exercise := &Exercise{}
+	query := fmt.Sprintf(`select %s from exercises where id = $1`, exerciseColumns)
+
+	err := scanExercise(s.db.QueryRow(query, id), exercise)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return exercise, nil
+}
+
+func (s *PostgresExerciseStore) DeleteExercise(id int) error {
+	result, err := s.db.Exec("delete from exercises where id = $1", id)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return internalErrors.ErrNoRows
+	}
+
+	return nil
+}
+
+// SearchExercises lists the global catalog together with the custom exercises of the user.
+func (s *PostgresExerciseStore) SearchExercises(userID int, filters ExerciseFilters) ([]Exercise, error) {
+	conditions := []string{"(user_id is null or user_id = $1)"}
+	args := []any{userID}
+
+	if filters.Search != "" {
+		args = append(args, "%"+escapeLike(filters.Search)+"%")
+		conditions = append(conditions, fmt.Sprintf(
+			"(name ilike $%d or exists (select 1 from unnest(aliases) alias where alias ilike $%d))",
+			len(args), len(args),
+		))
+	}
+
+	if filters.MuscleGroup != "" {
+		args = append(args, filters.MuscleGroup)
+		conditions = append(conditions, fmt.Sprintf(
+			"($%d = any(primary_muscle_groups) or $%d = any(secondary_muscle_groups))",
+			len(args), len(args),
+		))
+	}
+
+	if filters.Equipment != "" {
+		args = append(args, filters.Equipment)
+		conditions = append(conditions, fmt.Sprintf("equipment = $%d", len(args)))
+	}
+
+	if filters.MovementType != "" {
+		args = append(args, filters.MovementType)
+		conditions = append(conditions, fmt.Sprintf("movement_type = $%d", len(args)))
+	}
+
+	if filters.CustomOnly {
+		conditions = append(conditions, "user_id is not null")
+	}
+
+	query := fmt.Sprintf(`
+		select %s
+		from exercises
+		where %s
+		order by name, id
+	`, exerciseColumns, strings.Join(conditions, " and "))
+
+	rows, err := s.db.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var exercises = make([]Exercise, 0)
+
+	for rows.Next() {
+		exercise := Exercise{}
+
+		if err := scanExercise(rows, &exercise); err != nil {
+			return nil, err
+		}
+
+		exercises = append(exercises, exercise)
+	}
+
+	return exercises, rows.Err()
+}
+
+// FindExerciseByName resolves a name or alias, preferring the user's custom exercises over the global catalog.
+func (s *PostgresExerciseStore) FindExerciseByName(userID int, name string) (*Exercise, error) {
+	exercise := &Exercise{}
+	query := fmt.Sprintf(`
+		select %s
+		from exercises
+		where (user_id is null or user_id = $1) and %s
+		order by user_id nulls last, id
+		limit 1
+	`, exerciseColumns, exerciseMatchesName)
+
+	err := scanExercise(s.db.QueryRow(query, userID, name), exercise)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return exercise, nil
+}
+
+func (s *PostgresExerciseStore) OwnsExercise(id int, userID int) (bool, error) {
+	var owns bool
+
+	query := `
+		select exists(select 1 from exercises where id = $1 and user_id = $2)
+	`
+
+	err := s.db.QueryRow(query, id, userID).Scan(&owns)
+
+	return owns, err
+}
+
+// CanUseExercise reports whether the exercise is visible to the user, either
+// because it is part of the global catalog or because the user created it.
+func (s *PostgresExerciseStore) CanUseExercise(id int, userID int) (bool, error) {
+	var visible bool
+
+	query := `
+		select exists(select 1 from exercises where id = $1 and (user_id is null or user_id = $2))
+	`
+
+	err := s.db.QueryRow(query, id, userID).Scan(&visible)
+
+	return visible, err
+}
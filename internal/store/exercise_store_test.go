@@ -0,0 +1,122 @@
+package store
+
+import (
+	"errors"
+	testingUtils "partiuFit/internal/database/testing_utils"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/utils"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExerciseStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	john, _ := testingUtils.CreateToken(db, "johndoe")
+	jane, _ := testingUtils.CreateToken(db, "janedoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	exerciseStore := NewPostgresExerciseStore(db)
+
+	t.Run("Create custom exercise", func(t *testing.T) {
+		exercise := &Exercise{
+			UserID:                &john.ID,
+			Name:                  "Landmine Press",
+			Aliases:               StringArray{"Meio terra"},
+			PrimaryMuscleGroups:   StringArray{"shoulders"},
+			SecondaryMuscleGroups: StringArray{"chest", "triceps"},
+			Equipment:             "barbell",
+			MovementType:          "compound",
+		}
+
+		createdExercise, err := exerciseStore.CreateExercise(exercise)
+
+		assert.NoError(t, err)
+		assert.NotZero(t, createdExercise.ID)
+		assert.True(t, createdExercise.IsCustom())
+	})
+
+	t.Run("Create custom exercise with duplicate name", func(t *testing.T) {
+		exercise := &Exercise{
+			UserID:              &john.ID,
+			Name:                "landmine press",
+			PrimaryMuscleGroups: StringArray{"shoulders"},
+			Equipment:           "barbell",
+			MovementType:        "compound",
+		}
+
+		_, err := exerciseStore.CreateExercise(exercise)
+
+		assert.True(t, errors.Is(err, internalErrors.ErrExerciseExists))
+	})
+
+	t.Run("Get by id", func(t *testing.T) {
+		found := utils.Must(exerciseStore.FindExerciseByName(john.ID, "Landmine Press"))
+
+		exercise, err := exerciseStore.GetExerciseById(found.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, StringArray{"Meio terra"}, exercise.Aliases)
+		assert.Equal(t, StringArray{"chest", "triceps"}, exercise.SecondaryMuscleGroups)
+	})
+
+	t.Run("Find by alias in global catalog", func(t *testing.T) {
+		exercise, err := exerciseStore.FindExerciseByName(jane.ID, "  supino RETO ")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Bench Press", exercise.Name)
+		assert.False(t, exercise.IsCustom())
+	})
+
+	t.Run("Custom exercises are private", func(t *testing.T) {
+		_, err := exerciseStore.FindExerciseByName(jane.ID, "Landmine Press")
+
+		assert.True(t, errors.Is(err, internalErrors.ErrNoRows))
+	})
+
+	t.Run("Search by name and muscle group", func(t *testing.T) {
+		exercises, err := exerciseStore.SearchExercises(john.ID, ExerciseFilters{Search: "press", MuscleGroup: "shoulders"})
+
+		assert.NoError(t, err)
+		names := make([]string, 0, len(exercises))
+		for _, exercise := range exercises {
+			names = append(names, exercise.Name)
+		}
+		assert.Contains(t, names, "Landmine Press")
+		assert.Contains(t, names, "Overhead Press")
+		assert.NotContains(t, names, "Leg Press")
+	})
+
+	t.Run("Search custom only", func(t *testing.T) {
+		exercises, err := exerciseStore.SearchExercises(john.ID, ExerciseFilters{CustomOnly: true})
+
+		assert.NoError(t, err)
+		assert.Len(t, exercises, 1)
+	})
+
+	t.Run("Ownership and visibility", func(t *testing.T) {
+		custom := utils.Must(exerciseStore.FindExerciseByName(john.ID, "Landmine Press"))
+		global := utils.Must(exerciseStore.FindExerciseByName(john.ID, "Bench Press"))
+
+		assert.True(t, utils.Must(exerciseStore.OwnsExercise(custom.ID, john.ID)))
+		assert.False(t, utils.Must(exerciseStore.OwnsExercise(global.ID, john.ID)))
+		assert.True(t, utils.Must(exerciseStore.CanUseExercise(global.ID, jane.ID)))
+		assert.False(t, utils.Must(exerciseStore.CanUseExercise(custom.ID, jane.ID)))
+	})
+
+	t.Run("Update and delete custom exercise", func(t *testing.T) {
+		custom := utils.Must(exerciseStore.FindExerciseByName(john.ID, "Landmine Press"))
+		custom.Name = "Landmine Shoulder Press"
+
+		_, err := exerciseStore.UpdateExercise(custom.ID, custom)
+		assert.NoError(t, err)
+
+		err = exerciseStore.DeleteExercise(custom.ID)
+		assert.NoError(t, err)
+
+		err = exerciseStore.DeleteExercise(custom.ID)
+		assert.True(t, errors.Is(err, internalErrors.ErrNoRows))
+	})
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FeedItem is a workout of another user as the viewer sees it, without the ids
+// of its entries and sets.
+type FeedItem struct {
+	WorkoutID  int            `json:"workout_id"`
+	Author     PublicUser     `json:"author"`
+	Visibility string         `json:"visibility"`
+	Workout    *SharedWorkout `json:"workout"`
+}
+
+type FeedFilters struct {
+	Limit  int
+	Cursor string
+}
+
+func (f *FeedFilters) normalize() {
+	if f.Limit <= 0 {
+		f.Limit = DefaultPageLimit
+	}
+
+	if f.Limit > MaxPageLimit {
+		f.Limit = MaxPageLimit
+	}
+}
+
+type FeedStore interface {
+	GetFeed(userID int, filters FeedFilters) ([]FeedItem, *Pagination, error)
+	GetUserWorkouts(viewerID int, userID int, filters FeedFilters) ([]FeedItem, *Pagination, error)
+}
+
+type PostgresFeedStore struct {
+	db *sql.DB
+}
+
+func NewPostgresFeedStore(db *sql.DB) *PostgresFeedStore {
+	return &PostgresFeedStore{
+		db: db,
+	}
+}
+
+// GetFeed lists the completed workouts of the users userID follows with an
+// approved follow, newest first. Private workouts are left out; public and
+// followers-only ones are both open to approved followers.
+func (s *PostgresFeedStore) GetFeed(userID int, filters FeedFilters) ([]FeedItem, *Pagination, error) {
+	conditions := []string{
+		"workouts.user_id in (select followee_id from follows where follower_id = $1 and status = 'approved')",
+		"workouts.visibility <> 'private'",
+	}
+
+	return s.getFeed(conditions, []any{userID}, filters)
+}
+
+// GetUserWorkouts lists the completed workouts of userID that viewerID can
+// see: all of them for the owner, public ones for everyone and followers-only
+// ones for approved followers.
+func (s *PostgresFeedStore) GetUserWorkouts(viewerID int, userID int, filters FeedFilters) ([]FeedItem, *Pagination, error) {
+	conditions := []string{
+		"workouts.user_id = $2",
+		`(
+			$1::integer = $2
+			or workouts.visibility = 'public'
+			or (workouts.visibility = 'followers' and exists(select 1 from follows where follower_id = $1 and followee_id = $2 and status = 'approved'))
+		)`,
+	}
+
+	return s.getFeed(conditions, []any{viewerID, userID}, filters)
+}
+
+// getFeed pages through the workouts matching the conditions with the same
+// cursors as GetAllWorkouts. Entries, sets and authors are loaded with one
+// query each for the whole page.
+func (s *PostgresFeedStore) getFeed(conditions []string, args []any, filters FeedFilters) ([]FeedItem, *Pagination, error) {
+	filters.normalize()
+	conditions = append(conditions, "workouts.deleted_at is null", fmt.Sprintf("workouts.status = '%s'", WorkoutCompleted))
+
+	if filters.Cursor != "" {
+		c, err := decodeCursor(filters.Cursor, "created_at")
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		args = append(args, c.Value, c.ID)
+		conditions = append(conditions, fmt.Sprintf("(workouts.created_at, workouts.id) < ($%d::timestamptz, $%d)", len(args)-1, len(args)))
+	}
+
+	args = append(args, filters.Limit+1)
+
+	query := fmt.Sprintf(`
+		select %s
+		from workouts
+		where %s
+		order by workouts.created_at desc, workouts.id desc
+		limit $%d
+	`, workoutColumns, strings.Join(conditions, " and "), len(args))
+
+	workouts := make([]Workout, 0)
+
+	err := scanRows(s.db, query, args, func(rows *sql.Rows) error {
+		workout := Workout{}
+
+		if err := scanWorkout(rows, &workout); err != nil {
+			return err
+		}
+
+		workouts = append(workouts, workout)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := &Pagination{Limit: filters.Limit}
+
+	if len(workouts) > filters.Limit {
+		workouts = workouts[:filters.Limit]
+		last := workouts[len(workouts)-1]
+
+		pagination.HasMore = true
+		pagination.NextCursor = encodeCursor(cursor{
+			Sort:  "created_at",
+			Value: last.CreatedAt.Format(time.RFC3339Nano),
+			ID:    last.ID,
+		})
+	}
+
+	if err := loadWorkoutEntries(s.db, workouts); err != nil {
+		return nil, nil, err
+	}
+
+	authors, err := loadAuthors(s.db, workouts)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := make([]FeedItem, len(workouts))
+
+	for i := range workouts {
+		items[i] = FeedItem{
+			WorkoutID:  workouts[i].ID,
+			Author:     authors[workouts[i].UserID],
+			Visibility: *workouts[i].Visibility,
+			Workout:    NewSharedWorkout(&workouts[i]),
+		}
+	}
+
+	return items, pagination, nil
+}
+
+func loadAuthors(q queryer, workouts []Workout) (map[int]PublicUser, error) {
+	userIDs := make([]int, len(workouts))
+
+	for i := range workouts {
+		userIDs[i] = workouts[i].UserID
+	}
+
+	authors := make(map[int]PublicUser)
+
+	err := scanRows(q, "select id, username, name from users where id = any($1)", []any{userIDs}, func(rows *sql.Rows) error {
+		author := PublicUser{}
+
+		if err := rows.Scan(&author.ID, &author.Username, &author.Name); err != nil {
+			return err
+		}
+
+		authors[author.ID] = author
+
+		return nil
+	})
+
+	return authors, err
+}
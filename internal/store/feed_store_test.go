@@ -0,0 +1,111 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	"partiuFit/internal/utils"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	john, _ := testingUtils.CreateToken(db, "johndoe")
+	jane, _ := testingUtils.CreateToken(db, "janedoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	workoutStore := NewPostgresWorkoutStore(db)
+	followStore := NewPostgresFollowStore(db)
+	feedStore := NewPostgresFeedStore(db)
+
+	createWorkout := func(title string, visibility *string) *Workout {
+		return utils.Must(workoutStore.CreateWorkout(&Workout{
+			Title:           title,
+			DurationMinutes: 60,
+			UserID:          jane.ID,
+			Visibility:      visibility,
+			Entries: []WorkoutEntry{
+				{ExerciseName: "Squat", Sets: 1, Reps: utils.ValueToPointer(5), Weight: 100, OrderIndex: 1, UserID: jane.ID},
+			},
+		}))
+	}
+
+	createWorkout("Public", utils.ValueToPointer(VisibilityPublic))
+	createWorkout("Followers", utils.ValueToPointer(VisibilityFollowers))
+	createWorkout("Private", utils.ValueToPointer(VisibilityPrivate))
+	createWorkout("Account default", nil)
+
+	titles := func(items []FeedItem) []string {
+		result := make([]string, 0, len(items))
+
+		for _, item := range items {
+			result = append(result, item.Workout.Title)
+		}
+
+		return result
+	}
+
+	t.Run("The feed only has followees", func(t *testing.T) {
+		items, _, err := feedStore.GetFeed(john.ID, FeedFilters{})
+		assert.NoError(t, err)
+		assert.Empty(t, items)
+	})
+
+	t.Run("Pending follows see nothing", func(t *testing.T) {
+		utils.Must(followStore.Follow(john.ID, jane.ID))
+
+		items, _, err := feedStore.GetFeed(john.ID, FeedFilters{})
+		assert.NoError(t, err)
+		assert.Empty(t, items)
+
+		items, _, err = feedStore.GetUserWorkouts(john.ID, jane.ID, FeedFilters{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Public"}, titles(items))
+	})
+
+	t.Run("Followers see public and followers-only workouts", func(t *testing.T) {
+		utils.MustIfError(followStore.ApproveFollower(jane.ID, john.ID))
+
+		items, pagination, err := feedStore.GetFeed(john.ID, FeedFilters{Limit: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Followers"}, titles(items))
+		assert.True(t, pagination.HasMore)
+		assert.Equal(t, "janedoe", items[0].Author.Username)
+		assert.Equal(t, 100.0, items[0].Workout.Entries[0].Weight)
+
+		items, pagination, err = feedStore.GetFeed(john.ID, FeedFilters{Limit: 1, Cursor: pagination.NextCursor})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Public"}, titles(items))
+		assert.False(t, pagination.HasMore)
+	})
+
+	t.Run("The account default only applies to new workouts", func(t *testing.T) {
+		_, err := db.Exec("update users set default_visibility = 'followers' where id = $1", jane.ID)
+		assert.NoError(t, err)
+
+		items, _, err := feedStore.GetFeed(john.ID, FeedFilters{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Followers", "Public"}, titles(items), "the existing history stays private")
+
+		createWorkout("New default", nil)
+
+		items, _, err = feedStore.GetFeed(john.ID, FeedFilters{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"New default", "Followers", "Public"}, titles(items))
+		assert.Equal(t, VisibilityFollowers, items[0].Visibility)
+	})
+
+	t.Run("Profiles respect the visibility", func(t *testing.T) {
+		utils.MustIfError(followStore.Unfollow(john.ID, jane.ID))
+
+		items, _, err := feedStore.GetUserWorkouts(john.ID, jane.ID, FeedFilters{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Public"}, titles(items))
+
+		items, _, err = feedStore.GetUserWorkouts(jane.ID, jane.ID, FeedFilters{})
+		assert.NoError(t, err)
+		assert.Len(t, items, 5)
+	})
+}
@@ -0,0 +1,198 @@
+package store
+
+import (
+	"database/sql"
+	internalErrors "partiuFit/internal/errors"
+	"time"
+)
+
+const (
+	VisibilityPublic    = "public"
+	VisibilityFollowers = "followers"
+	VisibilityPrivate   = "private"
+)
+
+// A follow starts pending and only counts, for the feed and followers-only
+// workouts, once the followed user approves it.
+const (
+	FollowPending  = "pending"
+	FollowApproved = "approved"
+)
+
+// PublicUser is what other users see of an account: never its email or
+// settings.
+type PublicUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// Follow is a user on either side of a follow, with when it started.
+type Follow struct {
+	User      PublicUser `json:"user"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+type FollowStore interface {
+	Follow(followerID int, followeeID int) (string, error)
+	Unfollow(followerID int, followeeID int) error
+	ApproveFollower(userID int, followerID int) error
+	RejectFollower(userID int, followerID int) error
+	IsFollowing(followerID int, followeeID int) (bool, error)
+	GetFollowers(userID int) ([]Follow, error)
+	GetFollowing(userID int) ([]Follow, error)
+	GetFollowRequests(userID int) ([]Follow, error)
+}
+
+type PostgresFollowStore struct {
+	db *sql.DB
+}
+
+func NewPostgresFollowStore(db *sql.DB) *PostgresFollowStore {
+	return &PostgresFollowStore{
+		db: db,
+	}
+}
+
+// Follow asks for followerID to follow followeeID and returns the status of
+// the follow: pending until followeeID approves it. Asking again keeps the
+// current status. Unknown users return ErrNoRows.
+func (s *PostgresFollowStore) Follow(followerID int, followeeID int) (string, error) {
+	if followerID == followeeID {
+		return "", internalErrors.ErrCannotFollowSelf
+	}
+
+	var exists bool
+
+	if err := s.db.QueryRow("select exists(select 1 from users where id = $1)", followeeID).Scan(&exists); err != nil {
+		return "", err
+	}
+
+	if !exists {
+		return "", internalErrors.ErrNoRows
+	}
+
+	var status string
+
+	err := s.db.QueryRow(`
+		insert into follows (follower_id, followee_id, status)
+		values ($1, $2, $3)
+		on conflict (follower_id, followee_id) do update set status = follows.status
+		returning status
+	`, followerID, followeeID, FollowPending).Scan(&status)
+
+	return status, err
+}
+
+// Unfollow stops followerID from following followeeID, or withdraws the
+// request when it was still pending. Follows that do not exist return
+// ErrNoRows.
+func (s *PostgresFollowStore) Unfollow(followerID int, followeeID int) error {
+	return s.changeFollow("delete from follows where follower_id = $1 and followee_id = $2", followerID, followeeID)
+}
+
+// ApproveFollower accepts the pending request of followerID to follow the
+// user. Requests that do not exist or were already approved return ErrNoRows.
+func (s *PostgresFollowStore) ApproveFollower(userID int, followerID int) error {
+	return s.changeFollow(
+		"update follows set status = 'approved' where follower_id = $1 and followee_id = $2 and status = 'pending'",
+		followerID, userID,
+	)
+}
+
+// RejectFollower turns down the pending request of followerID to follow the
+// user. Requests that do not exist or were already approved return ErrNoRows.
+func (s *PostgresFollowStore) RejectFollower(userID int, followerID int) error {
+	return s.changeFollow(
+		"delete from follows where follower_id = $1 and followee_id = $2 and status = 'pending'", followerID, userID,
+	)
+}
+
+// IsFollowing tells whether followerID follows followeeID with an approved
+// follow.
+func (s *PostgresFollowStore) IsFollowing(followerID int, followeeID int) (bool, error) {
+	var following bool
+
+	err := s.db.QueryRow(
+		"select exists(select 1 from follows where follower_id = $1 and followee_id = $2 and status = 'approved')",
+		followerID, followeeID,
+	).Scan(&following)
+
+	return following, err
+}
+
+// GetFollowers lists who follows the user with an approved follow, most
+// recent first.
+func (s *PostgresFollowStore) GetFollowers(userID int) ([]Follow, error) {
+	return s.getFollows(`
+		select users.id, users.username, users.name, follows.created_at
+		from follows
+		join users on users.id = follows.follower_id
+		where follows.followee_id = $1 and follows.status = 'approved'
+		order by follows.created_at desc, users.id desc
+	`, userID)
+}
+
+// GetFollowing lists who the user follows with an approved follow, most
+// recent first.
+func (s *PostgresFollowStore) GetFollowing(userID int) ([]Follow, error) {
+	return s.getFollows(`
+		select users.id, users.username, users.name, follows.created_at
+		from follows
+		join users on users.id = follows.followee_id
+		where follows.follower_id = $1 and follows.status = 'approved'
+		order by follows.created_at desc, users.id desc
+	`, userID)
+}
+
+// GetFollowRequests lists who asked to follow the user and is waiting for an
+// answer, most recent first.
+func (s *PostgresFollowStore) GetFollowRequests(userID int) ([]Follow, error) {
+	return s.getFollows(`
+		select users.id, users.username, users.name, follows.created_at
+		from follows
+		join users on users.id = follows.follower_id
+		where follows.followee_id = $1 and follows.status = 'pending'
+		order by follows.created_at desc, users.id desc
+	`, userID)
+}
+
+// changeFollow runs a statement on a single follow, returning ErrNoRows when
+// there was none to change.
+func (s *PostgresFollowStore) changeFollow(query string, followerID int, followeeID int) error {
+	result, err := s.db.Exec(query, followerID, followeeID)
+
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affectedRows == 0 {
+		return internalErrors.ErrNoRows
+	}
+
+	return nil
+}
+
+func (s *PostgresFollowStore) getFollows(query string, userID int) ([]Follow, error) {
+	follows := make([]Follow, 0)
+
+	err := scanRows(s.db, query, []any{userID}, func(rows *sql.Rows) error {
+		follow := Follow{}
+
+		if err := rows.Scan(&follow.User.ID, &follow.User.Username, &follow.User.Name, &follow.CreatedAt); err != nil {
+			return err
+		}
+
+		follows = append(follows, follow)
+
+		return nil
+	})
+
+	return follows, err
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/utils"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	john, _ := testingUtils.CreateToken(db, "johndoe")
+	jane, _ := testingUtils.CreateToken(db, "janedoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	followStore := NewPostgresFollowStore(db)
+
+	t.Run("Follow and unfollow", func(t *testing.T) {
+		status, err := followStore.Follow(john.ID, jane.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, FollowPending, status)
+		assert.Empty(t, utils.Must(followStore.GetFollowers(jane.ID)), "pending follows are not followers yet")
+		assert.False(t, utils.Must(followStore.IsFollowing(john.ID, jane.ID)))
+
+		requests := utils.Must(followStore.GetFollowRequests(jane.ID))
+		assert.Len(t, requests, 1)
+		assert.Equal(t, john.ID, requests[0].User.ID)
+
+		assert.NoError(t, followStore.ApproveFollower(jane.ID, john.ID))
+		assert.ErrorIs(t, followStore.ApproveFollower(jane.ID, john.ID), internalErrors.ErrNoRows)
+		assert.True(t, utils.Must(followStore.IsFollowing(john.ID, jane.ID)))
+		assert.Empty(t, utils.Must(followStore.GetFollowRequests(jane.ID)))
+
+		status, err = followStore.Follow(john.ID, jane.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, FollowApproved, status, "following twice keeps the approval")
+
+		followers := utils.Must(followStore.GetFollowers(jane.ID))
+		assert.Len(t, followers, 1)
+		assert.Equal(t, "johndoe", followers[0].User.Username)
+
+		following := utils.Must(followStore.GetFollowing(john.ID))
+		assert.Len(t, following, 1)
+		assert.Equal(t, jane.ID, following[0].User.ID)
+
+		assert.NoError(t, followStore.Unfollow(john.ID, jane.ID))
+		assert.ErrorIs(t, followStore.Unfollow(john.ID, jane.ID), internalErrors.ErrNoRows)
+		assert.Empty(t, utils.Must(followStore.GetFollowers(jane.ID)))
+	})
+
+	t.Run("Rejected requests are removed", func(t *testing.T) {
+		utils.Must(followStore.Follow(jane.ID, john.ID))
+
+		assert.NoError(t, followStore.RejectFollower(john.ID, jane.ID))
+		assert.ErrorIs(t, followStore.RejectFollower(john.ID, jane.ID), internalErrors.ErrNoRows)
+		assert.Empty(t, utils.Must(followStore.GetFollowRequests(john.ID)))
+	})
+
+	t.Run("Invalid follows", func(t *testing.T) {
+		_, err := followStore.Follow(john.ID, john.ID)
+		assert.ErrorIs(t, err, internalErrors.ErrCannotFollowSelf)
+
+		_, err = followStore.Follow(john.ID, jane.ID+100)
+		assert.ErrorIs(t, err, internalErrors.ErrNoRows)
+	})
+}
@@ -0,0 +1,593 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/requests"
+	"time"
+)
+
+const (
+	GoalWorkouts        = "workouts"
+	GoalCaloriesBurned  = "calories_burned"
+	GoalDurationMinutes = "duration_minutes"
+	GoalLiftWeight      = "lift_weight"
+)
+
+const (
+	GoalActive   = "active"
+	GoalAchieved = "achieved"
+	GoalFailed   = "failed"
+)
+
+// Goal is either periodic ("4 workouts per week", "2000 kcal per month"),
+// measured against the totals of every week or month, or a lift goal ("bench
+// 100 kg by December"), measured against the heaviest set of an exercise.
+type Goal struct {
+	ID         int           `json:"id"`
+	UserID     int           `json:"user_id"`
+	Title      string        `json:"title"`
+	GoalType   string        `json:"goal_type"`
+	Target     float64       `json:"target"`
+	Period     *string       `json:"period"`
+	ExerciseID *int          `json:"exercise_id"`
+	Deadline   *Date         `json:"deadline"`
+	Status     string        `json:"status"`
+	AchievedAt *time.Time    `json:"achieved_at"`
+	Progress   *GoalProgress `json:"progress"`
+	CreatedAt  *time.Time    `json:"created_at"`
+	UpdatedAt  *time.Time    `json:"updated_at"`
+}
+
+// GoalProgress is computed on every evaluation. For periodic goals it refers
+// to the current period, or to the last one once the deadline has passed.
+type GoalProgress struct {
+	Current         float64 `json:"current"`
+	PercentComplete float64 `json:"percent_complete"`
+	Projected       float64 `json:"projected"`
+	OnTrack         bool    `json:"on_track"`
+	CurrentStreak   int     `json:"current_streak"`
+	LongestStreak   int     `json:"longest_streak"`
+	PeriodStart     *Date   `json:"period_start,omitempty"`
+	PeriodEnd       *Date   `json:"period_end,omitempty"`
+}
+
+func (g *Goal) IsPeriodic() bool {
+	return g.GoalType != GoalLiftWeight
+}
+
+func (g *Goal) FromGoalRequest(goalRequest *requests.GoalRequest) *Goal {
+	g.Title = goalRequest.Title
+	g.GoalType = goalRequest.GoalType
+	g.Target = goalRequest.Target
+	g.Period = nil
+	g.ExerciseID = goalRequest.ExerciseID
+	g.Deadline = nil
+
+	if goalRequest.Period != "" {
+		g.Period = &goalRequest.Period
+	}
+
+	if goalRequest.Deadline != "" {
+		deadline, _ := ParseDate(goalRequest.Deadline)
+		g.Deadline = &deadline
+	}
+
+	return g
+}
+
+type GoalStore interface {
+	CreateGoal(goal *Goal) (*Goal, error)
+	UpdateGoal(id int, goal *Goal) (*Goal, error)
+	GetGoalById(id int) (*Goal, error)
+	DeleteGoal(id int) error
+	GetAllGoals(userID int) ([]Goal, error)
+	OwnsGoal(id int, userID int) (bool, error)
+}
+
+type PostgresGoalStore struct {
+	db *sql.DB
+}
+
+func NewPostgresGoalStore(db *sql.DB) *PostgresGoalStore {
+	return &PostgresGoalStore{
+		db: db,
+	}
+}
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx, so goals can be evaluated
+// inside the transaction of a workout write.
+type dbtx interface {
+	queryer
+	QueryRow(query string, args ...any) *sql.Row
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+const goalColumns = `id, user_id, title, goal_type, target, period, exercise_id, deadline, status, achieved_at, created_at, updated_at`
+
+func scanGoal(scanner interface{ Scan(dest ...any) error }, goal *Goal) error {
+	return scanner.Scan(
+		&goal.ID,
+		&goal.UserID,
+		&goal.Title,
+		&goal.GoalType,
+		&goal.Target,
+		&goal.Period,
+		&goal.ExerciseID,
+		&goal.Deadline,
+		&goal.Status,
+		&goal.AchievedAt,
+		&goal.CreatedAt,
+		&goal.UpdatedAt,
+	)
+}
+
+func (s *PostgresGoalStore) CreateGoal(goal *Goal) (*Goal, error) {
+	query := `
+		insert into goals (user_id, title, goal_type, target, period, exercise_id, deadline)
+		values ($1, $2, $3, $4, $5, $6, $7)
+		returning id, status, created_at, updated_at
+	`
+
+	err := s.db.QueryRow(
+		query,
+		goal.UserID,
+		goal.Title,
+		goal.GoalType,
+		goal.Target,
+		goal.Period,
+		goal.ExerciseID,
+		goal.Deadline).Scan(&goal.ID, &goal.Status, &goal.CreatedAt, &goal.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	goals := []Goal{*goal}
+	err = refreshGoalsOf(s.db, goal.UserID, goals)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &goals[0], nil
+}
+
+func (s *PostgresGoalStore) UpdateGoal(id int, goal *Goal) (*Goal, error) {
+	query := `
+		update goals
+		set title = $2, goal_type = $3, target = $4, period = $5, exercise_id = $6, deadline = $7, updated_at = now()
+		where id = $1
+		returning user_id, status, achieved_at, created_at, updated_at
+	`
+
+	goal.ID = id
+
+	err := s.db.QueryRow(
+		query,
+		id,
+		goal.Title,
+		goal.GoalType,
+		goal.Target,
+		goal.Period,
+		goal.ExerciseID,
+		goal.Deadline).Scan(&goal.UserID, &goal.Status, &goal.AchievedAt, &goal.CreatedAt, &goal.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	goals := []Goal{*goal}
+	err = refreshGoalsOf(s.db, goal.UserID, goals)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &goals[0], nil
+}
+
+func (s *PostgresGoalStore) GetGoalById(id int) (*Goal, error) {
+	goal := &Goal{}
+	query := fmt.Sprintf(`select %s from goals where id = $1`, goalColumns)
+
+	err := scanGoal(s.db.QueryRow(query, id), goal)
+
+	if err != nil {
+		return nil, err
+	}
+
+	goals := []Goal{*goal}
+	err = evaluateGoals(s.db, goal.UserID, goals, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &goals[0], nil
+}
+
+func (s *PostgresGoalStore) DeleteGoal(id int) error {
+	result, err := s.db.Exec("delete from goals where id = $1", id)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return internalErrors.ErrNoRows
+	}
+
+	return nil
+}
+
+func (s *PostgresGoalStore) GetAllGoals(userID int) ([]Goal, error) {
+	goals, err := loadUserGoals(s.db, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = evaluateGoals(s.db, userID, goals, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return goals, nil
+}
+
+func (s *PostgresGoalStore) OwnsGoal(id int, userID int) (bool, error) {
+	var exists bool
+	query := `select exists(select 1 from goals where id = $1 and user_id = $2)`
+
+	err := s.db.QueryRow(query, id, userID).Scan(&exists)
+
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func loadUserGoals(q queryer, userID int) ([]Goal, error) {
+	query := fmt.Sprintf(`select %s from goals where user_id = $1 order by created_at, id`, goalColumns)
+
+	rows, err := q.Query(query, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	goals := make([]Goal, 0)
+
+	for rows.Next() {
+		goal := Goal{}
+
+		if err := scanGoal(rows, &goal); err != nil {
+			return nil, err
+		}
+
+		goals = append(goals, goal)
+	}
+
+	return goals, rows.Err()
+}
+
+// refreshGoals re-evaluates every goal of the user and stores the resulting
+// status. Workout writes call it inside their transaction.
+func refreshGoals(q dbtx, userID int) error {
+	goals, err := loadUserGoals(q, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return refreshGoalsOf(q, userID, goals)
+}
+
+// refreshGoalsOf evaluates the given goals in place and stores their status.
+func refreshGoalsOf(q dbtx, userID int, goals []Goal) error {
+	err := evaluateGoals(q, userID, goals, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	for _, goal := range goals {
+		_, err := q.Exec(
+			`update goals set status = $2, achieved_at = $3, evaluated_at = now() where id = $1`,
+			goal.ID,
+			goal.Status,
+			goal.AchievedAt,
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type liftPoint struct {
+	At     time.Time
+	Weight float64
+}
+
+// evaluateGoals loads the workout data the goals depend on, in the user's
+// time zone, and fills their progress and status.
+func evaluateGoals(q dbtx, userID int, goals []Goal, now time.Time) error {
+	if len(goals) == 0 {
+		return nil
+	}
+
+	var timeZone string
+	err := q.QueryRow(`select time_zone from users where id = $1`, userID).Scan(&timeZone)
+
+	if err != nil {
+		return err
+	}
+
+	location, err := time.LoadLocation(timeZone)
+
+	if err != nil {
+		location = time.UTC
+	}
+
+	periodTotals := make(map[string]map[string]SummaryTotals)
+	liftHistory := make(map[int][]liftPoint)
+
+	for i := range goals {
+		goal := &goals[i]
+
+		if goal.IsPeriodic() {
+			if _, ok := periodTotals[*goal.Period]; !ok {
+				periodTotals[*goal.Period], err = loadPeriodTotals(q, userID, *goal.Period, location.String())
+
+				if err != nil {
+					return err
+				}
+			}
+
+			evaluatePeriodicGoal(goal, periodTotals[*goal.Period], now.In(location))
+			continue
+		}
+
+		if _, ok := liftHistory[*goal.ExerciseID]; !ok {
+			liftHistory[*goal.ExerciseID], err = loadLiftHistory(q, userID, *goal.ExerciseID)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		evaluateLiftGoal(goal, liftHistory[*goal.ExerciseID], now.In(location))
+	}
+
+	return nil
+}
+
+func loadPeriodTotals(q queryer, userID int, period string, timeZone string) (map[string]SummaryTotals, error) {
+	query := `
+		select date_trunc($2, workouts.created_at at time zone $3) as period_start,
+		       count(*),
+		       coalesce(sum(duration_minutes), 0),
+		       coalesce(sum(calories_burned), 0)
+		from workouts
+		where user_id = $1 and deleted_at is null and status = 'completed'
+		group by period_start
+	`
+
+	totals := make(map[string]SummaryTotals)
+
+	err := scanRows(q, query, []any{userID, period, timeZone}, func(rows *sql.Rows) error {
+		var start time.Time
+		periodTotals := SummaryTotals{}
+
+		err := rows.Scan(&start, &periodTotals.WorkoutCount, &periodTotals.TotalDurationMinutes, &periodTotals.TotalCaloriesBurned)
+
+		if err != nil {
+			return err
+		}
+
+		totals[NewDate(start).String()] = periodTotals
+
+		return nil
+	})
+
+	return totals, err
+}
+
+func loadLiftHistory(q queryer, userID int, exerciseID int) ([]liftPoint, error) {
+	query := `
+		select workouts.created_at, max(workout_sets.weight)
+		from workout_sets
+		join workout_entries on workout_entries.id = workout_sets.workout_entry_id
+		join workouts on workouts.id = workout_entries.workout_id
+		where workouts.user_id = $1
+		  and workouts.deleted_at is null
+		  and workouts.status = 'completed'
+		  and workout_entries.exercise_id = $2
+		  and workout_sets.completed
+		  and workout_sets.set_type <> 'warm_up'
+		  and workout_sets.reps > 0
+		group by workouts.id, workouts.created_at
+		order by workouts.created_at, workouts.id
+	`
+
+	history := make([]liftPoint, 0)
+
+	err := scanRows(q, query, []any{userID, exerciseID}, func(rows *sql.Rows) error {
+		point := liftPoint{}
+
+		if err := rows.Scan(&point.At, &point.Weight); err != nil {
+			return err
+		}
+
+		history = append(history, point)
+
+		return nil
+	})
+
+	return history, err
+}
+
+func (t SummaryTotals) goalValue(goalType string) float64 {
+	switch goalType {
+	case GoalCaloriesBurned:
+		return float64(t.TotalCaloriesBurned)
+	case GoalDurationMinutes:
+		return float64(t.TotalDurationMinutes)
+	default:
+		return float64(t.WorkoutCount)
+	}
+}
+
+// evaluatePeriodicGoal walks every period since the goal was created. A period
+// counts for the streak when its total reaches the target; the period still in
+// progress only extends the streak, never breaks it. now must be in the
+// user's time zone.
+func evaluatePeriodicGoal(goal *Goal, totals map[string]SummaryTotals, now time.Time) {
+	today := NewDate(now)
+	period := *goal.Period
+	first := PeriodStart(NewDate(goal.CreatedAt.In(now.Location())), period)
+	last := PeriodStart(today, period)
+	deadlinePassed := goal.Deadline != nil && goal.Deadline.Before(today.Time)
+
+	if deadlinePassed {
+		last = PeriodStart(*goal.Deadline, period)
+	}
+
+	if last.Before(first.Time) {
+		last = first
+	}
+
+	progress := &GoalProgress{}
+	run := 0
+
+	for start := first; !start.After(last.Time); start = AddPeriods(start, period, 1) {
+		met := totals[start.String()].goalValue(goal.GoalType) >= goal.Target
+
+		if met {
+			run++
+			progress.LongestStreak = max(progress.LongestStreak, run)
+		}
+
+		if start.Equal(last.Time) && (met || !deadlinePassed) {
+			progress.CurrentStreak = run
+		}
+
+		if !met {
+			run = 0
+		}
+	}
+
+	end := Date{AddPeriods(last, period, 1).AddDate(0, 0, -1)}
+	progress.PeriodStart = &last
+	progress.PeriodEnd = &end
+	progress.Current = totals[last.String()].goalValue(goal.GoalType)
+	progress.Projected = progress.Current
+
+	if !deadlinePassed {
+		periodLength := AddPeriods(last, period, 1).Sub(last.Time)
+		elapsed := wallClock(now).Sub(last.Time)
+
+		if elapsed > 0 {
+			progress.Projected = progress.Current * float64(periodLength) / float64(elapsed)
+		}
+	}
+
+	progress.PercentComplete = percentOf(progress.Current, goal.Target)
+	progress.OnTrack = progress.Current >= goal.Target || progress.Projected >= goal.Target
+	goal.Progress = progress
+	goal.AchievedAt = nil
+	goal.Status = GoalActive
+
+	if deadlinePassed {
+		goal.Status = GoalFailed
+
+		if progress.Current >= goal.Target {
+			goal.Status = GoalAchieved
+		}
+	}
+}
+
+// evaluateLiftGoal projects the heaviest set linearly: the gain since the goal
+// was created (or since the first session after it) is extended until the
+// deadline. now must be in the user's time zone.
+func evaluateLiftGoal(goal *Goal, history []liftPoint, now time.Time) {
+	progress := &GoalProgress{}
+	goal.AchievedAt = nil
+
+	baseline := 0.0
+	baselineAt := *goal.CreatedAt
+	hasBaseline := false
+
+	for _, point := range history {
+		if point.At.Before(*goal.CreatedAt) {
+			baseline = max(baseline, point.Weight)
+			hasBaseline = true
+		} else if !hasBaseline {
+			baseline = point.Weight
+			baselineAt = point.At
+			hasBaseline = true
+		}
+
+		progress.Current = max(progress.Current, point.Weight)
+
+		if goal.AchievedAt == nil && progress.Current >= goal.Target {
+			achievedAt := point.At
+			goal.AchievedAt = &achievedAt
+		}
+	}
+
+	today := NewDate(now)
+	deadlinePassed := goal.Deadline != nil && goal.Deadline.Before(today.Time)
+	progress.Projected = progress.Current
+	elapsedDays := now.Sub(baselineAt).Hours() / 24
+
+	if goal.Deadline != nil && !deadlinePassed && elapsedDays >= 1 {
+		dailyGain := (progress.Current - baseline) / elapsedDays
+		remainingDays := goal.Deadline.AddDate(0, 0, 1).Sub(wallClock(now)).Hours() / 24
+		progress.Projected = progress.Current + max(dailyGain, 0)*remainingDays
+	}
+
+	progress.PercentComplete = percentOf(progress.Current, goal.Target)
+	progress.OnTrack = progress.Current >= goal.Target || (goal.Deadline != nil && progress.Projected >= goal.Target)
+	goal.Progress = progress
+
+	switch {
+	case goal.AchievedAt != nil:
+		goal.Status = GoalAchieved
+	case deadlinePassed:
+		goal.Status = GoalFailed
+	default:
+		goal.Status = GoalActive
+	}
+}
+
+// wallClock keeps the local date and time of t but labels it UTC, matching
+// the Date values periods are built from.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+func percentOf(value, target float64) float64 {
+	if target <= 0 {
+		return 0
+	}
+
+	return min(100, float64(int(value/target*10000+0.5))/100)
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	"partiuFit/internal/utils"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoalStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	workoutStore := NewPostgresWorkoutStore(db)
+	goalStore := NewPostgresGoalStore(db)
+	exerciseStore := NewPostgresExerciseStore(db)
+	bench := utils.Must(exerciseStore.FindExerciseByName(user.ID, "Bench Press"))
+
+	weekly := &Goal{UserID: user.ID, Title: "Train twice a week", GoalType: GoalWorkouts, Target: 2, Period: utils.ValueToPointer(BucketWeek)}
+	lift := &Goal{UserID: user.ID, Title: "Bench 100 kg", GoalType: GoalLiftWeight, Target: 100, ExerciseID: &bench.ID}
+
+	t.Run("Create goals", func(t *testing.T) {
+		var err error
+		weekly, err = goalStore.CreateGoal(weekly)
+		assert.NoError(t, err)
+		assert.Equal(t, GoalActive, weekly.Status)
+		assert.Zero(t, weekly.Progress.Current)
+
+		lift, err = goalStore.CreateGoal(lift)
+		assert.NoError(t, err)
+		assert.Equal(t, GoalActive, lift.Status)
+	})
+
+	t.Run("Workout writes re-evaluate goals", func(t *testing.T) {
+		for _, weight := range []float64{90, 100} {
+			_, err := workoutStore.CreateWorkout(&Workout{
+				Title:           "Bench day",
+				DurationMinutes: 45,
+				CaloriesBurned:  300,
+				UserID:          user.ID,
+				Entries: []WorkoutEntry{{
+					ExerciseID:   &bench.ID,
+					ExerciseName: "Bench Press",
+					OrderIndex:   1,
+					UserID:       user.ID,
+					WorkoutSets:  []WorkoutSet{{SetType: SetTypeWorking, Reps: utils.ValueToPointer(1), Weight: weight, Completed: true}},
+				}},
+			})
+			assert.NoError(t, err)
+		}
+
+		var status string
+		utils.MustIfError(db.QueryRow("select status from goals where id = $1", lift.ID).Scan(&status))
+		assert.Equal(t, GoalAchieved, status)
+
+		goal, err := goalStore.GetGoalById(weekly.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2.0, goal.Progress.Current)
+		assert.Equal(t, 100.0, goal.Progress.PercentComplete)
+		assert.Equal(t, 1, goal.Progress.CurrentStreak)
+	})
+
+	t.Run("Deleting workouts reverts achievements", func(t *testing.T) {
+		workouts, _, err := workoutStore.GetAllWorkouts(user.ID, WorkoutFilters{})
+		assert.NoError(t, err)
+
+		for _, workout := range workouts {
+			if workout.Title == "Bench day" {
+				assert.NoError(t, workoutStore.DeleteWorkout(workout.ID))
+			}
+		}
+
+		goals, err := goalStore.GetAllGoals(user.ID)
+		assert.NoError(t, err)
+		for _, goal := range goals {
+			assert.Equal(t, GoalActive, goal.Status)
+		}
+	})
+}
+
+func TestEvaluatePeriodicGoal(t *testing.T) {
+	createdAt := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	totals := map[string]SummaryTotals{
+		"2025-09-01": {WorkoutCount: 4},
+		"2025-09-08": {WorkoutCount: 2},
+		"2025-09-15": {WorkoutCount: 4},
+		"2025-09-22": {WorkoutCount: 5},
+		"2025-09-29": {WorkoutCount: 1},
+	}
+	goal := &Goal{GoalType: GoalWorkouts, Target: 4, Period: utils.ValueToPointer(BucketWeek), CreatedAt: &createdAt}
+
+	t.Run("Current period in progress", func(t *testing.T) {
+		// Wednesday noon: 2.5 of 7 days elapsed
+		evaluatePeriodicGoal(goal, totals, time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC))
+
+		assert.Equal(t, GoalActive, goal.Status)
+		assert.Equal(t, 1.0, goal.Progress.Current)
+		assert.Equal(t, 25.0, goal.Progress.PercentComplete)
+		assert.InDelta(t, 2.8, goal.Progress.Projected, 0.01)
+		assert.False(t, goal.Progress.OnTrack)
+		assert.Equal(t, 2, goal.Progress.CurrentStreak, "the week in progress does not break the streak")
+		assert.Equal(t, 2, goal.Progress.LongestStreak)
+		assert.Equal(t, "2025-10-05", goal.Progress.PeriodEnd.String())
+	})
+
+	t.Run("Deadline decides the final status", func(t *testing.T) {
+		deadline := utils.Must(ParseDate("2025-09-24"))
+		goal.Deadline = &deadline
+		evaluatePeriodicGoal(goal, totals, time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC))
+
+		assert.Equal(t, GoalAchieved, goal.Status)
+		assert.Equal(t, "2025-09-22", goal.Progress.PeriodStart.String())
+		assert.Equal(t, 5.0, goal.Progress.Current)
+	})
+}
+
+func TestEvaluateLiftGoal(t *testing.T) {
+	createdAt := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	deadline := utils.Must(ParseDate("2025-12-31"))
+	goal := &Goal{GoalType: GoalLiftWeight, Target: 100, CreatedAt: &createdAt, Deadline: &deadline}
+	history := []liftPoint{
+		{At: time.Date(2025, 8, 20, 0, 0, 0, 0, time.UTC), Weight: 80},
+		{At: time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC), Weight: 85},
+		{At: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), Weight: 90},
+	}
+
+	t.Run("Projects the gain since the goal was created", func(t *testing.T) {
+		evaluateLiftGoal(goal, history, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC))
+
+		assert.Equal(t, GoalActive, goal.Status)
+		assert.Equal(t, 90.0, goal.Progress.Current)
+		assert.Equal(t, 90.0, goal.Progress.PercentComplete)
+		// 10 kg in 30 days, 92 days left until the end of the deadline
+		assert.InDelta(t, 90+10.0/30*92, goal.Progress.Projected, 0.01)
+		assert.True(t, goal.Progress.OnTrack)
+	})
+
+	t.Run("Achieved with the first set at the target", func(t *testing.T) {
+		reached := append(history, liftPoint{At: time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC), Weight: 100})
+		evaluateLiftGoal(goal, reached, time.Date(2025, 10, 21, 0, 0, 0, 0, time.UTC))
+
+		assert.Equal(t, GoalAchieved, goal.Status)
+		assert.Equal(t, reached[3].At, *goal.AchievedAt)
+	})
+
+	t.Run("Failed after the deadline", func(t *testing.T) {
+		evaluateLiftGoal(goal, history, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC))
+
+		assert.Equal(t, GoalFailed, goal.Status)
+		assert.False(t, goal.Progress.OnTrack)
+	})
+}
@@ -0,0 +1,382 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"partiuFit/internal/fitness"
+	"time"
+)
+
+const (
+	RecordHeaviestWeight = "heaviest_weight"
+	RecordMaxReps        = "max_reps"
+	RecordEstimated1RM   = "estimated_1rm"
+	RecordBestVolume     = "best_volume"
+)
+
+// PersonalRecord is a record broken by a workout entry. Every time a record
+// is beaten a new row is kept, so the latest row of each type is the current best.
+type PersonalRecord struct {
+	ID             int       `json:"id"`
+	UserID         int       `json:"user_id"`
+	ExerciseID     *int      `json:"exercise_id"`
+	ExerciseName   string    `json:"exercise_name"`
+	RecordType     string    `json:"record_type"`
+	Value          float64   `json:"value"`
+	Weight         *float64  `json:"weight"`
+	Reps           *int      `json:"reps"`
+	WorkoutID      int       `json:"workout_id"`
+	WorkoutEntryID int       `json:"workout_entry_id"`
+	WorkoutSetID   *int      `json:"workout_set_id"`
+	AchievedAt     time.Time `json:"achieved_at"`
+
+	exerciseKey string
+}
+
+type PersonalRecordStore interface {
+	GetPersonalRecords(userID int, exerciseID *int) ([]PersonalRecord, error)
+}
+
+type PostgresPersonalRecordStore struct {
+	db *sql.DB
+}
+
+func NewPostgresPersonalRecordStore(db *sql.DB) *PostgresPersonalRecordStore {
+	return &PostgresPersonalRecordStore{
+		db: db,
+	}
+}
+
+const personalRecordColumns = `id, user_id, exercise_id, exercise_name, record_type, value, weight, reps, workout_id, workout_entry_id, workout_set_id, achieved_at`
+
+// exerciseKeyExpression identifies an exercise by its catalog id, falling back
+// to the normalized name for entries that could not be linked to the catalog.
+const exerciseKeyExpression = `coalesce(workout_entries.exercise_id::text, 'name:' || lower(trim(workout_entries.exercise_name)))`
+
+func scanPersonalRecords(rows *sql.Rows) ([]PersonalRecord, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	records := make([]PersonalRecord, 0)
+
+	for rows.Next() {
+		record := PersonalRecord{}
+
+		err := rows.Scan(
+			&record.ID,
+			&record.UserID,
+			&record.ExerciseID,
+			&record.ExerciseName,
+			&record.RecordType,
+			&record.Value,
+			&record.Weight,
+			&record.Reps,
+			&record.WorkoutID,
+			&record.WorkoutEntryID,
+			&record.WorkoutSetID,
+			&record.AchievedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// GetPersonalRecords returns the current best of every record type, one per weight for max_reps.
+func (s *PostgresPersonalRecordStore) GetPersonalRecords(userID int, exerciseID *int) ([]PersonalRecord, error) {
+	query := fmt.Sprintf(`
+		select %s
+		from (
+			select distinct on (exercise_key, record_type, case when record_type = 'max_reps' then weight end) *
+			from personal_records
+			where user_id = $1 and ($2::integer is null or exercise_id = $2)
+			order by exercise_key, record_type, case when record_type = 'max_reps' then weight end, achieved_at desc, id desc
+		) current_records
+		order by exercise_name, record_type, weight
+	`, personalRecordColumns)
+
+	rows, err := s.db.Query(query, userID, exerciseID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPersonalRecords(rows)
+}
+
+// getWorkoutPersonalRecords returns the records earned by the entries of a workout.
+func getWorkoutPersonalRecords(tx *sql.Tx, workoutID int) ([]PersonalRecord, error) {
+	query := fmt.Sprintf(`
+		select %s
+		from personal_records
+		where workout_id = $1
+		order by workout_entry_id, record_type, weight
+	`, personalRecordColumns)
+
+	rows, err := tx.Query(query, workoutID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPersonalRecords(rows)
+}
+
+// workoutExerciseKeys lists the exercises touched by a workout, so their records can be recomputed.
+func workoutExerciseKeys(tx *sql.Tx, workoutID int) ([]string, error) {
+	query := fmt.Sprintf(`
+		select distinct %s
+		from workout_entries
+		where workout_id = $1
+	`, exerciseKeyExpression)
+
+	rows, err := tx.Query(query, workoutID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	keys := make([]string, 0)
+
+	for rows.Next() {
+		var key string
+
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// userExerciseKeys lists every exercise the user has logged.
+func userExerciseKeys(tx *sql.Tx, userID int) ([]string, error) {
+	query := fmt.Sprintf(`
+		select distinct %s
+		from workout_entries
+		join workouts on workouts.id = workout_entries.workout_id
+		where workouts.user_id = $1
+	`, exerciseKeyExpression)
+
+	keys := make([]string, 0)
+
+	err := scanRows(tx, query, []any{userID}, func(rows *sql.Rows) error {
+		var key string
+
+		if err := rows.Scan(&key); err != nil {
+			return err
+		}
+
+		keys = append(keys, key)
+
+		return nil
+	})
+
+	return keys, err
+}
+
+type recordSet struct {
+	exerciseKey  string
+	exerciseID   *int
+	exerciseName string
+	workoutID    int
+	entryID      int
+	setID        int
+	reps         int
+	weight       float64
+	achievedAt   time.Time
+}
+
+// recomputePersonalRecords rebuilds the record history of the given exercises
+// from every completed, non warm-up set of the user in chronological order.
+// It runs inside the transaction that changed the workouts, so creating,
+// editing or deleting a workout always leaves the records consistent.
+func recomputePersonalRecords(tx *sql.Tx, userID int, exerciseKeys []string) error {
+	if len(exerciseKeys) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(`delete from personal_records where user_id = $1 and exercise_key = any($2)`, userID, exerciseKeys)
+
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		select %s, workout_entries.exercise_id, workout_entries.exercise_name, workouts.id, workout_entries.id,
+		       workout_sets.id, workout_sets.reps, workout_sets.weight, workouts.created_at
+		from workout_sets
+		join workout_entries on workout_entries.id = workout_sets.workout_entry_id
+		join workouts on workouts.id = workout_entries.workout_id
+		where workouts.user_id = $1
+		  and workouts.deleted_at is null
+		  and workouts.status = 'completed'
+		  and %s = any($2)
+		  and workout_sets.completed
+		  and workout_sets.set_type <> 'warm_up'
+		  and workout_sets.reps > 0
+		order by workouts.created_at, workouts.id, workout_entries.order_index, workout_entries.id, workout_sets.set_number
+	`, exerciseKeyExpression, exerciseKeyExpression)
+
+	rows, err := tx.Query(query, userID, exerciseKeys)
+
+	if err != nil {
+		return err
+	}
+
+	sets := make([]recordSet, 0)
+
+	for rows.Next() {
+		set := recordSet{}
+
+		err := rows.Scan(
+			&set.exerciseKey,
+			&set.exerciseID,
+			&set.exerciseName,
+			&set.workoutID,
+			&set.entryID,
+			&set.setID,
+			&set.reps,
+			&set.weight,
+			&set.achievedAt,
+		)
+
+		if err != nil {
+			_ = rows.Close()
+			return err
+		}
+
+		sets = append(sets, set)
+	}
+
+	_ = rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, record := range detectPersonalRecords(userID, sets) {
+		err := insertPersonalRecord(tx, &record)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type exerciseBests struct {
+	heaviestWeight float64
+	estimated1RM   float64
+	bestVolume     float64
+	maxRepsAt      map[float64]int
+}
+
+// detectPersonalRecords walks the sets in chronological order and emits a
+// record every time a previous best is strictly beaten. Volume is compared per
+// entry, so it is evaluated when the sets of an entry end.
+func detectPersonalRecords(userID int, sets []recordSet) []PersonalRecord {
+	records := make([]PersonalRecord, 0)
+	bests := make(map[string]*exerciseBests)
+
+	newRecord := func(set recordSet, recordType string, value float64) PersonalRecord {
+		return PersonalRecord{
+			UserID:         userID,
+			ExerciseID:     set.exerciseID,
+			ExerciseName:   set.exerciseName,
+			RecordType:     recordType,
+			Value:          value,
+			WorkoutID:      set.workoutID,
+			WorkoutEntryID: set.entryID,
+			AchievedAt:     set.achievedAt,
+			exerciseKey:    set.exerciseKey,
+		}
+	}
+
+	entryVolume := 0.0
+
+	for i, set := range sets {
+		best, ok := bests[set.exerciseKey]
+
+		if !ok {
+			best = &exerciseBests{maxRepsAt: make(map[float64]int)}
+			bests[set.exerciseKey] = best
+		}
+
+		weight, reps, setID := set.weight, set.reps, set.setID
+
+		if set.weight > best.heaviestWeight {
+			best.heaviestWeight = set.weight
+			record := newRecord(set, RecordHeaviestWeight, weight)
+			record.Weight, record.Reps, record.WorkoutSetID = &weight, &reps, &setID
+			records = append(records, record)
+		}
+
+		if set.reps > best.maxRepsAt[set.weight] {
+			best.maxRepsAt[set.weight] = set.reps
+			record := newRecord(set, RecordMaxReps, float64(reps))
+			record.Weight, record.Reps, record.WorkoutSetID = &weight, &reps, &setID
+			records = append(records, record)
+		}
+
+		if estimated := fitness.EstimateOneRepMax(set.weight, set.reps, fitness.FormulaEpley); estimated > best.estimated1RM {
+			best.estimated1RM = estimated
+			record := newRecord(set, RecordEstimated1RM, estimated)
+			record.Weight, record.Reps, record.WorkoutSetID = &weight, &reps, &setID
+			records = append(records, record)
+		}
+
+		entryVolume += set.weight * float64(set.reps)
+		lastOfEntry := i == len(sets)-1 || sets[i+1].entryID != set.entryID
+
+		if lastOfEntry {
+			if entryVolume > best.bestVolume {
+				best.bestVolume = entryVolume
+				records = append(records, newRecord(set, RecordBestVolume, entryVolume))
+			}
+
+			entryVolume = 0
+		}
+	}
+
+	return records
+}
+
+func insertPersonalRecord(tx *sql.Tx, record *PersonalRecord) error {
+	query := `
+		insert into personal_records (
+			user_id, exercise_key, exercise_id, exercise_name, record_type, value, weight, reps,
+			workout_id, workout_entry_id, workout_set_id, achieved_at
+		)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		returning id
+	`
+
+	return tx.QueryRow(
+		query,
+		record.UserID,
+		record.exerciseKey,
+		record.ExerciseID,
+		record.ExerciseName,
+		record.RecordType,
+		record.Value,
+		record.Weight,
+		record.Reps,
+		record.WorkoutID,
+		record.WorkoutEntryID,
+		record.WorkoutSetID,
+		record.AchievedAt).Scan(&record.ID)
+}
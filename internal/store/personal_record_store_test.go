@@ -0,0 +1,117 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	"partiuFit/internal/utils"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersonalRecordStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	workoutStore := NewPostgresWorkoutStore(db)
+	recordStore := NewPostgresPersonalRecordStore(db)
+
+	benchWorkout := func(title string, sets ...WorkoutSet) *Workout {
+		return &Workout{
+			Title:           title,
+			Description:     "Bench day",
+			DurationMinutes: 30,
+			CaloriesBurned:  200,
+			UserID:          user.ID,
+			Entries: []WorkoutEntry{
+				{ExerciseName: "Bench Press", OrderIndex: 1, UserID: user.ID, WorkoutSets: sets},
+			},
+		}
+	}
+
+	countByType := func(records []PersonalRecord) map[string]int {
+		counts := make(map[string]int)
+
+		for _, record := range records {
+			counts[record.RecordType]++
+		}
+
+		return counts
+	}
+
+	t.Run("First session sets the baseline records", func(t *testing.T) {
+		workout, err := workoutStore.CreateWorkout(benchWorkout("Week 1",
+			WorkoutSet{SetType: SetTypeWarmUp, Reps: utils.ValueToPointer(10), Weight: 100, Completed: true},
+			WorkoutSet{SetType: SetTypeWorking, Reps: utils.ValueToPointer(5), Weight: 80, Completed: true},
+		))
+
+		assert.NoError(t, err)
+		counts := countByType(workout.PersonalRecords)
+		assert.Equal(t, 1, counts[RecordHeaviestWeight], "warm-up sets never count")
+		assert.Equal(t, 80.0, workout.PersonalRecords[0].Value)
+	})
+
+	t.Run("Only beaten records are returned", func(t *testing.T) {
+		workout, err := workoutStore.CreateWorkout(benchWorkout("Week 2",
+			WorkoutSet{SetType: SetTypeWorking, Reps: utils.ValueToPointer(4), Weight: 80, Completed: true},
+			WorkoutSet{SetType: SetTypeWorking, Reps: utils.ValueToPointer(3), Weight: 85, Completed: true},
+			WorkoutSet{SetType: SetTypeFailure, Reps: utils.ValueToPointer(1), Weight: 95, Completed: false},
+		))
+
+		assert.NoError(t, err)
+		counts := countByType(workout.PersonalRecords)
+		assert.Equal(t, 1, counts[RecordHeaviestWeight])
+		assert.Equal(t, 1, counts[RecordMaxReps], "only 85kg is a new rep record")
+		assert.Equal(t, 1, counts[RecordEstimated1RM], "85x3 estimates 93.5kg against 93.3kg from 80x5")
+		assert.Equal(t, 1, counts[RecordBestVolume])
+	})
+
+	t.Run("Current records", func(t *testing.T) {
+		records, err := recordStore.GetPersonalRecords(user.ID, nil)
+
+		assert.NoError(t, err)
+		for _, record := range records {
+			if record.RecordType == RecordHeaviestWeight {
+				assert.Equal(t, 85.0, record.Value)
+			}
+		}
+	})
+
+	t.Run("Deleting a workout recomputes its records", func(t *testing.T) {
+		workouts, _, err := workoutStore.GetAllWorkouts(user.ID, WorkoutFilters{Search: "Week 2"})
+		assert.NoError(t, err)
+
+		assert.NoError(t, workoutStore.DeleteWorkout(workouts[0].ID))
+
+		records := utils.Must(recordStore.GetPersonalRecords(user.ID, nil))
+		for _, record := range records {
+			if record.RecordType == RecordHeaviestWeight {
+				assert.Equal(t, 80.0, record.Value)
+			}
+		}
+	})
+}
+
+func TestDetectPersonalRecords(t *testing.T) {
+	now := time.Now()
+	sets := []recordSet{
+		{exerciseKey: "1", workoutID: 1, entryID: 1, setID: 1, reps: 5, weight: 100, achievedAt: now},
+		{exerciseKey: "1", workoutID: 1, entryID: 1, setID: 2, reps: 5, weight: 100, achievedAt: now},
+		{exerciseKey: "2", workoutID: 1, entryID: 2, setID: 3, reps: 10, weight: 20, achievedAt: now},
+		{exerciseKey: "1", workoutID: 2, entryID: 3, setID: 4, reps: 8, weight: 100, achievedAt: now},
+	}
+
+	records := detectPersonalRecords(1, sets)
+
+	byEntry := make(map[int][]string)
+	for _, record := range records {
+		byEntry[record.WorkoutEntryID] = append(byEntry[record.WorkoutEntryID], record.RecordType)
+	}
+
+	assert.ElementsMatch(t, []string{RecordHeaviestWeight, RecordMaxReps, RecordEstimated1RM, RecordBestVolume}, byEntry[1])
+	assert.ElementsMatch(t, []string{RecordHeaviestWeight, RecordMaxReps, RecordEstimated1RM, RecordBestVolume}, byEntry[2])
+	assert.ElementsMatch(t, []string{RecordMaxReps, RecordEstimated1RM}, byEntry[3], "800kg of volume does not beat 1000kg")
+}
@@ -0,0 +1,433 @@
+package store
+
+import (
+	"database/sql"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/requests"
+	"partiuFit/internal/rrule"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	OccurrencePlanned   = "planned"
+	OccurrenceCompleted = "completed"
+	OccurrenceMissed    = "missed"
+)
+
+// ScheduledWorkout plans a template or an ad-hoc workout on StartsOn and, when
+// RRule is set, on every following date of the RFC 5545 recurrence.
+type ScheduledWorkout struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	TemplateID *int       `json:"template_id"`
+	Title      string     `json:"title"`
+	Notes      string     `json:"notes"`
+	StartsOn   Date       `json:"starts_on"`
+	RRule      *string    `json:"rrule"`
+	CreatedAt  *time.Time `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+type ScheduledOccurrence struct {
+	ScheduleID int    `json:"schedule_id"`
+	TemplateID *int   `json:"template_id"`
+	Title      string `json:"title"`
+	Date       Date   `json:"date"`
+	Status     string `json:"status"`
+	WorkoutID  *int   `json:"workout_id"`
+}
+
+func (s *ScheduledWorkout) FromScheduleRequest(scheduleRequest *requests.ScheduledWorkoutRequest) *ScheduledWorkout {
+	s.TemplateID = scheduleRequest.TemplateID
+	s.Title = scheduleRequest.Title
+	s.Notes = scheduleRequest.Notes
+	s.StartsOn, _ = ParseDate(scheduleRequest.StartsOn)
+	s.RRule = nil
+
+	if scheduleRequest.RRule != "" {
+		rule, _ := rrule.Parse(scheduleRequest.RRule)
+		normalized := rule.String()
+		s.RRule = &normalized
+	}
+
+	return s
+}
+
+// Occurrences expands the schedule within [from, to].
+func (s *ScheduledWorkout) Occurrences(from, to time.Time) []time.Time {
+	if s.RRule == nil {
+		if s.StartsOn.Before(NewDate(from).Time) || s.StartsOn.After(NewDate(to).Time) {
+			return []time.Time{}
+		}
+
+		return []time.Time{s.StartsOn.Time}
+	}
+
+	rule, err := rrule.Parse(*s.RRule)
+
+	if err != nil {
+		return []time.Time{}
+	}
+
+	return rule.Between(s.StartsOn.Time, from, to)
+}
+
+func (s *ScheduledWorkout) OccursOn(day Date) bool {
+	return len(s.Occurrences(day.Time, day.Time)) == 1
+}
+
+// matches reports whether a logged workout fulfils the schedule: started from
+// the same template, or with the same title for ad-hoc schedules.
+func (s *ScheduledWorkout) matches(workout *Workout) bool {
+	if s.TemplateID != nil {
+		return workout.TemplateID != nil && *workout.TemplateID == *s.TemplateID
+	}
+
+	return strings.EqualFold(strings.TrimSpace(workout.Title), strings.TrimSpace(s.Title))
+}
+
+type ScheduleStore interface {
+	CreateSchedule(schedule *ScheduledWorkout) (*ScheduledWorkout, error)
+	UpdateSchedule(id int, schedule *ScheduledWorkout) (*ScheduledWorkout, error)
+	GetScheduleById(id int) (*ScheduledWorkout, error)
+	DeleteSchedule(id int) error
+	GetAllSchedules(userID int) ([]ScheduledWorkout, error)
+	OwnsSchedule(id int, userID int) (bool, error)
+	CompleteOccurrence(scheduleID int, day Date, workoutID *int) error
+	GetOccurrences(userID int, from, to Date, today Date, location *time.Location) ([]ScheduledOccurrence, error)
+}
+
+type PostgresScheduleStore struct {
+	db *sql.DB
+}
+
+func NewPostgresScheduleStore(db *sql.DB) *PostgresScheduleStore {
+	return &PostgresScheduleStore{
+		db: db,
+	}
+}
+
+func (s *PostgresScheduleStore) CreateSchedule(schedule *ScheduledWorkout) (*ScheduledWorkout, error) {
+	query := `
+		insert into scheduled_workouts (user_id, template_id, title, notes, starts_on, rrule)
+		values ($1, $2, $3, $4, $5, $6)
+		returning id, created_at, updated_at
+	`
+
+	err := s.db.QueryRow(
+		query,
+		schedule.UserID,
+		schedule.TemplateID,
+		schedule.Title,
+		schedule.Notes,
+		schedule.StartsOn,
+		schedule.RRule).Scan(&schedule.ID, &schedule.CreatedAt, &schedule.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *PostgresScheduleStore) UpdateSchedule(id int, schedule *ScheduledWorkout) (*ScheduledWorkout, error) {
+	query := `
+		update scheduled_workouts
+		set template_id = $2, title = $3, notes = $4, starts_on = $5, rrule = $6, updated_at = now()
+		where id = $1
+		returning user_id, created_at, updated_at
+	`
+
+	schedule.ID = id
+
+	err := s.db.QueryRow(
+		query,
+		id,
+		schedule.TemplateID,
+		schedule.Title,
+		schedule.Notes,
+		schedule.StartsOn,
+		schedule.RRule).Scan(&schedule.UserID, &schedule.CreatedAt, &schedule.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *PostgresScheduleStore) GetScheduleById(id int) (*ScheduledWorkout, error) {
+	schedule := &ScheduledWorkout{}
+	query := `
+		select id, user_id, template_id, title, notes, starts_on, rrule, created_at, updated_at
+		from scheduled_workouts
+		where id = $1
+	`
+
+	err := s.db.QueryRow(query, id).Scan(
+		&schedule.ID,
+		&schedule.UserID,
+		&schedule.TemplateID,
+		&schedule.Title,
+		&schedule.Notes,
+		&schedule.StartsOn,
+		&schedule.RRule,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *PostgresScheduleStore) DeleteSchedule(id int) error {
+	result, err := s.db.Exec("delete from scheduled_workouts where id = $1", id)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return internalErrors.ErrNoRows
+	}
+
+	return nil
+}
+
+func (s *PostgresScheduleStore) GetAllSchedules(userID int) ([]ScheduledWorkout, error) {
+	query := `
+		select id, user_id, template_id, title, notes, starts_on, rrule, created_at, updated_at
+		from scheduled_workouts
+		where user_id = $1
+		order by starts_on, id
+	`
+
+	rows, err := s.db.Query(query, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var schedules = make([]ScheduledWorkout, 0)
+
+	for rows.Next() {
+		schedule := ScheduledWorkout{}
+
+		err := rows.Scan(
+			&schedule.ID,
+			&schedule.UserID,
+			&schedule.TemplateID,
+			&schedule.Title,
+			&schedule.Notes,
+			&schedule.StartsOn,
+			&schedule.RRule,
+			&schedule.CreatedAt,
+			&schedule.UpdatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (s *PostgresScheduleStore) OwnsSchedule(id int, userID int) (bool, error) {
+	var owns bool
+
+	query := `
+		select exists(select 1 from scheduled_workouts where id = $1 and user_id = $2)
+	`
+
+	err := s.db.QueryRow(query, id, userID).Scan(&owns)
+
+	return owns, err
+}
+
+// CompleteOccurrence explicitly marks an occurrence as done, optionally linking the workout that fulfilled it.
+func (s *PostgresScheduleStore) CompleteOccurrence(scheduleID int, day Date, workoutID *int) error {
+	query := `
+		insert into scheduled_workout_completions (scheduled_workout_id, occurrence_date, workout_id)
+		values ($1, $2, $3)
+		on conflict (scheduled_workout_id, occurrence_date) do update set workout_id = excluded.workout_id
+	`
+
+	_, err := s.db.Exec(query, scheduleID, day, workoutID)
+
+	return err
+}
+
+type occurrenceKey struct {
+	scheduleID int
+	day        string
+}
+
+// GetOccurrences expands every schedule of the user within [from, to]. An
+// occurrence is completed when it was explicitly marked or when a matching
+// workout was logged on that day in location, the time zone of the user, and
+// missed when its day is before today.
+func (s *PostgresScheduleStore) GetOccurrences(userID int, from, to Date, today Date, location *time.Location) ([]ScheduledOccurrence, error) {
+	schedules, err := s.GetAllSchedules(userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	completions, err := s.getCompletions(userID, from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	workoutsByDay, err := s.getWorkoutsByDay(userID, from, to, location)
+
+	if err != nil {
+		return nil, err
+	}
+
+	usedWorkouts := make(map[int]bool)
+
+	for _, workoutID := range completions {
+		if workoutID != nil {
+			usedWorkouts[*workoutID] = true
+		}
+	}
+
+	occurrences := make([]ScheduledOccurrence, 0)
+
+	for i := range schedules {
+		schedule := &schedules[i]
+
+		for _, day := range schedule.Occurrences(from.Time, to.Time) {
+			occurrence := ScheduledOccurrence{
+				ScheduleID: schedule.ID,
+				TemplateID: schedule.TemplateID,
+				Title:      schedule.Title,
+				Date:       NewDate(day),
+				Status:     OccurrencePlanned,
+			}
+
+			if workoutID, completed := completions[occurrenceKey{schedule.ID, occurrence.Date.String()}]; completed {
+				occurrence.Status = OccurrenceCompleted
+				occurrence.WorkoutID = workoutID
+			} else {
+				for _, workout := range workoutsByDay[occurrence.Date.String()] {
+					if !usedWorkouts[workout.ID] && schedule.matches(&workout) {
+						usedWorkouts[workout.ID] = true
+						occurrence.Status = OccurrenceCompleted
+						occurrence.WorkoutID = &workout.ID
+						break
+					}
+				}
+			}
+
+			if occurrence.Status == OccurrencePlanned && occurrence.Date.Before(today.Time) {
+				occurrence.Status = OccurrenceMissed
+			}
+
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		if occurrences[i].Date.Equal(occurrences[j].Date.Time) {
+			return occurrences[i].ScheduleID < occurrences[j].ScheduleID
+		}
+
+		return occurrences[i].Date.Before(occurrences[j].Date.Time)
+	})
+
+	return occurrences, nil
+}
+
+func (s *PostgresScheduleStore) getCompletions(userID int, from, to Date) (map[occurrenceKey]*int, error) {
+	query := `
+		select c.scheduled_workout_id, c.occurrence_date, c.workout_id
+		from scheduled_workout_completions c
+		join scheduled_workouts s on s.id = c.scheduled_workout_id
+		where s.user_id = $1 and c.occurrence_date between $2 and $3
+	`
+
+	rows, err := s.db.Query(query, userID, from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	completions := make(map[occurrenceKey]*int)
+
+	for rows.Next() {
+		var scheduleID int
+		var day Date
+		var workoutID *int
+
+		if err := rows.Scan(&scheduleID, &day, &workoutID); err != nil {
+			return nil, err
+		}
+
+		completions[occurrenceKey{scheduleID, day.String()}] = workoutID
+	}
+
+	return completions, rows.Err()
+}
+
+// getWorkoutsByDay groups the completed workouts of the user by the day they
+// were logged on in location.
+func (s *PostgresScheduleStore) getWorkoutsByDay(userID int, from, to Date, location *time.Location) (map[string][]Workout, error) {
+	query := `
+		select id, title, template_id, created_at
+		from workouts
+		where user_id = $1 and deleted_at is null and status = 'completed' and created_at >= $2 and created_at < $3
+		order by created_at, id
+	`
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+	end := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, location)
+
+	rows, err := s.db.Query(query, userID, start, end)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	workoutsByDay := make(map[string][]Workout)
+
+	for rows.Next() {
+		workout := Workout{}
+
+		if err := rows.Scan(&workout.ID, &workout.Title, &workout.TemplateID, &workout.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		day := NewDate(workout.CreatedAt.In(location)).String()
+		workoutsByDay[day] = append(workoutsByDay[day], workout)
+	}
+
+	return workoutsByDay, rows.Err()
+}
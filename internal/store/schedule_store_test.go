@@ -0,0 +1,117 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	"partiuFit/internal/utils"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	jane, _ := testingUtils.CreateToken(db, "janedoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	scheduleStore := NewPostgresScheduleStore(db)
+	workoutStore := NewPostgresWorkoutStore(db)
+
+	today := NewDate(time.Now().UTC())
+	weekAgo := NewDate(today.AddDate(0, 0, -7))
+
+	t.Run("Create with recurrence", func(t *testing.T) {
+		schedule := &ScheduledWorkout{
+			UserID:   user.ID,
+			Title:    "Morning Run",
+			StartsOn: weekAgo,
+			RRule:    utils.ValueToPointer("FREQ=DAILY"),
+		}
+
+		createdSchedule, err := scheduleStore.CreateSchedule(schedule)
+
+		assert.NoError(t, err)
+		assert.NotZero(t, createdSchedule.ID)
+
+		retrievedSchedule, err := scheduleStore.GetScheduleById(createdSchedule.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, weekAgo.String(), retrievedSchedule.StartsOn.String())
+		assert.Equal(t, "FREQ=DAILY", *retrievedSchedule.RRule)
+	})
+
+	t.Run("Occurrences are completed by matching workouts and missed in the past", func(t *testing.T) {
+		_, err := workoutStore.CreateWorkout(&Workout{
+			Title:           "morning run",
+			Description:     "5k",
+			DurationMinutes: 30,
+			CaloriesBurned:  300,
+			UserID:          user.ID,
+		})
+		assert.NoError(t, err)
+
+		occurrences, err := scheduleStore.GetOccurrences(user.ID, weekAgo, today, today, time.UTC)
+
+		assert.NoError(t, err)
+		assert.Len(t, occurrences, 8)
+		assert.Equal(t, OccurrenceMissed, occurrences[0].Status)
+		assert.Equal(t, OccurrenceCompleted, occurrences[7].Status)
+		assert.NotNil(t, occurrences[7].WorkoutID)
+	})
+
+	t.Run("Complete occurrence explicitly", func(t *testing.T) {
+		schedules := utils.Must(scheduleStore.GetAllSchedules(user.ID))
+		yesterday := NewDate(today.AddDate(0, 0, -1))
+
+		err := scheduleStore.CompleteOccurrence(schedules[0].ID, yesterday, nil)
+		assert.NoError(t, err)
+
+		occurrences := utils.Must(scheduleStore.GetOccurrences(user.ID, yesterday, yesterday, today, time.UTC))
+		assert.Len(t, occurrences, 1)
+		assert.Equal(t, OccurrenceCompleted, occurrences[0].Status)
+	})
+
+	t.Run("Single occurrence without recurrence", func(t *testing.T) {
+		nextWeek := NewDate(today.AddDate(0, 0, 7))
+		schedule := utils.Must(scheduleStore.CreateSchedule(&ScheduledWorkout{
+			UserID:   jane.ID,
+			Title:    "Leg day",
+			StartsOn: nextWeek,
+		}))
+
+		assert.True(t, schedule.OccursOn(nextWeek))
+		assert.False(t, schedule.OccursOn(today))
+
+		occurrences := utils.Must(scheduleStore.GetOccurrences(jane.ID, today, NewDate(today.AddDate(0, 0, 30)), today, time.UTC))
+		assert.Len(t, occurrences, 1)
+		assert.Equal(t, OccurrencePlanned, occurrences[0].Status)
+	})
+
+	t.Run("Workouts count on the day of the user's time zone", func(t *testing.T) {
+		saoPaulo := utils.Must(time.LoadLocation("America/Sao_Paulo"))
+		day := utils.Must(ParseDate("2025-03-10"))
+		utils.Must(scheduleStore.CreateSchedule(&ScheduledWorkout{UserID: jane.ID, Title: "Late swim", StartsOn: day}))
+
+		// 23:30 in São Paulo is already the next day in UTC
+		loggedAt := time.Date(2025, 3, 10, 23, 30, 0, 0, saoPaulo)
+		utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Late swim", UserID: jane.ID, CreatedAt: &loggedAt}))
+
+		occurrences := utils.Must(scheduleStore.GetOccurrences(jane.ID, day, day, today, saoPaulo))
+		assert.Len(t, occurrences, 1)
+		assert.Equal(t, OccurrenceCompleted, occurrences[0].Status)
+
+		occurrences = utils.Must(scheduleStore.GetOccurrences(jane.ID, day, day, today, time.UTC))
+		assert.Equal(t, OccurrenceMissed, occurrences[0].Status)
+	})
+
+	t.Run("Ownership and delete", func(t *testing.T) {
+		schedules := utils.Must(scheduleStore.GetAllSchedules(user.ID))
+
+		assert.True(t, utils.Must(scheduleStore.OwnsSchedule(schedules[0].ID, user.ID)))
+		assert.False(t, utils.Must(scheduleStore.OwnsSchedule(schedules[0].ID, jane.ID)))
+		assert.NoError(t, scheduleStore.DeleteSchedule(schedules[0].ID))
+		assert.Empty(t, utils.Must(scheduleStore.GetAllSchedules(user.ID)))
+	})
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"database/sql"
+	"html"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 50
+)
+
+// searchHeadlineOptions delimits the matched words of a snippet with control
+// characters, which are removed from the texts beforehand, and keeps up to two
+// short fragments of long texts. markSnippet turns the delimiters into <mark>
+// once the rest of the snippet is escaped.
+const searchHeadlineOptions = "StartSel=\x02, StopSel=\x03, MinWords=5, MaxWords=20, MaxFragments=2"
+
+var searchMarkReplacer = strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>")
+
+// markSnippet escapes the user text of a snippet as HTML and marks its matched
+// words, so the snippet is safe to render as is.
+func markSnippet(snippet string) string {
+	return searchMarkReplacer.Replace(html.EscapeString(snippet))
+}
+
+type SearchHighlight struct {
+	Field   string `json:"field"`
+	EntryID *int   `json:"entry_id,omitempty"`
+	Snippet string `json:"snippet"`
+}
+
+// SearchResult is a workout matching the search. Highlights are snippets of
+// the fields that matched, the workout ones first and then its entries in order.
+type SearchResult struct {
+	WorkoutID  int               `json:"workout_id"`
+	Title      string            `json:"title"`
+	CreatedAt  *time.Time        `json:"created_at"`
+	Rank       float64           `json:"rank"`
+	Highlights []SearchHighlight `json:"highlights"`
+}
+
+type SearchStore interface {
+	SearchWorkouts(userID int, text string, limit int) ([]SearchResult, error)
+}
+
+type PostgresSearchStore struct {
+	db *sql.DB
+}
+
+func NewPostgresSearchStore(db *sql.DB) *PostgresSearchStore {
+	return &PostgresSearchStore{
+		db: db,
+	}
+}
+
+// SearchWorkouts finds the workouts of the user whose title, description or
+// entries (exercise names and notes) match the text, best matches first. The
+// text is parsed the way search engines do ("quoted phrases", or, -word) once
+// in each language, so words match whichever language they were written in. A
+// workout ranks by its own match plus the best match among its entries.
+// Workouts in the trash are left out.
+func (s *PostgresSearchStore) SearchWorkouts(userID int, text string, limit int) ([]SearchResult, error) {
+	query := `
+		select workouts.id, workouts.title, workouts.created_at,
+			ts_rank(workouts.search_vector, search.query) + coalesce(max(ts_rank(workout_entries.search_vector, search.query)), 0) as rank
+		from workouts
+		cross join (select websearch_to_tsquery('portuguese', $1) || websearch_to_tsquery('english', $1) as query) as search
+		left join workout_entries on workout_entries.workout_id = workouts.id and workout_entries.search_vector @@ search.query
+		where workouts.user_id = $2 and workouts.deleted_at is null
+		  and (workouts.search_vector @@ search.query or workout_entries.id is not null)
+		group by workouts.id, search.query
+		order by rank desc, workouts.created_at desc, workouts.id desc
+		limit $3
+	`
+
+	results := make([]SearchResult, 0)
+
+	err := scanRows(s.db, query, []any{text, userID, limit}, func(rows *sql.Rows) error {
+		result := SearchResult{Highlights: make([]SearchHighlight, 0)}
+
+		if err := rows.Scan(&result.WorkoutID, &result.Title, &result.CreatedAt, &result.Rank); err != nil {
+			return err
+		}
+
+		results = append(results, result)
+
+		return nil
+	})
+
+	if err != nil || len(results) == 0 {
+		return results, err
+	}
+
+	return results, loadSearchHighlights(s.db, text, results)
+}
+
+// loadSearchHighlights fills in the snippets of the fields that matched. Each
+// field is highlighted with the language it matched in, the stemmed words of
+// the other one would not be marked.
+func loadSearchHighlights(q queryer, text string, results []SearchResult) error {
+	workoutIDs := make([]int, len(results))
+	resultsByWorkout := make(map[int]*SearchResult, len(results))
+
+	for i := range results {
+		workoutIDs[i] = results[i].WorkoutID
+		resultsByWorkout[results[i].WorkoutID] = &results[i]
+	}
+
+	query := `
+		select fields.workout_id, fields.entry_id, fields.field,
+			case when to_tsvector('portuguese', fields.value) @@ search.portuguese
+				then ts_headline('portuguese', translate(fields.value, chr(2) || chr(3), ''), search.portuguese, $3)
+				else ts_headline('english', translate(fields.value, chr(2) || chr(3), ''), search.english, $3)
+			end
+		from (
+			select id as workout_id, null::integer as entry_id, 'title' as field, title as value, 0 as position, 0 as field_position
+			from workouts where id = any($2)
+			union all
+			select id, null, 'description', description, 0, 1
+			from workouts where id = any($2)
+			union all
+			select workout_id, id, 'exercise_name', exercise_name, order_index + 1, 0
+			from workout_entries where workout_id = any($2)
+			union all
+			select workout_id, id, 'notes', coalesce(notes, ''), order_index + 1, 1
+			from workout_entries where workout_id = any($2)
+		) as fields
+		cross join (select websearch_to_tsquery('portuguese', $1) as portuguese, websearch_to_tsquery('english', $1) as english) as search
+		where to_tsvector('portuguese', fields.value) @@ search.portuguese
+		   or to_tsvector('english', fields.value) @@ search.english
+		order by fields.workout_id, fields.position, fields.entry_id, fields.field_position
+	`
+
+	return scanRows(q, query, []any{text, workoutIDs, searchHeadlineOptions}, func(rows *sql.Rows) error {
+		var workoutID int
+		highlight := SearchHighlight{}
+
+		if err := rows.Scan(&workoutID, &highlight.EntryID, &highlight.Field, &highlight.Snippet); err != nil {
+			return err
+		}
+
+		highlight.Snippet = markSnippet(highlight.Snippet)
+		result := resultsByWorkout[workoutID]
+		result.Highlights = append(result.Highlights, highlight)
+
+		return nil
+	})
+}
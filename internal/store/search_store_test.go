@@ -0,0 +1,82 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	"partiuFit/internal/utils"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	workoutStore := NewPostgresWorkoutStore(db)
+	searchStore := NewPostgresSearchStore(db)
+
+	legs := utils.Must(workoutStore.CreateWorkout(&Workout{
+		Title:           "Treino de pernas",
+		Description:     "Joelho doendo no fim",
+		DurationMinutes: 60,
+		UserID:          user.ID,
+		Entries: []WorkoutEntry{
+			{ExerciseName: "Squat", Sets: 3, Reps: utils.ValueToPointer(5), Weight: 100, Notes: "My knee hurt on the last set", OrderIndex: 1, UserID: user.ID},
+		},
+	}))
+	push := utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Push", Description: "Knee felt fine", DurationMinutes: 45, UserID: user.ID}))
+	deleted := utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Knee rehab", DurationMinutes: 30, UserID: user.ID}))
+	utils.MustIfError(workoutStore.DeleteWorkout(deleted.ID))
+
+	t.Run("Search entry notes in English", func(t *testing.T) {
+		results, err := searchStore.SearchWorkouts(user.ID, "knee hurts", DefaultSearchLimit)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, legs.ID, results[0].WorkoutID)
+
+		highlight := results[0].Highlights[0]
+		assert.Equal(t, "notes", highlight.Field)
+		assert.Equal(t, legs.Entries[0].ID, *highlight.EntryID)
+		assert.Contains(t, highlight.Snippet, "<mark>knee</mark>")
+	})
+
+	t.Run("Search in Portuguese", func(t *testing.T) {
+		results, err := searchStore.SearchWorkouts(user.ID, "joelho", DefaultSearchLimit)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, "description", results[0].Highlights[0].Field)
+		assert.Contains(t, results[0].Highlights[0].Snippet, "<mark>Joelho</mark>")
+	})
+
+	t.Run("Results are ranked and skip the trash", func(t *testing.T) {
+		results, err := searchStore.SearchWorkouts(user.ID, "knee", DefaultSearchLimit)
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+
+		for _, result := range results {
+			assert.NotEqual(t, deleted.ID, result.WorkoutID)
+		}
+
+		assert.ElementsMatch(t, []int{legs.ID, push.ID}, []int{results[0].WorkoutID, results[1].WorkoutID})
+		assert.GreaterOrEqual(t, results[0].Rank, results[1].Rank)
+	})
+
+	t.Run("Snippets escape the user text", func(t *testing.T) {
+		utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Shoulder <b>day</b> \x02", DurationMinutes: 30, UserID: user.ID}))
+
+		results, err := searchStore.SearchWorkouts(user.ID, "shoulder", DefaultSearchLimit)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		snippet := results[0].Highlights[0].Snippet
+		assert.Contains(t, snippet, "<mark>Shoulder</mark>")
+		assert.NotContains(t, snippet, "<b>")
+		assert.NotContains(t, snippet, "\x02")
+	})
+}
+
+func TestMarkSnippet(t *testing.T) {
+	assert.Equal(t, "<mark>knee</mark> &lt;script&gt;alert(1)&lt;/script&gt;", markSnippet("\x02knee\x03 <script>alert(1)</script>"))
+}
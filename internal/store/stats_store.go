@@ -0,0 +1,431 @@
+package store
+
+import (
+	"cmp"
+	"database/sql"
+	"fmt"
+	"math"
+	"partiuFit/internal/fitness"
+	"strings"
+	"time"
+)
+
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+type ProgressFilters struct {
+	Bucket   string
+	Formula  string
+	TimeZone string
+	From     *time.Time
+	To       *time.Time
+}
+
+type ProgressBucket struct {
+	PeriodStart      Date    `json:"period_start"`
+	TotalVolume      float64 `json:"total_volume"`
+	MaxWeight        float64 `json:"max_weight"`
+	BestEstimated1RM float64 `json:"best_estimated_1rm"`
+	// RelativeStrength is the best estimated 1RM divided by the bodyweight
+	// measured by the end of the period, nil without bodyweight measurements.
+	RelativeStrength *float64 `json:"relative_strength"`
+	SessionCount     int      `json:"session_count"`
+}
+
+// TopMuscleGroupsLimit is how many muscle groups a summary period highlights.
+const TopMuscleGroupsLimit = 3
+
+type SummaryFilters struct {
+	Period   string
+	TimeZone string
+	From     Date
+	To       Date
+}
+
+type MuscleGroupCount struct {
+	MuscleGroup string `json:"muscle_group"`
+	Sets        int    `json:"sets"`
+}
+
+type SummaryTotals struct {
+	WorkoutCount         int     `json:"workout_count"`
+	TotalDurationMinutes int     `json:"total_duration_minutes"`
+	TotalCaloriesBurned  int     `json:"total_calories_burned"`
+	TotalVolume          float64 `json:"total_volume"`
+	DistinctExercises    int     `json:"distinct_exercises"`
+}
+
+type SummaryPeriod struct {
+	PeriodStart Date `json:"period_start"`
+	PeriodEnd   Date `json:"period_end"`
+	SummaryTotals
+	TopMuscleGroups []MuscleGroupCount `json:"top_muscle_groups"`
+	// Deltas compares the totals against the period right before this one.
+	Deltas SummaryTotals `json:"deltas"`
+}
+
+type StatsStore interface {
+	GetExerciseProgress(userID int, exerciseKey string, filters ProgressFilters) ([]ProgressBucket, error)
+	GetSummary(userID int, filters SummaryFilters) ([]SummaryPeriod, error)
+}
+
+type PostgresStatsStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStatsStore(db *sql.DB) *PostgresStatsStore {
+	return &PostgresStatsStore{
+		db: db,
+	}
+}
+
+// ExerciseKey identifies an exercise the same way personal records do: by
+// catalog id, or by normalized name for entries outside the catalog.
+func ExerciseKey(exercise *Exercise, name string) string {
+	if exercise != nil {
+		return fmt.Sprint(exercise.ID)
+	}
+
+	return "name:" + strings.ToLower(strings.TrimSpace(name))
+}
+
+// GetExerciseProgress aggregates the completed, non warm-up sets of an
+// exercise per period. Volume is the sum of reps × weight of every set, which
+// equals sets × reps × weight for entries logged with the legacy triple.
+func (s *PostgresStatsStore) GetExerciseProgress(userID int, exerciseKey string, filters ProgressFilters) ([]ProgressBucket, error) {
+	conditions, args := progressConditions(userID, exerciseKey, filters)
+
+	query := fmt.Sprintf(`
+		select date_trunc($%d, workouts.created_at at time zone $%d) as period_start,
+		       coalesce(sum(workout_sets.reps * workout_sets.weight), 0),
+		       coalesce(max(workout_sets.weight), 0),
+		       count(distinct workouts.id)
+		from workout_sets
+		join workout_entries on workout_entries.id = workout_sets.workout_entry_id
+		join workouts on workouts.id = workout_entries.workout_id
+		where %s
+		group by period_start
+		order by period_start
+	`, len(args)+1, len(args)+2, conditions)
+
+	rows, err := s.db.Query(query, append(args, filters.Bucket, cmp.Or(filters.TimeZone, "UTC"))...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	buckets := make([]ProgressBucket, 0)
+	bucketIndex := make(map[string]int)
+
+	for rows.Next() {
+		bucket := ProgressBucket{}
+		var periodStart time.Time
+
+		err := rows.Scan(&periodStart, &bucket.TotalVolume, &bucket.MaxWeight, &bucket.SessionCount)
+
+		if err != nil {
+			return nil, err
+		}
+
+		bucket.PeriodStart = NewDate(periodStart)
+		bucketIndex[bucket.PeriodStart.String()] = len(buckets)
+		buckets = append(buckets, bucket)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// The best estimated 1RM of a weight always comes from its highest rep
+	// count, so only those pairs are needed to apply the formula in Go.
+	repsQuery := fmt.Sprintf(`
+		select date_trunc($%d, workouts.created_at at time zone $%d) as period_start,
+		       workout_sets.weight,
+		       max(workout_sets.reps)
+		from workout_sets
+		join workout_entries on workout_entries.id = workout_sets.workout_entry_id
+		join workouts on workouts.id = workout_entries.workout_id
+		where %s
+		group by period_start, workout_sets.weight
+	`, len(args)+1, len(args)+2, conditions)
+
+	repsRows, err := s.db.Query(repsQuery, append(args, filters.Bucket, cmp.Or(filters.TimeZone, "UTC"))...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = repsRows.Close()
+	}()
+
+	for repsRows.Next() {
+		var periodStart time.Time
+		var weight float64
+		var reps int
+
+		if err := repsRows.Scan(&periodStart, &weight, &reps); err != nil {
+			return nil, err
+		}
+
+		bucket := &buckets[bucketIndex[NewDate(periodStart).String()]]
+		bucket.BestEstimated1RM = max(bucket.BestEstimated1RM, fitness.EstimateOneRepMax(weight, reps, filters.Formula))
+	}
+
+	if err := repsRows.Err(); err != nil {
+		return nil, err
+	}
+
+	bodyweights, err := loadMeasurementSeries(s.db, userID, "weight", nil, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range buckets {
+		bucketEnd := AddPeriods(buckets[i].PeriodStart, filters.Bucket, 1)
+		bodyweight := bodyweightAt(bodyweights, bucketEnd.Time)
+
+		if bodyweight != nil && buckets[i].BestEstimated1RM > 0 {
+			relativeStrength := math.Round(buckets[i].BestEstimated1RM / *bodyweight * 100) / 100
+			buckets[i].RelativeStrength = &relativeStrength
+		}
+	}
+
+	return buckets, nil
+}
+
+func progressConditions(userID int, exerciseKey string, filters ProgressFilters) (string, []any) {
+	conditions := fmt.Sprintf(`
+		workouts.user_id = $1
+		and workouts.deleted_at is null
+		and workouts.status = 'completed'
+		and %s = $2
+		and workout_sets.completed
+		and workout_sets.set_type <> 'warm_up'
+		and workout_sets.reps > 0
+	`, exerciseKeyExpression)
+	args := []any{userID, exerciseKey}
+
+	if filters.From != nil {
+		args = append(args, *filters.From)
+		conditions += fmt.Sprintf(" and workouts.created_at >= $%d", len(args))
+	}
+
+	if filters.To != nil {
+		args = append(args, *filters.To)
+		conditions += fmt.Sprintf(" and workouts.created_at < $%d", len(args))
+	}
+
+	return conditions, args
+}
+
+// PeriodStart aligns a day to the start of its ISO week or calendar month.
+func PeriodStart(day Date, period string) Date {
+	if period == BucketMonth {
+		return Date{time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)}
+	}
+
+	if period == BucketDay {
+		return day
+	}
+
+	weekday := (int(day.Weekday()) + 6) % 7
+
+	return Date{day.AddDate(0, 0, -weekday)}
+}
+
+// AddPeriods moves a period start by n weeks or months.
+func AddPeriods(start Date, period string, n int) Date {
+	switch period {
+	case BucketMonth:
+		return Date{start.AddDate(0, n, 0)}
+	case BucketDay:
+		return Date{start.AddDate(0, 0, n)}
+	default:
+		return Date{start.AddDate(0, 0, 7*n)}
+	}
+}
+
+// GetSummary returns one entry per period between filters.From and
+// filters.To, including empty periods, bucketed in the user's time zone.
+func (s *PostgresStatsStore) GetSummary(userID int, filters SummaryFilters) ([]SummaryPeriod, error) {
+	first := PeriodStart(filters.From, filters.Period)
+	last := PeriodStart(filters.To, filters.Period)
+	// the period before the first one is loaded only to compute its deltas
+	rangeStart := AddPeriods(first, filters.Period, -1)
+	rangeEnd := AddPeriods(last, filters.Period, 1)
+
+	periods := make(map[string]*SummaryPeriod)
+	periodFor := func(start time.Time) *SummaryPeriod {
+		key := NewDate(start).String()
+
+		if periods[key] == nil {
+			periods[key] = &SummaryPeriod{TopMuscleGroups: make([]MuscleGroupCount, 0)}
+		}
+
+		return periods[key]
+	}
+
+	localTime := "(workouts.created_at at time zone $2)"
+	conditions := fmt.Sprintf(`
+		workouts.user_id = $1
+		and workouts.deleted_at is null
+		and workouts.status = 'completed'
+		and %[1]s >= $4::timestamp
+		and %[1]s < $5::timestamp
+	`, localTime)
+	args := []any{userID, cmp.Or(filters.TimeZone, "UTC"), filters.Period, rangeStart.String(), rangeEnd.String()}
+	periodColumn := fmt.Sprintf("date_trunc($3, %s)", localTime)
+
+	workoutsQuery := fmt.Sprintf(`
+		select %s as period_start,
+		       count(*),
+		       coalesce(sum(duration_minutes), 0),
+		       coalesce(sum(calories_burned), 0)
+		from workouts
+		where %s
+		group by period_start
+	`, periodColumn, conditions)
+
+	err := scanRows(s.db, workoutsQuery, args, func(rows *sql.Rows) error {
+		var start time.Time
+		var count, duration, calories int
+
+		if err := rows.Scan(&start, &count, &duration, &calories); err != nil {
+			return err
+		}
+
+		totals := &periodFor(start).SummaryTotals
+		totals.WorkoutCount = count
+		totals.TotalDurationMinutes = duration
+		totals.TotalCaloriesBurned = calories
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	entriesQuery := fmt.Sprintf(`
+		select %s as period_start,
+		       coalesce(sum(workout_sets.reps * workout_sets.weight) filter (
+		           where workout_sets.completed and workout_sets.set_type <> 'warm_up'
+		       ), 0),
+		       count(distinct %s)
+		from workout_entries
+		join workouts on workouts.id = workout_entries.workout_id
+		left join workout_sets on workout_sets.workout_entry_id = workout_entries.id
+		where %s
+		group by period_start
+	`, periodColumn, exerciseKeyExpression, conditions)
+
+	err = scanRows(s.db, entriesQuery, args, func(rows *sql.Rows) error {
+		var start time.Time
+		var volume float64
+		var exercises int
+
+		if err := rows.Scan(&start, &volume, &exercises); err != nil {
+			return err
+		}
+
+		totals := &periodFor(start).SummaryTotals
+		totals.TotalVolume = volume
+		totals.DistinctExercises = exercises
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	muscleGroupsQuery := fmt.Sprintf(`
+		select %s as period_start,
+		       muscle_group,
+		       count(*) as sets
+		from workout_sets
+		join workout_entries on workout_entries.id = workout_sets.workout_entry_id
+		join workouts on workouts.id = workout_entries.workout_id
+		join exercises on exercises.id = workout_entries.exercise_id
+		cross join unnest(exercises.primary_muscle_groups) as muscle_group
+		where %s and workout_sets.completed and workout_sets.set_type <> 'warm_up'
+		group by period_start, muscle_group
+		order by period_start, sets desc, muscle_group
+	`, periodColumn, conditions)
+
+	err = scanRows(s.db, muscleGroupsQuery, args, func(rows *sql.Rows) error {
+		var start time.Time
+		muscleGroup := MuscleGroupCount{}
+
+		if err := rows.Scan(&start, &muscleGroup.MuscleGroup, &muscleGroup.Sets); err != nil {
+			return err
+		}
+
+		period := periodFor(start)
+
+		if len(period.TopMuscleGroups) < TopMuscleGroupsLimit {
+			period.TopMuscleGroups = append(period.TopMuscleGroups, muscleGroup)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	summary := make([]SummaryPeriod, 0)
+	previous := periodFor(rangeStart.Time).SummaryTotals
+
+	for start := first; !start.After(last.Time); start = AddPeriods(start, filters.Period, 1) {
+		period := periodFor(start.Time)
+		period.PeriodStart = start
+		period.PeriodEnd = Date{AddPeriods(start, filters.Period, 1).AddDate(0, 0, -1)}
+		period.Deltas = period.SummaryTotals.Minus(previous)
+
+		summary = append(summary, *period)
+		previous = period.SummaryTotals
+	}
+
+	return summary, nil
+}
+
+func (t SummaryTotals) Minus(other SummaryTotals) SummaryTotals {
+	return SummaryTotals{
+		WorkoutCount:         t.WorkoutCount - other.WorkoutCount,
+		TotalDurationMinutes: t.TotalDurationMinutes - other.TotalDurationMinutes,
+		TotalCaloriesBurned:  t.TotalCaloriesBurned - other.TotalCaloriesBurned,
+		TotalVolume:          t.TotalVolume - other.TotalVolume,
+		DistinctExercises:    t.DistinctExercises - other.DistinctExercises,
+	}
+}
+
+// scanRows runs a query and hands every row to scanRow.
+func scanRows(q queryer, query string, args []any, scanRow func(rows *sql.Rows) error) error {
+	rows, err := q.Query(query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		if err := scanRow(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	"partiuFit/internal/fitness"
+	"partiuFit/internal/utils"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	workoutStore := NewPostgresWorkoutStore(db)
+	statsStore := NewPostgresStatsStore(db)
+
+	logWorkout := func(exerciseName string, sets ...WorkoutSet) {
+		_, err := workoutStore.CreateWorkout(&Workout{
+			Title:           "Progress",
+			Description:     "Progress day",
+			DurationMinutes: 30,
+			CaloriesBurned:  200,
+			UserID:          user.ID,
+			Entries: []WorkoutEntry{
+				{ExerciseName: exerciseName, OrderIndex: 1, UserID: user.ID, WorkoutSets: sets},
+			},
+		})
+		utils.MustIfError(err)
+	}
+
+	logWorkout("Landmine Press",
+		WorkoutSet{SetType: SetTypeWarmUp, Reps: utils.ValueToPointer(10), Weight: 20, Completed: true},
+		WorkoutSet{SetType: SetTypeWorking, Reps: utils.ValueToPointer(5), Weight: 40, Completed: true},
+	)
+	logWorkout("landmine press ",
+		WorkoutSet{SetType: SetTypeWorking, Reps: utils.ValueToPointer(8), Weight: 35, Completed: true},
+		WorkoutSet{SetType: SetTypeFailure, Reps: utils.ValueToPointer(1), Weight: 60, Completed: false},
+	)
+
+	t.Run("Aggregates completed working sets per bucket", func(t *testing.T) {
+		progress, err := statsStore.GetExerciseProgress(user.ID, ExerciseKey(nil, "Landmine Press"), ProgressFilters{
+			Bucket:  BucketWeek,
+			Formula: fitness.FormulaEpley,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, progress, 1)
+		assert.Equal(t, 2, progress[0].SessionCount)
+		assert.Equal(t, 40.0*5+35*8, progress[0].TotalVolume)
+		assert.Equal(t, 40.0, progress[0].MaxWeight, "warm-up and incomplete sets never count")
+		assert.InDelta(t, 46.67, progress[0].BestEstimated1RM, 0.01)
+	})
+
+	t.Run("Formula is selectable", func(t *testing.T) {
+		progress, err := statsStore.GetExerciseProgress(user.ID, ExerciseKey(nil, "Landmine Press"), ProgressFilters{
+			Bucket:  BucketMonth,
+			Formula: fitness.FormulaBrzycki,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, progress, 1)
+		assert.InDelta(t, fitness.EstimateOneRepMax(40, 5, fitness.FormulaBrzycki), progress[0].BestEstimated1RM, 0.01)
+	})
+
+	t.Run("Range outside the history is empty", func(t *testing.T) {
+		to := time.Now().AddDate(0, 0, -7)
+		progress, err := statsStore.GetExerciseProgress(user.ID, ExerciseKey(nil, "Landmine Press"), ProgressFilters{
+			Bucket:  BucketDay,
+			Formula: fitness.FormulaEpley,
+			To:      &to,
+		})
+
+		assert.NoError(t, err)
+		assert.Empty(t, progress)
+	})
+
+	t.Run("Summary fills empty periods and computes deltas", func(t *testing.T) {
+		today := NewDate(time.Now().UTC())
+		summary, err := statsStore.GetSummary(user.ID, SummaryFilters{
+			Period:   BucketWeek,
+			TimeZone: "UTC",
+			From:     Date{today.AddDate(0, 0, -14)},
+			To:       today,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, summary, 3)
+
+		current := summary[len(summary)-1]
+		assert.Equal(t, PeriodStart(today, BucketWeek), current.PeriodStart)
+		assert.Equal(t, 2, current.WorkoutCount)
+		assert.Equal(t, 60, current.TotalDurationMinutes)
+		assert.Equal(t, 400, current.TotalCaloriesBurned)
+		assert.Equal(t, 40.0*5+35*8, current.TotalVolume)
+		assert.Equal(t, 1, current.DistinctExercises, "names are compared case-insensitively")
+		assert.Equal(t, 2, current.Deltas.WorkoutCount)
+		assert.Zero(t, summary[0].WorkoutCount)
+	})
+}
+
+func TestPeriodStart(t *testing.T) {
+	wednesday := utils.Must(ParseDate("2025-10-15"))
+
+	assert.Equal(t, "2025-10-13", PeriodStart(wednesday, BucketWeek).String())
+	assert.Equal(t, "2025-10-01", PeriodStart(wednesday, BucketMonth).String())
+	assert.Equal(t, "2025-10-19", PeriodStart(utils.Must(ParseDate("2025-10-19")), BucketDay).String())
+	assert.Equal(t, "2025-10-13", PeriodStart(utils.Must(ParseDate("2025-10-19")), BucketWeek).String(), "sunday closes the ISO week")
+	assert.Equal(t, "2025-11-01", AddPeriods(PeriodStart(wednesday, BucketMonth), BucketMonth, 1).String())
+}
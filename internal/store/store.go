@@ -7,15 +7,47 @@ import (
 )
 
 type Store struct {
-	WorkoutStore WorkoutStore
-	UserStore    UserStore
-	TokensStore  TokensStore
+	WorkoutStore         WorkoutStore
+	UserStore            UserStore
+	TokensStore          TokensStore
+	ExerciseStore        ExerciseStore
+	TemplateStore        TemplateStore
+	ScheduleStore        ScheduleStore
+	PersonalRecordStore  PersonalRecordStore
+	StatsStore           StatsStore
+	GoalStore            GoalStore
+	BodyMeasurementStore BodyMeasurementStore
+	WorkoutSessionStore  WorkoutSessionStore
+	WorkoutTrackStore    WorkoutTrackStore
+	WorkoutRevisionStore WorkoutRevisionStore
+	WorkoutEntryStore    WorkoutEntryStore
+	TagStore             TagStore
+	SearchStore          SearchStore
+	WorkoutShareStore    WorkoutShareStore
+	FollowStore          FollowStore
+	FeedStore            FeedStore
 }
 
 func NewStore(db *sql.DB) *Store {
 	return &Store{
-		WorkoutStore: NewPostgresWorkoutStore(db),
-		UserStore:    NewPostgresUserStore(db),
-		TokensStore:  NewPostgresTokensStore(db),
+		WorkoutStore:         NewPostgresWorkoutStore(db),
+		UserStore:            NewPostgresUserStore(db),
+		TokensStore:          NewPostgresTokensStore(db),
+		ExerciseStore:        NewPostgresExerciseStore(db),
+		TemplateStore:        NewPostgresTemplateStore(db),
+		ScheduleStore:        NewPostgresScheduleStore(db),
+		PersonalRecordStore:  NewPostgresPersonalRecordStore(db),
+		StatsStore:           NewPostgresStatsStore(db),
+		GoalStore:            NewPostgresGoalStore(db),
+		BodyMeasurementStore: NewPostgresBodyMeasurementStore(db),
+		WorkoutSessionStore:  NewPostgresWorkoutSessionStore(db),
+		WorkoutTrackStore:    NewPostgresWorkoutTrackStore(db),
+		WorkoutRevisionStore: NewPostgresWorkoutRevisionStore(db),
+		WorkoutEntryStore:    NewPostgresWorkoutEntryStore(db),
+		TagStore:             NewPostgresTagStore(db),
+		SearchStore:          NewPostgresSearchStore(db),
+		WorkoutShareStore:    NewPostgresWorkoutShareStore(db),
+		FollowStore:          NewPostgresFollowStore(db),
+		FeedStore:            NewPostgresFeedStore(db),
 	}
 }
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StringArray scans a postgres text[] column. The pgx stdlib driver hands
+// arrays to database/sql in their text representation, e.g. {a,"b c"}.
+type StringArray []string
+
+func (a *StringArray) Scan(src any) error {
+	var text string
+
+	switch value := src.(type) {
+	case nil:
+		*a = StringArray{}
+		return nil
+	case string:
+		text = value
+	case []byte:
+		text = string(value)
+	default:
+		return fmt.Errorf("cannot scan %T into StringArray", src)
+	}
+
+	if len(text) < 2 || text[0] != '{' || text[len(text)-1] != '}' {
+		return fmt.Errorf("invalid array literal %q", text)
+	}
+
+	elements := StringArray{}
+	body := text[1 : len(text)-1]
+
+	for i := 0; i < len(body); {
+		var element strings.Builder
+
+		if body[i] == '"' {
+			i++
+
+			for i < len(body) && body[i] != '"' {
+				if body[i] == '\\' && i+1 < len(body) {
+					i++
+				}
+
+				element.WriteByte(body[i])
+				i++
+			}
+
+			i++
+			elements = append(elements, element.String())
+		} else {
+			for i < len(body) && body[i] != ',' {
+				element.WriteByte(body[i])
+				i++
+			}
+
+			if element.String() != "NULL" {
+				elements = append(elements, element.String())
+			}
+		}
+
+		// skip the separator
+		i++
+	}
+
+	*a = elements
+
+	return nil
+}
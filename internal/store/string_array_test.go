@@ -0,0 +1,29 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringArrayScan(t *testing.T) {
+	t.Run("Empty array", func(t *testing.T) {
+		var array StringArray
+
+		assert.NoError(t, array.Scan("{}"))
+		assert.Equal(t, StringArray{}, array)
+	})
+
+	t.Run("Quoted and unquoted elements", func(t *testing.T) {
+		var array StringArray
+
+		assert.NoError(t, array.Scan([]byte(`{chest,"Supino reto","say \"hi\"",NULL}`)))
+		assert.Equal(t, StringArray{"chest", "Supino reto", `say "hi"`}, array)
+	})
+
+	t.Run("Invalid literal", func(t *testing.T) {
+		var array StringArray
+
+		assert.Error(t, array.Scan("chest"))
+	})
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+// Tag is a label the user puts on workouts. WorkoutCount only counts workouts
+// that are not in the trash.
+type Tag struct {
+	ID           int        `json:"id"`
+	Name         string     `json:"name"`
+	WorkoutCount int        `json:"workout_count"`
+	CreatedAt    *time.Time `json:"created_at"`
+}
+
+type TagStore interface {
+	GetTags(userID int) ([]Tag, error)
+}
+
+type PostgresTagStore struct {
+	db *sql.DB
+}
+
+func NewPostgresTagStore(db *sql.DB) *PostgresTagStore {
+	return &PostgresTagStore{
+		db: db,
+	}
+}
+
+// GetTags lists the tags of the user, the most used first.
+func (s *PostgresTagStore) GetTags(userID int) ([]Tag, error) {
+	query := `
+		select tags.id, tags.name, count(workouts.id), tags.created_at
+		from tags
+		left join workout_tags on workout_tags.tag_id = tags.id
+		left join workouts on workouts.id = workout_tags.workout_id and workouts.deleted_at is null
+		where tags.user_id = $1
+		group by tags.id
+		order by count(workouts.id) desc, lower(tags.name)
+	`
+
+	tags := make([]Tag, 0)
+
+	err := scanRows(s.db, query, []any{userID}, func(rows *sql.Rows) error {
+		tag := Tag{}
+
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.WorkoutCount, &tag.CreatedAt); err != nil {
+			return err
+		}
+
+		tags = append(tags, tag)
+
+		return nil
+	})
+
+	return tags, err
+}
+
+// NormalizeTags trims the names and drops empty ones and repeated ones,
+// ignoring case.
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(tag), " ")
+		key := strings.ToLower(tag)
+
+		if tag == "" || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+// setWorkoutTags replaces the tags of a workout. Tags the user never used are
+// created; existing ones are matched ignoring case and keep their name.
+func setWorkoutTags(tx *sql.Tx, workoutID int, userID int, tags []string) error {
+	tags = NormalizeTags(tags)
+
+	_, err := tx.Exec("delete from workout_tags where workout_id = $1", workoutID)
+
+	if err != nil || len(tags) == 0 {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		insert into tags (user_id, name)
+		select $1, name from unnest($2::text[]) as name
+		on conflict (user_id, lower(name)) do nothing
+	`, userID, tags)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		insert into workout_tags (workout_id, tag_id)
+		select $1, id from tags
+		where user_id = $2 and lower(name) in (select lower(name) from unnest($3::text[]) as name)
+	`, workoutID, userID, tags)
+
+	return err
+}
+
+// loadWorkoutTags fills in the tag names of the workouts, alphabetically.
+func loadWorkoutTags(q queryer, workouts []Workout) error {
+	workoutIDs := make([]int, len(workouts))
+	workoutsByID := make(map[int]*Workout, len(workouts))
+
+	for i := range workouts {
+		workoutIDs[i] = workouts[i].ID
+		workoutsByID[workouts[i].ID] = &workouts[i]
+		workouts[i].Tags = make([]string, 0)
+	}
+
+	if len(workouts) == 0 {
+		return nil
+	}
+
+	query := `
+		select workout_tags.workout_id, tags.name
+		from workout_tags
+		join tags on tags.id = workout_tags.tag_id
+		where workout_tags.workout_id = any($1)
+		order by lower(tags.name)
+	`
+
+	return scanRows(q, query, []any{workoutIDs}, func(rows *sql.Rows) error {
+		var workoutID int
+		var name string
+
+		if err := rows.Scan(&workoutID, &name); err != nil {
+			return err
+		}
+
+		workout := workoutsByID[workoutID]
+		workout.Tags = append(workout.Tags, name)
+
+		return nil
+	})
+}
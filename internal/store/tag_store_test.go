@@ -0,0 +1,65 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	"partiuFit/internal/utils"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	workoutStore := NewPostgresWorkoutStore(db)
+	tagStore := NewPostgresTagStore(db)
+
+	push := utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Push", DurationMinutes: 60, UserID: user.ID, Tags: []string{"Deload", " travel "}}))
+	legs := utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Legs", DurationMinutes: 60, UserID: user.ID, Tags: []string{"deload"}}))
+	utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Pull", DurationMinutes: 60, UserID: user.ID}))
+
+	t.Run("Tags are matched ignoring case", func(t *testing.T) {
+		workout := utils.Must(workoutStore.GetWorkoutById(legs.ID))
+		assert.Equal(t, []string{"Deload"}, workout.Tags)
+
+		tags, err := tagStore.GetTags(user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, tags, 2)
+		assert.Equal(t, "Deload", tags[0].Name)
+		assert.Equal(t, 2, tags[0].WorkoutCount)
+		assert.Equal(t, "travel", tags[1].Name)
+	})
+
+	t.Run("Filter by any or all tags", func(t *testing.T) {
+		workouts, _, err := workoutStore.GetAllWorkouts(user.ID, WorkoutFilters{Limit: 10, Tags: []string{"DELOAD", "travel"}})
+		assert.NoError(t, err)
+		assert.Len(t, workouts, 2)
+
+		workouts, _, err = workoutStore.GetAllWorkouts(user.ID, WorkoutFilters{Limit: 10, Tags: []string{"DELOAD", "travel"}, TagMatch: TagMatchAll})
+		assert.NoError(t, err)
+		assert.Len(t, workouts, 1)
+		assert.Equal(t, push.ID, workouts[0].ID)
+		assert.Equal(t, []string{"Deload", "travel"}, workouts[0].Tags)
+	})
+
+	t.Run("Updating replaces the tags", func(t *testing.T) {
+		workout := utils.Must(workoutStore.GetWorkoutById(push.ID))
+		workout.Tags = []string{}
+		utils.Must(workoutStore.UpdateWorkout(push.ID, workout))
+
+		assert.Empty(t, utils.Must(workoutStore.GetWorkoutById(push.ID)).Tags)
+
+		tags := utils.Must(tagStore.GetTags(user.ID))
+		assert.Equal(t, 1, tags[0].WorkoutCount)
+		assert.Equal(t, 0, tags[1].WorkoutCount)
+	})
+}
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"Deload", "travel week"}, NormalizeTags([]string{" Deload", "travel   week", "", "deload"}))
+	assert.Empty(t, NormalizeTags(nil))
+}
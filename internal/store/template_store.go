@@ -0,0 +1,407 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/requests"
+	"time"
+)
+
+type WorkoutTemplate struct {
+	ID                       int                    `json:"id"`
+	UserID                   int                    `json:"user_id"`
+	Title                    string                 `json:"title"`
+	Description              string                 `json:"description"`
+	EstimatedDurationMinutes *int                   `json:"estimated_duration_minutes"`
+	Entries                  []WorkoutTemplateEntry `json:"entries"`
+	CreatedAt                *time.Time             `json:"created_at"`
+	UpdatedAt                *time.Time             `json:"updated_at"`
+}
+
+type WorkoutTemplateEntry struct {
+	ID                    int        `json:"id"`
+	ExerciseID            *int       `json:"exercise_id"`
+	ExerciseName          string     `json:"exercise_name"`
+	TargetSets            int        `json:"target_sets"`
+	TargetReps            *int       `json:"target_reps"`
+	TargetDurationSeconds *int       `json:"target_duration_seconds"`
+	TargetWeight          float64    `json:"target_weight"`
+	RestSeconds           *int       `json:"rest_seconds"`
+	Notes                 string     `json:"notes"`
+	OrderIndex            int        `json:"order_index"`
+	CreatedAt             *time.Time `json:"created_at"`
+	UpdatedAt             *time.Time `json:"updated_at"`
+}
+
+func (t *WorkoutTemplate) FromTemplateRequest(templateRequest *requests.WorkoutTemplateRequest) *WorkoutTemplate {
+	t.Title = templateRequest.Title
+	t.Description = templateRequest.Description
+	t.EstimatedDurationMinutes = templateRequest.EstimatedDurationMinutes
+	t.Entries = make([]WorkoutTemplateEntry, 0, len(templateRequest.Entries))
+
+	for i, entryRequest := range templateRequest.Entries {
+		t.Entries = append(t.Entries, WorkoutTemplateEntry{
+			ExerciseID:            entryRequest.ExerciseID,
+			ExerciseName:          entryRequest.ExerciseName,
+			TargetSets:            entryRequest.TargetSets,
+			TargetReps:            entryRequest.TargetReps,
+			TargetDurationSeconds: entryRequest.TargetDurationSeconds,
+			TargetWeight:          entryRequest.TargetWeight,
+			RestSeconds:           entryRequest.RestSeconds,
+			Notes:                 entryRequest.Notes,
+			OrderIndex:            i + 1,
+		})
+	}
+
+	return t
+}
+
+// ToWorkout copies the planned values into a new planned workout, ready to be
+// started as a session. Its entries are expanded into sets that are not done
+// yet. Nothing is shared with the template, so editing it later never touches
+// workouts started from it.
+func (t *WorkoutTemplate) ToWorkout(userID int) *Workout {
+	workout := &Workout{
+		Title:       t.Title,
+		Description: t.Description,
+		UserID:      userID,
+		TemplateID:  &t.ID,
+		Status:      WorkoutPlanned,
+		Entries:     make([]WorkoutEntry, 0, len(t.Entries)),
+	}
+
+	if t.EstimatedDurationMinutes != nil {
+		workout.DurationMinutes = *t.EstimatedDurationMinutes
+	}
+
+	for _, templateEntry := range t.Entries {
+		entry := WorkoutEntry{
+			ExerciseName: templateEntry.ExerciseName,
+			Sets:         templateEntry.TargetSets,
+			Weight:       templateEntry.TargetWeight,
+			Notes:        templateEntry.Notes,
+			OrderIndex:   templateEntry.OrderIndex,
+			UserID:       userID,
+		}
+
+		if templateEntry.ExerciseID != nil {
+			entry.ExerciseID = copyPointer(templateEntry.ExerciseID)
+		}
+
+		if templateEntry.TargetReps != nil {
+			entry.Reps = copyPointer(templateEntry.TargetReps)
+		} else {
+			entry.DurationSeconds = copyPointer(templateEntry.TargetDurationSeconds)
+		}
+
+		workout.Entries = append(workout.Entries, entry)
+	}
+
+	return workout
+}
+
+func copyPointer[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+
+	copied := *value
+
+	return &copied
+}
+
+type TemplateStore interface {
+	CreateTemplate(template *WorkoutTemplate) (*WorkoutTemplate, error)
+	UpdateTemplate(id int, template *WorkoutTemplate) (*WorkoutTemplate, error)
+	GetTemplateById(id int) (*WorkoutTemplate, error)
+	DeleteTemplate(id int) error
+	GetAllTemplates(userID int) ([]WorkoutTemplate, error)
+	OwnsTemplate(id int, userID int) (bool, error)
+}
+
+type PostgresTemplateStore struct {
+	db *sql.DB
+}
+
+func NewPostgresTemplateStore(db *sql.DB) *PostgresTemplateStore {
+	return &PostgresTemplateStore{
+		db: db,
+	}
+}
+
+func (s *PostgresTemplateStore) CreateTemplate(template *WorkoutTemplate) (*WorkoutTemplate, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		insert into workout_templates (user_id, title, description, estimated_duration_minutes)
+		values ($1, $2, $3, $4)
+		returning id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		template.UserID,
+		template.Title,
+		template.Description,
+		template.EstimatedDurationMinutes).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range template.Entries {
+		err := insertTemplateEntry(tx, template, &template.Entries[i])
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *PostgresTemplateStore) UpdateTemplate(id int, template *WorkoutTemplate) (*WorkoutTemplate, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		update workout_templates
+		set title = $2, description = $3, estimated_duration_minutes = $4, updated_at = now()
+		where id = $1
+		returning user_id, created_at, updated_at
+	`
+
+	template.ID = id
+
+	err = tx.QueryRow(
+		query,
+		id,
+		template.Title,
+		template.Description,
+		template.EstimatedDurationMinutes).Scan(&template.UserID, &template.CreatedAt, &template.UpdatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("delete from workout_template_entries where template_id = $1", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range template.Entries {
+		err := insertTemplateEntry(tx, template, &template.Entries[i])
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// insertTemplateEntry links the entry to the catalog by name the same way workout entries are.
+func insertTemplateEntry(tx *sql.Tx, template *WorkoutTemplate, entry *WorkoutTemplateEntry) error {
+	query := fmt.Sprintf(`
+		insert into workout_template_entries (
+			template_id, exercise_name, target_sets, target_reps, target_duration_seconds,
+			target_weight, rest_seconds, notes, order_index, exercise_id
+		)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, coalesce($10, (
+			select id from exercises
+			where (user_id is null or user_id = $11) and %s
+			order by user_id nulls last, id
+			limit 1
+		)))
+		returning id, exercise_id, created_at, updated_at
+	`, exerciseMatchesName)
+
+	return tx.QueryRow(
+		query,
+		template.ID,
+		entry.ExerciseName,
+		entry.TargetSets,
+		entry.TargetReps,
+		entry.TargetDurationSeconds,
+		entry.TargetWeight,
+		entry.RestSeconds,
+		entry.Notes,
+		entry.OrderIndex,
+		entry.ExerciseID,
+		template.UserID).Scan(&entry.ID, &entry.ExerciseID, &entry.CreatedAt, &entry.UpdatedAt)
+}
+
+func (s *PostgresTemplateStore) GetTemplateById(id int) (*WorkoutTemplate, error) {
+	template := &WorkoutTemplate{}
+	query := `
+		select id, user_id, title, description, estimated_duration_minutes, created_at, updated_at
+		from workout_templates
+		where id = $1
+	`
+
+	err := s.db.QueryRow(query, id).Scan(
+		&template.ID,
+		&template.UserID,
+		&template.Title,
+		&template.Description,
+		&template.EstimatedDurationMinutes,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entriesQuery := `
+		select id, exercise_id, exercise_name, target_sets, target_reps, target_duration_seconds,
+		       target_weight, rest_seconds, notes, order_index, created_at, updated_at
+		from workout_template_entries
+		where template_id = $1
+		order by order_index
+	`
+
+	rows, err := s.db.Query(entriesQuery, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	template.Entries = make([]WorkoutTemplateEntry, 0)
+
+	for rows.Next() {
+		entry := WorkoutTemplateEntry{}
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ExerciseID,
+			&entry.ExerciseName,
+			&entry.TargetSets,
+			&entry.TargetReps,
+			&entry.TargetDurationSeconds,
+			&entry.TargetWeight,
+			&entry.RestSeconds,
+			&entry.Notes,
+			&entry.OrderIndex,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		template.Entries = append(template.Entries, entry)
+	}
+
+	return template, rows.Err()
+}
+
+func (s *PostgresTemplateStore) DeleteTemplate(id int) error {
+	result, err := s.db.Exec("delete from workout_templates where id = $1", id)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return internalErrors.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetAllTemplates lists the templates of a user without their entries.
+func (s *PostgresTemplateStore) GetAllTemplates(userID int) ([]WorkoutTemplate, error) {
+	query := `
+		select id, user_id, title, description, estimated_duration_minutes, created_at, updated_at
+		from workout_templates
+		where user_id = $1
+		order by title, id
+	`
+
+	rows, err := s.db.Query(query, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var templates = make([]WorkoutTemplate, 0)
+
+	for rows.Next() {
+		template := WorkoutTemplate{}
+
+		err := rows.Scan(
+			&template.ID,
+			&template.UserID,
+			&template.Title,
+			&template.Description,
+			&template.EstimatedDurationMinutes,
+			&template.CreatedAt,
+			&template.UpdatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+func (s *PostgresTemplateStore) OwnsTemplate(id int, userID int) (bool, error) {
+	var owns bool
+
+	query := `
+		select exists(select 1 from workout_templates where id = $1 and user_id = $2)
+	`
+
+	err := s.db.QueryRow(query, id, userID).Scan(&owns)
+
+	return owns, err
+}
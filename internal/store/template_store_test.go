@@ -0,0 +1,105 @@
+package store
+
+import (
+	"errors"
+	testingUtils "partiuFit/internal/database/testing_utils"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/utils"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	jane, _ := testingUtils.CreateToken(db, "janedoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	templateStore := NewPostgresTemplateStore(db)
+	workoutStore := NewPostgresWorkoutStore(db)
+
+	newTemplate := func() *WorkoutTemplate {
+		return &WorkoutTemplate{
+			UserID:                   user.ID,
+			Title:                    "Push",
+			Description:              "Chest, shoulders and triceps",
+			EstimatedDurationMinutes: utils.ValueToPointer(60),
+			Entries: []WorkoutTemplateEntry{
+				{ExerciseName: "Supino reto", TargetSets: 4, TargetReps: utils.ValueToPointer(8), TargetWeight: 80, OrderIndex: 1},
+				{ExerciseName: "Plank", TargetSets: 3, TargetDurationSeconds: utils.ValueToPointer(60), OrderIndex: 2},
+			},
+		}
+	}
+
+	t.Run("Create with valid data", func(t *testing.T) {
+		createdTemplate, err := templateStore.CreateTemplate(newTemplate())
+
+		assert.NoError(t, err)
+		assert.NotZero(t, createdTemplate.ID)
+		assert.Len(t, createdTemplate.Entries, 2)
+		assert.NotNil(t, createdTemplate.Entries[0].ExerciseID, "entry should be linked to the catalog by alias")
+	})
+
+	t.Run("Get by id and get all", func(t *testing.T) {
+		createdTemplate := utils.Must(templateStore.CreateTemplate(newTemplate()))
+
+		retrievedTemplate, err := templateStore.GetTemplateById(createdTemplate.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Push", retrievedTemplate.Title)
+		assert.Equal(t, 80.0, retrievedTemplate.Entries[0].TargetWeight)
+
+		templates, err := templateStore.GetAllTemplates(user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, templates, 2)
+
+		templates, err = templateStore.GetAllTemplates(jane.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, templates)
+	})
+
+	t.Run("Started workouts do not follow template edits", func(t *testing.T) {
+		template := utils.Must(templateStore.CreateTemplate(newTemplate()))
+
+		workout, err := workoutStore.CreateWorkout(template.ToWorkout(user.ID))
+		assert.NoError(t, err)
+		assert.Equal(t, template.ID, *workout.TemplateID)
+		assert.Equal(t, 60, workout.DurationMinutes)
+		assert.Equal(t, WorkoutPlanned, workout.Status)
+		assert.False(t, workout.Entries[0].WorkoutSets[0].Completed)
+		assert.Empty(t, workout.PersonalRecords)
+
+		template.Entries[0].TargetWeight = 100
+		_, err = templateStore.UpdateTemplate(template.ID, template)
+		assert.NoError(t, err)
+
+		retrievedWorkout := utils.Must(workoutStore.GetWorkoutById(workout.ID))
+		assert.Equal(t, 80.0, retrievedWorkout.Entries[0].Weight)
+		assert.Len(t, retrievedWorkout.Entries[0].WorkoutSets, 4)
+		assert.Equal(t, 60, *retrievedWorkout.Entries[1].DurationSeconds)
+	})
+
+	t.Run("Ownership", func(t *testing.T) {
+		template := utils.Must(templateStore.CreateTemplate(newTemplate()))
+
+		assert.True(t, utils.Must(templateStore.OwnsTemplate(template.ID, user.ID)))
+		assert.False(t, utils.Must(templateStore.OwnsTemplate(template.ID, jane.ID)))
+	})
+
+	t.Run("Delete keeps started workouts", func(t *testing.T) {
+		template := utils.Must(templateStore.CreateTemplate(newTemplate()))
+		workout := utils.Must(workoutStore.CreateWorkout(template.ToWorkout(user.ID)))
+
+		err := templateStore.DeleteTemplate(template.ID)
+		assert.NoError(t, err)
+
+		err = templateStore.DeleteTemplate(template.ID)
+		assert.True(t, errors.Is(err, internalErrors.ErrNoRows))
+
+		retrievedWorkout, err := workoutStore.GetWorkoutById(workout.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, retrievedWorkout.TemplateID)
+	})
+}
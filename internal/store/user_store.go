@@ -16,25 +16,51 @@ var (
 )
 
 type User struct {
-	ID        int                    `json:"id"`
-	Name      string                 `json:"name"`
-	Username  string                 `json:"username"`
-	Password  *valueObjects.Password `json:"-"`
-	Email     string                 `json:"email"`
-	CreatedAt *time.Time             `json:"created_at"`
-	UpdatedAt *time.Time             `json:"updated_at"`
+	ID                int                    `json:"id"`
+	Name              string                 `json:"name"`
+	Username          string                 `json:"username"`
+	Password          *valueObjects.Password `json:"-"`
+	Email             string                 `json:"email"`
+	TimeZone          string                 `json:"time_zone"`
+	UnitSystem        string                 `json:"unit_system"`
+	DefaultVisibility string                 `json:"default_visibility"`
+	CreatedAt         *time.Time             `json:"created_at"`
+	UpdatedAt         *time.Time             `json:"updated_at"`
 }
 
 func (u *User) IsAnonymous() bool {
 	return u == AnonymousUser
 }
 
+// Location returns the user's time zone, falling back to UTC for unknown zones.
+func (u *User) Location() *time.Location {
+	location, err := time.LoadLocation(u.TimeZone)
+
+	if err != nil || u.TimeZone == "" {
+		return time.UTC
+	}
+
+	return location
+}
+
 func (u *User) FromUserRequest(userRequest *requests.UserRequest) *User {
 	u.Name = userRequest.Name
 	u.Username = userRequest.Username
 	u.Email = userRequest.Email
 	u.Password = valueObjects.NewPassword(userRequest.Password)
 
+	if userRequest.TimeZone != "" {
+		u.TimeZone = userRequest.TimeZone
+	}
+
+	if userRequest.UnitSystem != "" {
+		u.UnitSystem = userRequest.UnitSystem
+	}
+
+	if userRequest.DefaultVisibility != "" {
+		u.DefaultVisibility = userRequest.DefaultVisibility
+	}
+
 	return u
 }
 
@@ -64,11 +90,11 @@ func (s *UserPostgresStore) GetUserByUsername(username string) (*User, error) {
 		Password: &valueObjects.Password{},
 	}
 	query := `
-		select id, name, username, password, email
+		select id, name, username, password, email, time_zone, unit_system, default_visibility
 		from users
 		where username = $1
 	`
-	err := s.db.QueryRow(query, username).Scan(&user.ID, &user.Name, &user.Username, &user.Password.Hash, &user.Email)
+	err := s.db.QueryRow(query, username).Scan(&user.ID, &user.Name, &user.Username, &user.Password.Hash, &user.Email, &user.TimeZone, &user.UnitSystem, &user.DefaultVisibility)
 
 	if err != nil {
 		return nil, err
@@ -79,12 +105,13 @@ func (s *UserPostgresStore) GetUserByUsername(username string) (*User, error) {
 
 func (s *UserPostgresStore) CreateUser(user *User) error {
 	query := `
-		insert into users (name, username, password, email)
-		values ($1, $2, $3, $4)
-		returning id, created_at, updated_at
+		insert into users (name, username, password, email, time_zone, unit_system, default_visibility)
+		values ($1, $2, $3, $4, coalesce(nullif($5, ''), 'UTC'), coalesce(nullif($6, ''), 'metric'), coalesce(nullif($7, ''), 'private'))
+		returning id, time_zone, unit_system, default_visibility, created_at, updated_at
 	`
 
-	err := s.db.QueryRow(query, user.Name, user.Username, user.Password.GetHash(), user.Email).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	err := s.db.QueryRow(query, user.Name, user.Username, user.Password.GetHash(), user.Email, user.TimeZone, user.UnitSystem, user.DefaultVisibility).
+		Scan(&user.ID, &user.TimeZone, &user.UnitSystem, &user.DefaultVisibility, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		return internalErrors.HandleDatabaseError(err)
@@ -96,11 +123,12 @@ func (s *UserPostgresStore) CreateUser(user *User) error {
 func (s *UserPostgresStore) UpdateUser(id int, user *User) error {
 	query := `
 		update users
-		set name = $2, username = $3, password = $4, email = $5
+		set name = $2, username = $3, password = $4, email = $5, time_zone = coalesce(nullif($6, ''), time_zone),
+		    unit_system = coalesce(nullif($7, ''), unit_system), default_visibility = coalesce(nullif($8, ''), default_visibility)
 		where id = $1
 	`
 
-	result, err := s.db.Exec(query, id, user.Name, user.Username, user.Password.GetHash(), user.Email)
+	result, err := s.db.Exec(query, id, user.Name, user.Username, user.Password.GetHash(), user.Email, user.TimeZone, user.UnitSystem, user.DefaultVisibility)
 
 	if err != nil {
 		return err
@@ -123,7 +151,7 @@ func (s *UserPostgresStore) GetUserFromToken(scope, token string) (*User, error)
 	hash := sha256.Sum256([]byte(token))
 
 	query := `
-		select id, name, username, password, email
+		select id, name, username, password, email, time_zone, unit_system, default_visibility
 		from users where exists (
 		    select 1 from tokens 
 		             where 
@@ -137,7 +165,7 @@ func (s *UserPostgresStore) GetUserFromToken(scope, token string) (*User, error)
 		Password: &valueObjects.Password{},
 	}
 
-	err := s.db.QueryRow(query, scope, hash[:], time.Now()).Scan(&user.ID, &user.Name, &user.Username, &user.Password.Hash, &user.Email)
+	err := s.db.QueryRow(query, scope, hash[:], time.Now()).Scan(&user.ID, &user.Name, &user.Username, &user.Password.Hash, &user.Email, &user.TimeZone, &user.UnitSystem, &user.DefaultVisibility)
 
 	if err != nil {
 		return nil, err
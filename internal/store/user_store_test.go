@@ -37,6 +37,29 @@ func TestUserStore(t *testing.T) {
 		assert.NotZero(t, user.ID)
 		assert.NotNil(t, user.CreatedAt)
 		assert.NotNil(t, user.UpdatedAt)
+		assert.Equal(t, "UTC", user.TimeZone)
+		assert.Equal(t, "metric", user.UnitSystem)
+	})
+
+	t.Run("CreateUser with time zone and unit system", func(t *testing.T) {
+		user := &User{
+			Name:     "Zoned User",
+			Username: "zoneduser",
+			Password: &valueObjects.Password{
+				PlainText: "password123",
+			},
+			Email:      "zoned@example.com",
+			TimeZone:   "America/Sao_Paulo",
+			UnitSystem: "imperial",
+		}
+
+		utils.MustIfError(user.HashPassword())
+		assert.NoError(t, userStore.CreateUser(user))
+
+		retrievedUser, err := userStore.GetUserByUsername("zoneduser")
+		assert.NoError(t, err)
+		assert.Equal(t, "America/Sao_Paulo", retrievedUser.Location().String())
+		assert.Equal(t, "imperial", retrievedUser.UnitSystem)
 	})
 
 	t.Run("CreateUser with duplicate username", func(t *testing.T) {
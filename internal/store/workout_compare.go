@@ -0,0 +1,160 @@
+package store
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	ExerciseRepeated = "repeated"
+	ExerciseAdded    = "added"
+	ExerciseRemoved  = "removed"
+)
+
+// ExerciseTotals sums the completed, non warm-up sets of an exercise, like
+// personal records and stats do.
+type ExerciseTotals struct {
+	Sets      int     `json:"sets"`
+	Reps      int     `json:"reps"`
+	TopWeight float64 `json:"top_weight"`
+	Volume    float64 `json:"volume"`
+}
+
+type ExerciseComparison struct {
+	ExerciseID   *int           `json:"exercise_id"`
+	ExerciseName string         `json:"exercise_name"`
+	Status       string         `json:"status"`
+	A            ExerciseTotals `json:"a"`
+	B            ExerciseTotals `json:"b"`
+	Delta        ExerciseTotals `json:"delta"`
+}
+
+type ComparedWorkout struct {
+	ID        int        `json:"id"`
+	Title     string     `json:"title"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+// WorkoutComparison tells what changed from workout A to workout B. Deltas are
+// B minus A, so a positive delta is an improvement.
+type WorkoutComparison struct {
+	A         ComparedWorkout      `json:"a"`
+	B         ComparedWorkout      `json:"b"`
+	Exercises []ExerciseComparison `json:"exercises"`
+	Totals    ExerciseComparison   `json:"totals"`
+}
+
+// CompareWorkouts matches the entries of two workouts by exercise, the same way
+// personal records do. Exercises come in the order of B, followed by the ones
+// only A had.
+func CompareWorkouts(a, b *Workout) *WorkoutComparison {
+	comparison := &WorkoutComparison{
+		A:         ComparedWorkout{ID: a.ID, Title: a.Title, CreatedAt: a.CreatedAt},
+		B:         ComparedWorkout{ID: b.ID, Title: b.Title, CreatedAt: b.CreatedAt},
+		Exercises: make([]ExerciseComparison, 0),
+	}
+
+	indexes := make(map[string]int)
+
+	exerciseFor := func(entry *WorkoutEntry) *ExerciseComparison {
+		key := entryExerciseKey(entry)
+		index, seen := indexes[key]
+
+		if !seen {
+			index = len(comparison.Exercises)
+			indexes[key] = index
+			comparison.Exercises = append(comparison.Exercises, ExerciseComparison{
+				ExerciseID:   entry.ExerciseID,
+				ExerciseName: entry.ExerciseName,
+			})
+		}
+
+		return &comparison.Exercises[index]
+	}
+
+	inA, inB := make(map[string]bool), make(map[string]bool)
+
+	for i := range b.Entries {
+		inB[entryExerciseKey(&b.Entries[i])] = true
+		exercise := exerciseFor(&b.Entries[i])
+		exercise.B = exercise.B.add(entryTotals(&b.Entries[i]))
+		comparison.Totals.B = comparison.Totals.B.add(entryTotals(&b.Entries[i]))
+	}
+
+	for i := range a.Entries {
+		inA[entryExerciseKey(&a.Entries[i])] = true
+		exercise := exerciseFor(&a.Entries[i])
+		exercise.A = exercise.A.add(entryTotals(&a.Entries[i]))
+		comparison.Totals.A = comparison.Totals.A.add(entryTotals(&a.Entries[i]))
+	}
+
+	for key, index := range indexes {
+		exercise := &comparison.Exercises[index]
+		exercise.Delta = exercise.B.subtract(exercise.A)
+
+		switch {
+		case !inA[key]:
+			exercise.Status = ExerciseAdded
+		case !inB[key]:
+			exercise.Status = ExerciseRemoved
+		default:
+			exercise.Status = ExerciseRepeated
+		}
+	}
+
+	comparison.Totals.Delta = comparison.Totals.B.subtract(comparison.Totals.A)
+
+	return comparison
+}
+
+func entryExerciseKey(entry *WorkoutEntry) string {
+	if entry.ExerciseID != nil {
+		return ExerciseKey(&Exercise{ID: *entry.ExerciseID}, "")
+	}
+
+	return ExerciseKey(nil, entry.ExerciseName)
+}
+
+func entryTotals(entry *WorkoutEntry) ExerciseTotals {
+	totals := ExerciseTotals{}
+
+	for _, set := range entry.WorkoutSets {
+		if !set.Completed || set.SetType == SetTypeWarmUp {
+			continue
+		}
+
+		totals.Sets++
+		totals.TopWeight = max(totals.TopWeight, set.Weight)
+
+		if set.Reps != nil {
+			totals.Reps += *set.Reps
+			totals.Volume += float64(*set.Reps) * set.Weight
+		}
+	}
+
+	totals.Volume = roundVolume(totals.Volume)
+
+	return totals
+}
+
+func (t ExerciseTotals) add(other ExerciseTotals) ExerciseTotals {
+	return ExerciseTotals{
+		Sets:      t.Sets + other.Sets,
+		Reps:      t.Reps + other.Reps,
+		TopWeight: max(t.TopWeight, other.TopWeight),
+		Volume:    roundVolume(t.Volume + other.Volume),
+	}
+}
+
+func (t ExerciseTotals) subtract(other ExerciseTotals) ExerciseTotals {
+	return ExerciseTotals{
+		Sets:      t.Sets - other.Sets,
+		Reps:      t.Reps - other.Reps,
+		TopWeight: roundVolume(t.TopWeight - other.TopWeight),
+		Volume:    roundVolume(t.Volume - other.Volume),
+	}
+}
+
+func roundVolume(value float64) float64 {
+	return math.Round(value*100) / 100
+}
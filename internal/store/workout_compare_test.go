@@ -0,0 +1,82 @@
+package store
+
+import (
+	"partiuFit/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareWorkouts(t *testing.T) {
+	set := func(setType string, reps int, weight float64) WorkoutSet {
+		return WorkoutSet{SetType: setType, Reps: utils.ValueToPointer(reps), Weight: weight, Completed: true}
+	}
+
+	a := &Workout{ID: 1, Entries: []WorkoutEntry{
+		{ExerciseID: utils.ValueToPointer(7), ExerciseName: "Bench Press", WorkoutSets: []WorkoutSet{
+			set(SetTypeWarmUp, 10, 40), set(SetTypeWorking, 8, 80), set(SetTypeWorking, 8, 80),
+		}},
+		{ExerciseName: "Dips", WorkoutSets: []WorkoutSet{set(SetTypeWorking, 12, 0)}},
+	}}
+	b := &Workout{ID: 2, Entries: []WorkoutEntry{
+		{ExerciseID: utils.ValueToPointer(7), ExerciseName: "Bench Press", WorkoutSets: []WorkoutSet{
+			set(SetTypeWorking, 8, 82.5), set(SetTypeWorking, 7, 82.5), set(SetTypeDrop, 10, 60),
+		}},
+		{ExerciseName: "Cable Fly", WorkoutSets: []WorkoutSet{set(SetTypeWorking, 15, 20)}},
+	}}
+
+	comparison := CompareWorkouts(a, b)
+
+	assert.Len(t, comparison.Exercises, 3)
+
+	bench := comparison.Exercises[0]
+	assert.Equal(t, ExerciseRepeated, bench.Status)
+	assert.Equal(t, ExerciseTotals{Sets: 2, Reps: 16, TopWeight: 80, Volume: 1280}, bench.A)
+	assert.Equal(t, ExerciseTotals{Sets: 3, Reps: 25, TopWeight: 82.5, Volume: 1837.5}, bench.B)
+	assert.Equal(t, ExerciseTotals{Sets: 1, Reps: 9, TopWeight: 2.5, Volume: 557.5}, bench.Delta)
+
+	assert.Equal(t, "Cable Fly", comparison.Exercises[1].ExerciseName)
+	assert.Equal(t, ExerciseAdded, comparison.Exercises[1].Status)
+	assert.Equal(t, "Dips", comparison.Exercises[2].ExerciseName)
+	assert.Equal(t, ExerciseRemoved, comparison.Exercises[2].Status)
+	assert.Equal(t, -12, comparison.Exercises[2].Delta.Reps)
+
+	assert.Equal(t, 4, comparison.Totals.B.Sets)
+	assert.Equal(t, 857.5, comparison.Totals.Delta.Volume)
+}
+
+func TestDuplicateWorkout(t *testing.T) {
+	workout := &Workout{
+		ID:                3,
+		Title:             "Push",
+		CaloriesBurned:    250,
+		CaloriesEstimated: true,
+		UserID:            1,
+		Status:            WorkoutPlanned,
+		Entries: []WorkoutEntry{{
+			ID:           9,
+			ExerciseName: "Bench Press",
+			Group:        &EntryGroup{ID: 1, Type: GroupSuperset, Rounds: 3},
+			WorkoutSets:  []WorkoutSet{{ID: 20, SetType: SetTypeWorking, Reps: utils.ValueToPointer(8), Weight: 80, Completed: true}},
+		}},
+	}
+
+	duplicate := workout.Duplicate(2, nil, false)
+
+	assert.Zero(t, duplicate.ID)
+	assert.Equal(t, 2, duplicate.UserID)
+	assert.Equal(t, WorkoutPlanned, duplicate.Status)
+	assert.False(t, duplicate.Entries[0].WorkoutSets[0].Completed)
+	assert.Zero(t, duplicate.CaloriesBurned, "estimated calories are estimated again")
+	assert.Zero(t, duplicate.Entries[0].ID)
+	assert.Zero(t, duplicate.Entries[0].WorkoutSets[0].ID)
+	assert.Equal(t, 2, duplicate.Entries[0].UserID)
+
+	duplicate.Entries[0].Group.Rounds = 5
+	assert.Equal(t, 3, workout.Entries[0].Group.Rounds, "the copy does not share its group")
+
+	finished := workout.Duplicate(2, nil, true)
+
+	assert.Equal(t, WorkoutCompleted, finished.Status)
+	assert.True(t, finished.Entries[0].WorkoutSets[0].Completed)
+}
@@ -0,0 +1,379 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	internalErrors "partiuFit/internal/errors"
+	"slices"
+)
+
+// WorkoutEntryStore edits the entries of a workout one at a time, instead of
+// sending every entry to UpdateWorkout. Entries and their sets keep their ids
+// across edits. Each change updates personal records, goals, estimated
+// calories and the revision history of the workout.
+type WorkoutEntryStore interface {
+	GetEntries(workoutID int) ([]WorkoutEntry, error)
+	GetEntry(workoutID int, entryID int) (*WorkoutEntry, error)
+	CreateEntry(workoutID int, entry *WorkoutEntry) (*WorkoutEntry, error)
+	UpdateEntry(workoutID int, entry *WorkoutEntry) (*WorkoutEntry, error)
+	DeleteEntry(workoutID int, entryID int) error
+	ReorderEntries(workoutID int, entryIDs []int) ([]WorkoutEntry, error)
+}
+
+type PostgresWorkoutEntryStore struct {
+	db *sql.DB
+}
+
+func NewPostgresWorkoutEntryStore(db *sql.DB) *PostgresWorkoutEntryStore {
+	return &PostgresWorkoutEntryStore{
+		db: db,
+	}
+}
+
+func (s *PostgresWorkoutEntryStore) GetEntries(workoutID int) ([]WorkoutEntry, error) {
+	return getWorkoutEntries(s.db, workoutID)
+}
+
+func (s *PostgresWorkoutEntryStore) GetEntry(workoutID int, entryID int) (*WorkoutEntry, error) {
+	entry := WorkoutEntry{}
+	query := fmt.Sprintf(`select %s from workout_entries where id = $1 and workout_id = $2`, workoutEntryColumns)
+
+	if err := scanWorkoutEntry(s.db.QueryRow(query, entryID, workoutID), &entry); err != nil {
+		return nil, err
+	}
+
+	entries := []WorkoutEntry{entry}
+
+	if err := loadWorkoutSets(s.db, entries); err != nil {
+		return nil, err
+	}
+
+	return &entries[0], nil
+}
+
+// CreateEntry appends the entry to the workout, unless it comes with its own
+// order_index.
+func (s *PostgresWorkoutEntryStore) CreateEntry(workoutID int, entry *WorkoutEntry) (*WorkoutEntry, error) {
+	var created *WorkoutEntry
+
+	err := s.changeEntries(workoutID, func(tx *sql.Tx, status string) error {
+		if entry.OrderIndex == 0 {
+			err := tx.QueryRow(
+				"select coalesce(max(order_index), 0) + 1 from workout_entries where workout_id = $1", workoutID,
+			).Scan(&entry.OrderIndex)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		created = entry
+
+		return insertWorkoutEntry(tx, workoutID, status, entry)
+	})
+
+	return created, err
+}
+
+// UpdateEntry overwrites the entry in place. Sets with the id of one of its
+// sets are updated, sets without an id are added and the missing ones removed.
+func (s *PostgresWorkoutEntryStore) UpdateEntry(workoutID int, entry *WorkoutEntry) (*WorkoutEntry, error) {
+	err := s.changeEntries(workoutID, func(tx *sql.Tx, status string) error {
+		return updateWorkoutEntry(tx, workoutID, status, entry)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (s *PostgresWorkoutEntryStore) DeleteEntry(workoutID int, entryID int) error {
+	return s.changeEntries(workoutID, func(tx *sql.Tx, _ string) error {
+		result, err := tx.Exec("delete from workout_entries where id = $1 and workout_id = $2", entryID, workoutID)
+
+		if err != nil {
+			return err
+		}
+
+		deleted, err := result.RowsAffected()
+
+		if err != nil {
+			return err
+		}
+
+		if deleted == 0 {
+			return internalErrors.ErrNoRows
+		}
+
+		return nil
+	})
+}
+
+// ReorderEntries numbers the entries in the order of entryIDs, which must hold
+// every entry of the workout exactly once.
+func (s *PostgresWorkoutEntryStore) ReorderEntries(workoutID int, entryIDs []int) ([]WorkoutEntry, error) {
+	var entries []WorkoutEntry
+
+	err := s.changeEntries(workoutID, func(tx *sql.Tx, _ string) error {
+		currentIDs, err := scanIDs(tx, "select id from workout_entries where workout_id = $1", workoutID)
+
+		if err != nil {
+			return err
+		}
+
+		requestedIDs := slices.Clone(entryIDs)
+		slices.Sort(currentIDs)
+		slices.Sort(requestedIDs)
+
+		if !slices.Equal(currentIDs, requestedIDs) {
+			return internalErrors.ErrInvalidEntryOrder
+		}
+
+		query := `
+			update workout_entries
+			set order_index = ordered.position, updated_at = now()
+			from unnest($2::int[]) with ordinality as ordered(id, position)
+			where workout_entries.id = ordered.id and workout_entries.workout_id = $1
+		`
+
+		if _, err := tx.Exec(query, workoutID, entryIDs); err != nil {
+			return err
+		}
+
+		entries, err = getWorkoutEntries(tx, workoutID)
+
+		return err
+	})
+
+	return entries, err
+}
+
+// changeEntries runs change inside a transaction, with the status of the
+// workout, and brings everything that depends on the entries of the workout up
+// to date afterwards. Entry groups are
+// validated against the resulting entries, so a change that breaks a group is
+// rolled back.
+func (s *PostgresWorkoutEntryStore) changeEntries(workoutID int, change func(tx *sql.Tx, status string) error) error {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := recordBaselineRevision(tx, workoutID); err != nil {
+		return err
+	}
+
+	previousExerciseKeys, err := workoutExerciseKeys(tx, workoutID)
+
+	if err != nil {
+		return err
+	}
+
+	var status string
+
+	if err := tx.QueryRow("select status from workouts where id = $1", workoutID).Scan(&status); err != nil {
+		return err
+	}
+
+	if err := change(tx, status); err != nil {
+		return err
+	}
+
+	entries, err := getWorkoutEntries(tx, workoutID)
+
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateEntryGroups(entries); err != nil {
+		return err
+	}
+
+	workout := &Workout{}
+	query := fmt.Sprintf(`update workouts set updated_at = now() where id = $1 returning %s`, workoutColumns)
+
+	if err := scanWorkout(tx.QueryRow(query, workoutID), workout); err != nil {
+		return err
+	}
+
+	if err := estimateCalories(tx, workout); err != nil {
+		return err
+	}
+
+	exerciseKeys, err := workoutExerciseKeys(tx, workoutID)
+
+	if err != nil {
+		return err
+	}
+
+	if err := recomputePersonalRecords(tx, workout.UserID, append(previousExerciseKeys, exerciseKeys...)); err != nil {
+		return err
+	}
+
+	if err := refreshGoals(tx, workout.UserID); err != nil {
+		return err
+	}
+
+	if err := recordRevision(tx, workoutID, &workout.UserID, RevisionUpdate); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func getWorkoutEntries(q queryer, workoutID int) ([]WorkoutEntry, error) {
+	workouts := []Workout{{ID: workoutID}}
+
+	if err := loadWorkoutEntries(q, workouts); err != nil {
+		return nil, err
+	}
+
+	return workouts[0].Entries, nil
+}
+
+// updateWorkoutEntry overwrites an entry of the workout in place, keeping its
+// id and the ids of its sets. Entries that are not in the workout return
+// ErrNoRows.
+func updateWorkoutEntry(tx *sql.Tx, workoutID int, status string, entry *WorkoutEntry) error {
+	entry.syncSets()
+	entry.defaultCompleted(status)
+
+	query := fmt.Sprintf(`
+		update workout_entries
+		set exercise_name = $2, sets = $3, reps = $4, duration_seconds = $5, weight = $6, notes = $7,
+		    order_index = $8, exercise_id = coalesce($10, (
+		        select id from exercises
+		        where (user_id is null or user_id = $9) and %s
+		        order by user_id nulls last, id
+		        limit 1
+		    )),
+		    group_id = $11, group_type = $12, group_rounds = $13, group_rest_seconds = $14, updated_at = now()
+		where id = $1 and workout_id = $15
+		returning exercise_id, created_at, updated_at
+	`, exerciseMatchesName)
+
+	args := []any{
+		entry.ID,
+		entry.ExerciseName,
+		entry.Sets,
+		entry.Reps,
+		entry.DurationSeconds,
+		entry.Weight,
+		entry.Notes,
+		entry.OrderIndex,
+		entry.UserID,
+		entry.ExerciseID,
+	}
+	args = append(append(args, entry.Group.columns()...), workoutID)
+
+	err := tx.QueryRow(query, args...).Scan(&entry.ExerciseID, &entry.CreatedAt, &entry.UpdatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return updateWorkoutSets(tx, entry)
+}
+
+// updateWorkoutSets writes the sets of an entry keeping the ids of the ones
+// that already belong to it; sets with any other id are added as new ones. Set
+// numbers are moved out of the way first, as they are unique per entry.
+func updateWorkoutSets(tx *sql.Tx, entry *WorkoutEntry) error {
+	currentIDs, err := scanIDs(tx, "select id from workout_sets where workout_entry_id = $1", entry.ID)
+
+	if err != nil {
+		return err
+	}
+
+	keptIDs := make([]int, 0)
+
+	for i := range entry.WorkoutSets {
+		set := &entry.WorkoutSets[i]
+
+		if !slices.Contains(currentIDs, set.ID) || slices.Contains(keptIDs, set.ID) {
+			set.ID = 0
+			continue
+		}
+
+		keptIDs = append(keptIDs, set.ID)
+	}
+
+	_, err = tx.Exec("delete from workout_sets where workout_entry_id = $1 and id <> all($2)", entry.ID, keptIDs)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("update workout_sets set set_number = -set_number where workout_entry_id = $1", entry.ID)
+
+	if err != nil {
+		return err
+	}
+
+	query := `
+		update workout_sets
+		set set_number = $3, set_type = $4, reps = $5, weight = $6, duration_seconds = $7, rpe = $8, rir = $9,
+		    completed = $10, updated_at = now()
+		where id = $1 and workout_entry_id = $2
+		returning created_at, updated_at
+	`
+
+	for i := range entry.WorkoutSets {
+		set := &entry.WorkoutSets[i]
+		set.SetNumber = i + 1
+
+		if set.ID == 0 {
+			if err := insertWorkoutSet(tx, entry.ID, set); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err := tx.QueryRow(
+			query,
+			set.ID,
+			entry.ID,
+			set.SetNumber,
+			set.SetType,
+			set.Reps,
+			set.Weight,
+			set.DurationSeconds,
+			set.RPE,
+			set.RIR,
+			set.Completed,
+		).Scan(&set.CreatedAt, &set.UpdatedAt)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanIDs runs a query returning a single id column.
+func scanIDs(q queryer, query string, args ...any) ([]int, error) {
+	ids := make([]int, 0)
+
+	err := scanRows(q, query, args, func(rows *sql.Rows) error {
+		var id int
+
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+
+		ids = append(ids, id)
+
+		return nil
+	})
+
+	return ids, err
+}
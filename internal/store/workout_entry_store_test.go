@@ -0,0 +1,77 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/utils"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkoutEntryStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	workoutStore := NewPostgresWorkoutStore(db)
+	entryStore := NewPostgresWorkoutEntryStore(db)
+
+	workout := utils.Must(workoutStore.CreateWorkout(&Workout{
+		Title:  "Push",
+		UserID: user.ID,
+		Entries: []WorkoutEntry{
+			{ExerciseName: "Bench Press", Sets: 2, Reps: utils.ValueToPointer(8), Weight: 80, OrderIndex: 1, UserID: user.ID},
+		},
+	}))
+	bench := workout.Entries[0]
+
+	t.Run("Create appends the entry", func(t *testing.T) {
+		entry, err := entryStore.CreateEntry(workout.ID, &WorkoutEntry{
+			ExerciseName: "Overhead Press", Sets: 3, Reps: utils.ValueToPointer(10), Weight: 40, UserID: user.ID,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, entry.OrderIndex)
+		assert.Len(t, entry.WorkoutSets, 3)
+	})
+
+	t.Run("Update keeps entry and set ids", func(t *testing.T) {
+		entry := utils.Must(entryStore.GetEntry(workout.ID, bench.ID))
+		firstSetID := entry.WorkoutSets[0].ID
+		entry.WorkoutSets[1].Weight = 82.5
+		entry.WorkoutSets = append(entry.WorkoutSets, WorkoutSet{SetType: SetTypeDrop, Reps: utils.ValueToPointer(12), Weight: 60, Completed: true})
+
+		updated, err := entryStore.UpdateEntry(workout.ID, entry)
+		assert.NoError(t, err)
+		assert.Equal(t, bench.ID, updated.ID)
+		assert.Equal(t, 82.5, updated.Weight)
+
+		found := utils.Must(entryStore.GetEntry(workout.ID, bench.ID))
+		assert.Len(t, found.WorkoutSets, 3)
+		assert.Equal(t, firstSetID, found.WorkoutSets[0].ID)
+		assert.Equal(t, 3, found.WorkoutSets[2].SetNumber)
+	})
+
+	t.Run("Reorder needs every entry once", func(t *testing.T) {
+		entries := utils.Must(entryStore.GetEntries(workout.ID))
+
+		_, err := entryStore.ReorderEntries(workout.ID, []int{entries[1].ID})
+		assert.ErrorIs(t, err, internalErrors.ErrInvalidEntryOrder)
+
+		reordered, err := entryStore.ReorderEntries(workout.ID, []int{entries[1].ID, entries[0].ID})
+		assert.NoError(t, err)
+		assert.Equal(t, "Overhead Press", reordered[0].ExerciseName)
+		assert.Equal(t, 1, reordered[0].OrderIndex)
+	})
+
+	t.Run("Delete an entry", func(t *testing.T) {
+		assert.NoError(t, entryStore.DeleteEntry(workout.ID, bench.ID))
+		assert.ErrorIs(t, entryStore.DeleteEntry(workout.ID, bench.ID), internalErrors.ErrNoRows)
+
+		_, err := entryStore.GetEntry(workout.ID, bench.ID)
+		assert.ErrorIs(t, err, internalErrors.ErrNoRows)
+	})
+}
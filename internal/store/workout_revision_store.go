@@ -0,0 +1,332 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	internalErrors "partiuFit/internal/errors"
+	"slices"
+	"time"
+)
+
+const (
+	RevisionCreate = "create"
+	RevisionUpdate = "update"
+	RevisionRevert = "revert"
+	// RevisionSnapshot captures changes made outside the revision history,
+	// such as sets logged in a live session or workouts older than it.
+	RevisionSnapshot = "snapshot"
+)
+
+// WorkoutRevision is the state of a workout right after a change. Changes
+// lists the fields that differ from the previous revision, and Workout holds
+// the full snapshot, weights in kilograms.
+type WorkoutRevision struct {
+	ID        int        `json:"id"`
+	WorkoutID int        `json:"workout_id"`
+	Revision  int        `json:"revision"`
+	ChangedBy *int       `json:"changed_by"`
+	Action    string     `json:"action"`
+	Changes   []string   `json:"changes"`
+	Workout   *Workout   `json:"workout,omitempty"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+type WorkoutRevisionStore interface {
+	GetRevisions(workoutID int) ([]WorkoutRevision, error)
+	GetRevision(workoutID int, revision int) (*WorkoutRevision, error)
+	RevertWorkout(workoutID int, revision int, changedBy int) (*Workout, error)
+}
+
+type PostgresWorkoutRevisionStore struct {
+	db *sql.DB
+}
+
+func NewPostgresWorkoutRevisionStore(db *sql.DB) *PostgresWorkoutRevisionStore {
+	return &PostgresWorkoutRevisionStore{
+		db: db,
+	}
+}
+
+// GetRevisions lists the revisions of a workout, newest first. Snapshots are
+// left out, but each revision says which fields it changed.
+func (s *PostgresWorkoutRevisionStore) GetRevisions(workoutID int) ([]WorkoutRevision, error) {
+	query := `
+		select id, workout_id, revision, changed_by, action, snapshot, created_at
+		from workout_revisions
+		where workout_id = $1
+		order by revision
+	`
+
+	revisions := make([]WorkoutRevision, 0)
+	var previous *Workout
+
+	err := scanRows(s.db, query, []any{workoutID}, func(rows *sql.Rows) error {
+		revision := WorkoutRevision{}
+
+		if err := scanWorkoutRevision(rows, &revision); err != nil {
+			return err
+		}
+
+		revision.Changes = revisionChanges(previous, revision.Workout)
+		previous = revision.Workout
+		revision.Workout = nil
+		revisions = append(revisions, revision)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(revisions)-1; i < j; i, j = i+1, j-1 {
+		revisions[i], revisions[j] = revisions[j], revisions[i]
+	}
+
+	return revisions, nil
+}
+
+// GetRevision returns the workout as it was at the given revision.
+func (s *PostgresWorkoutRevisionStore) GetRevision(workoutID int, revision int) (*WorkoutRevision, error) {
+	query := `
+		select id, workout_id, revision, changed_by, action, snapshot, created_at
+		from workout_revisions
+		where workout_id = $1 and revision in ($2, $2 - 1)
+		order by revision
+	`
+
+	found := make([]WorkoutRevision, 0, 2)
+
+	err := scanRows(s.db, query, []any{workoutID, revision}, func(rows *sql.Rows) error {
+		workoutRevision := WorkoutRevision{}
+
+		if err := scanWorkoutRevision(rows, &workoutRevision); err != nil {
+			return err
+		}
+
+		found = append(found, workoutRevision)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(found) == 0 || found[len(found)-1].Revision != revision {
+		return nil, internalErrors.ErrNoRows
+	}
+
+	current := &found[len(found)-1]
+	var previous *Workout
+
+	if len(found) == 2 {
+		previous = found[0].Workout
+	}
+
+	current.Changes = revisionChanges(previous, current.Workout)
+
+	return current, nil
+}
+
+// RevertWorkout brings the title, description, duration, calories and entries
+// of a workout back to a revision. Entries and sets that still exist keep their
+// ids, only the removed ones since are created again. The revert is itself
+// recorded as a new revision, so it can be undone as well.
+func (s *PostgresWorkoutRevisionStore) RevertWorkout(workoutID int, revision int, changedBy int) (*Workout, error) {
+	target, err := s.GetRevision(workoutID, revision)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := recordBaselineRevision(tx, workoutID); err != nil {
+		return nil, err
+	}
+
+	workout := target.Workout
+	// who can see the workout is not part of its history, a revert never
+	// widens it back
+	workout.Visibility = nil
+
+	if err := updateWorkout(tx, workoutID, workout); err != nil {
+		return nil, err
+	}
+
+	if err := recordRevision(tx, workoutID, &changedBy, RevisionRevert); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return workout, nil
+}
+
+func scanWorkoutRevision(scanner interface{ Scan(dest ...any) error }, revision *WorkoutRevision) error {
+	var snapshot []byte
+
+	err := scanner.Scan(
+		&revision.ID,
+		&revision.WorkoutID,
+		&revision.Revision,
+		&revision.ChangedBy,
+		&revision.Action,
+		&snapshot,
+		&revision.CreatedAt,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	revision.Workout = &Workout{}
+
+	return json.Unmarshal(snapshot, revision.Workout)
+}
+
+// loadWorkoutSnapshot reads the workout with its entries and sets, locking its
+// row so revisions are numbered one at a time.
+func loadWorkoutSnapshot(tx *sql.Tx, workoutID int) ([]byte, error) {
+	workout := Workout{}
+	query := fmt.Sprintf(`select %s from workouts where id = $1 for update`, workoutColumns)
+
+	if err := scanWorkout(tx.QueryRow(query, workoutID), &workout); err != nil {
+		return nil, err
+	}
+
+	workouts := []Workout{workout}
+
+	if err := loadWorkoutEntries(tx, workouts); err != nil {
+		return nil, err
+	}
+
+	if err := loadWorkoutTags(tx, workouts); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(workouts[0])
+}
+
+// recordRevision stores the current state of the workout as its next revision.
+func recordRevision(tx *sql.Tx, workoutID int, changedBy *int, action string) error {
+	snapshot, err := loadWorkoutSnapshot(tx, workoutID)
+
+	if err != nil {
+		return err
+	}
+
+	return insertRevision(tx, workoutID, changedBy, action, snapshot)
+}
+
+// recordBaselineRevision is called before a change. When the workout no longer
+// matches its latest revision, or has none, its current state is stored first
+// so the change never loses what was there before it.
+func recordBaselineRevision(tx *sql.Tx, workoutID int) error {
+	snapshot, err := loadWorkoutSnapshot(tx, workoutID)
+
+	if err != nil {
+		return err
+	}
+
+	var unchanged bool
+	query := `
+		select snapshot - 'updated_at' = $2::jsonb - 'updated_at'
+		from workout_revisions
+		where workout_id = $1
+		order by revision desc
+		limit 1
+	`
+
+	err = tx.QueryRow(query, workoutID, string(snapshot)).Scan(&unchanged)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if unchanged {
+		return nil
+	}
+
+	return insertRevision(tx, workoutID, nil, RevisionSnapshot, snapshot)
+}
+
+func insertRevision(tx *sql.Tx, workoutID int, changedBy *int, action string, snapshot []byte) error {
+	query := `
+		insert into workout_revisions (workout_id, revision, changed_by, action, snapshot)
+		select $1, coalesce(max(revision), 0) + 1, $2, $3, $4
+		from workout_revisions
+		where workout_id = $1
+	`
+
+	_, err := tx.Exec(query, workoutID, changedBy, action, string(snapshot))
+
+	return err
+}
+
+// revisionChanges names the fields of the workout that differ between two
+// snapshots. The first revision lists none.
+func revisionChanges(previous, current *Workout) []string {
+	changes := make([]string, 0)
+
+	if previous == nil || current == nil {
+		return changes
+	}
+
+	fields := []struct {
+		name    string
+		changed bool
+	}{
+		{"title", previous.Title != current.Title},
+		{"description", previous.Description != current.Description},
+		{"duration_minutes", previous.DurationMinutes != current.DurationMinutes},
+		{"calories_burned", previous.CaloriesBurned != current.CaloriesBurned},
+		{"status", previous.Status != current.Status},
+		{"entries", entriesFingerprint(previous.Entries) != entriesFingerprint(current.Entries)},
+		{"tags", !slices.Equal(previous.Tags, current.Tags)},
+	}
+
+	for _, field := range fields {
+		if field.changed {
+			changes = append(changes, field.name)
+		}
+	}
+
+	return changes
+}
+
+// entriesFingerprint serializes the entries without their ids and timestamps,
+// which change every time the entries are rewritten.
+func entriesFingerprint(entries []WorkoutEntry) string {
+	stripped := make([]WorkoutEntry, len(entries))
+
+	for i, entry := range entries {
+		entry.ID, entry.CreatedAt, entry.UpdatedAt = 0, nil, nil
+		sets := make([]WorkoutSet, len(entry.WorkoutSets))
+
+		for j, set := range entry.WorkoutSets {
+			set.ID, set.CreatedAt, set.UpdatedAt = 0, nil, nil
+			sets[j] = set
+		}
+
+		entry.WorkoutSets = sets
+		stripped[i] = entry
+	}
+
+	fingerprint, _ := json.Marshal(stripped)
+
+	return string(fingerprint)
+}
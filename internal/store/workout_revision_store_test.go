@@ -0,0 +1,104 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/utils"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkoutRevisionStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	workoutStore := NewPostgresWorkoutStore(db)
+	revisionStore := NewPostgresWorkoutRevisionStore(db)
+
+	workout := utils.Must(workoutStore.CreateWorkout(&Workout{
+		Title:           "Push",
+		DurationMinutes: 60,
+		CaloriesBurned:  300,
+		UserID:          user.ID,
+		Entries: []WorkoutEntry{
+			{ExerciseName: "Bench Press", Sets: 3, Reps: utils.ValueToPointer(8), Weight: 80, OrderIndex: 1, UserID: user.ID},
+		},
+	}))
+
+	updated := *workout
+	updated.Title = "Push day"
+	updated.Entries = []WorkoutEntry{
+		{ExerciseName: "Bench Press", Sets: 3, Reps: utils.ValueToPointer(8), Weight: 85, OrderIndex: 1, UserID: user.ID},
+	}
+	utils.Must(workoutStore.UpdateWorkout(workout.ID, &updated))
+
+	t.Run("Every change is a revision", func(t *testing.T) {
+		revisions, err := revisionStore.GetRevisions(workout.ID)
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 2)
+
+		assert.Equal(t, 2, revisions[0].Revision)
+		assert.Equal(t, RevisionUpdate, revisions[0].Action)
+		assert.Equal(t, user.ID, *revisions[0].ChangedBy)
+		assert.Equal(t, []string{"title", "entries"}, revisions[0].Changes)
+		assert.Nil(t, revisions[0].Workout)
+
+		assert.Equal(t, RevisionCreate, revisions[1].Action)
+		assert.Empty(t, revisions[1].Changes)
+	})
+
+	t.Run("Get a revision", func(t *testing.T) {
+		revision, err := revisionStore.GetRevision(workout.ID, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Push", revision.Workout.Title)
+		assert.Equal(t, 80.0, revision.Workout.Entries[0].WorkoutSets[0].Weight)
+
+		_, err = revisionStore.GetRevision(workout.ID, 10)
+		assert.ErrorIs(t, err, internalErrors.ErrNoRows)
+	})
+
+	t.Run("Revert to a revision", func(t *testing.T) {
+		reverted, err := revisionStore.RevertWorkout(workout.ID, 1, user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Push", reverted.Title)
+
+		current := utils.Must(workoutStore.GetWorkoutById(workout.ID))
+		assert.Equal(t, 80.0, current.Entries[0].Weight)
+
+		revisions := utils.Must(revisionStore.GetRevisions(workout.ID))
+		assert.Len(t, revisions, 3)
+		assert.Equal(t, RevisionRevert, revisions[0].Action)
+	})
+
+	t.Run("Revert keeps the ids of existing entries", func(t *testing.T) {
+		current := utils.Must(workoutStore.GetWorkoutById(workout.ID))
+		_, err := revisionStore.RevertWorkout(workout.ID, 3, user.ID)
+		assert.NoError(t, err)
+
+		reverted := utils.Must(workoutStore.GetWorkoutById(workout.ID))
+		assert.Equal(t, current.Entries[0].ID, reverted.Entries[0].ID)
+		assert.Equal(t, current.Entries[0].WorkoutSets[0].ID, reverted.Entries[0].WorkoutSets[0].ID)
+	})
+}
+
+func TestRevisionChanges(t *testing.T) {
+	previous := &Workout{Title: "Push", Entries: []WorkoutEntry{{ID: 1, ExerciseName: "Bench Press"}}}
+
+	t.Run("Rewritten entries are not a change", func(t *testing.T) {
+		current := &Workout{Title: "Push", Entries: []WorkoutEntry{{ID: 7, ExerciseName: "Bench Press"}}}
+		assert.Empty(t, revisionChanges(previous, current))
+	})
+
+	t.Run("Changed fields are listed", func(t *testing.T) {
+		current := &Workout{Title: "Pull", DurationMinutes: 45, Entries: []WorkoutEntry{{ID: 1, ExerciseName: "Row"}}}
+		assert.Equal(t, []string{"title", "duration_minutes", "entries"}, revisionChanges(previous, current))
+	})
+
+	t.Run("The first revision has no changes", func(t *testing.T) {
+		assert.Empty(t, revisionChanges(nil, previous))
+	})
+}
@@ -0,0 +1,410 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	internalErrors "partiuFit/internal/errors"
+	"slices"
+	"time"
+)
+
+const (
+	SessionStart      = "start"
+	SessionPause      = "pause"
+	SessionResume     = "resume"
+	SessionFinish     = "finish"
+	SessionAutoFinish = "auto_finish"
+)
+
+// DefaultSessionTimeout is how long a live session may go without activity
+// before it is closed automatically.
+const DefaultSessionTimeout = 4 * time.Hour
+
+// sessionTransitions maps every event to the statuses it can leave from and
+// the status it leads to.
+var sessionTransitions = map[string]struct {
+	from []string
+	to   string
+}{
+	SessionStart:      {[]string{WorkoutPlanned}, WorkoutInProgress},
+	SessionPause:      {[]string{WorkoutInProgress}, WorkoutPaused},
+	SessionResume:     {[]string{WorkoutPaused}, WorkoutInProgress},
+	SessionFinish:     {[]string{WorkoutInProgress, WorkoutPaused}, WorkoutCompleted},
+	SessionAutoFinish: {[]string{WorkoutInProgress, WorkoutPaused}, WorkoutCompleted},
+}
+
+type SessionEvent struct {
+	EventType  string    `json:"event_type"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// WorkoutSession is the live state of a workout. ActiveSeconds only counts
+// the time between start/resume and pause/finish.
+type WorkoutSession struct {
+	WorkoutID      int            `json:"workout_id"`
+	Status         string         `json:"status"`
+	StartedAt      *time.Time     `json:"started_at"`
+	FinishedAt     *time.Time     `json:"finished_at"`
+	LastActivityAt *time.Time     `json:"last_activity_at"`
+	ActiveSeconds  int            `json:"active_seconds"`
+	Events         []SessionEvent `json:"events"`
+}
+
+type WorkoutSessionStore interface {
+	GetSession(workoutID int) (*WorkoutSession, error)
+	TransitionSession(workoutID int, event string) (*WorkoutSession, error)
+	LogSessionSet(workoutID int, entryID *int, entry *WorkoutEntry, set WorkoutSet) (*WorkoutEntry, error)
+	CloseAbandonedSessions(abandonAfter time.Duration) (int, error)
+}
+
+type PostgresWorkoutSessionStore struct {
+	db *sql.DB
+}
+
+func NewPostgresWorkoutSessionStore(db *sql.DB) *PostgresWorkoutSessionStore {
+	return &PostgresWorkoutSessionStore{
+		db: db,
+	}
+}
+
+func (s *PostgresWorkoutSessionStore) GetSession(workoutID int) (*WorkoutSession, error) {
+	return getSession(s.db, workoutID, time.Now())
+}
+
+func getSession(q dbtx, workoutID int, now time.Time) (*WorkoutSession, error) {
+	session := &WorkoutSession{WorkoutID: workoutID}
+	query := `select status, started_at, finished_at, last_activity_at from workouts where id = $1`
+
+	err := q.QueryRow(query, workoutID).Scan(&session.Status, &session.StartedAt, &session.FinishedAt, &session.LastActivityAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	session.Events = make([]SessionEvent, 0)
+	eventsQuery := `select event_type, occurred_at from workout_session_events where workout_id = $1 order by occurred_at, id`
+
+	err = scanRows(q, eventsQuery, []any{workoutID}, func(rows *sql.Rows) error {
+		event := SessionEvent{}
+
+		if err := rows.Scan(&event.EventType, &event.OccurredAt); err != nil {
+			return err
+		}
+
+		session.Events = append(session.Events, event)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	session.ActiveSeconds = int(activeDuration(session.Events, now).Seconds())
+
+	return session, nil
+}
+
+// TransitionSession applies a start, pause, resume or finish event with the
+// server time. Finishing computes the duration of the workout from the active
+// time, estimates its calories when none were logged and re-evaluates the
+// goals of the user.
+func (s *PostgresWorkoutSessionStore) TransitionSession(workoutID int, event string) (*WorkoutSession, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var now time.Time
+	err = tx.QueryRow("select now()").Scan(&now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := applySessionEvent(tx, workoutID, event, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return session, tx.Commit()
+}
+
+func applySessionEvent(tx *sql.Tx, workoutID int, event string, at time.Time) (*WorkoutSession, error) {
+	transition, ok := sessionTransitions[event]
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", internalErrors.ErrInvalidSessionTransition, event)
+	}
+
+	var userID int
+	var status string
+	err := tx.QueryRow("select user_id, status from workouts where id = $1 for update", workoutID).Scan(&userID, &status)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(transition.from, status) {
+		return nil, fmt.Errorf("%w: %s -> %s", internalErrors.ErrInvalidSessionTransition, status, event)
+	}
+
+	_, err = tx.Exec(`insert into workout_session_events (workout_id, event_type, occurred_at) values ($1, $2, $3)`, workoutID, event, at)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		update workouts
+		set status = $2,
+		    last_activity_at = $3,
+		    started_at = coalesce(started_at, $3),
+		    finished_at = case when $2 = 'completed' then $3::timestamptz end,
+		    updated_at = now()
+		where id = $1
+	`
+
+	_, err = tx.Exec(query, workoutID, transition.to, at)
+
+	if err != nil {
+		return nil, internalErrors.HandleSessionDatabaseError(err)
+	}
+
+	session, err := getSession(tx, workoutID, at)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if transition.to != WorkoutCompleted {
+		return session, nil
+	}
+
+	workout := &Workout{}
+	query = fmt.Sprintf("update workouts set duration_minutes = $2 where id = $1 returning %s", workoutColumns)
+	err = scanWorkout(tx.QueryRow(query, workoutID, int(math.Round(float64(session.ActiveSeconds)/60))), workout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := estimateCalories(tx, workout); err != nil {
+		return nil, err
+	}
+
+	exerciseKeys, err := workoutExerciseKeys(tx, workoutID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recomputePersonalRecords(tx, userID, exerciseKeys); err != nil {
+		return nil, err
+	}
+
+	return session, refreshGoals(tx, userID)
+}
+
+// LogSessionSet appends a set to an entry of a running session. Without an
+// entryID a new entry is added at the end of the workout for the exercise.
+// Logged sets count as done unless sent with completed false; they only reach
+// personal records once the session is finished.
+func (s *PostgresWorkoutSessionStore) LogSessionSet(workoutID int, entryID *int, entry *WorkoutEntry, set WorkoutSet) (*WorkoutEntry, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var userID int
+	var status string
+	err = tx.QueryRow("select user_id, status from workouts where id = $1 for update", workoutID).Scan(&userID, &status)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if status != WorkoutInProgress {
+		return nil, fmt.Errorf("%w: séries só podem ser registradas com a sessão em andamento", internalErrors.ErrInvalidSessionTransition)
+	}
+
+	if set.completedOmitted {
+		set.Completed = true
+		set.completedOmitted = false
+	}
+
+	if entryID == nil {
+		err = tx.QueryRow("select coalesce(max(order_index), 0) + 1 from workout_entries where workout_id = $1", workoutID).Scan(&entry.OrderIndex)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entry.UserID = userID
+		entry.WorkoutSets = []WorkoutSet{set}
+
+		if err := insertWorkoutEntry(tx, workoutID, status, entry); err != nil {
+			return nil, err
+		}
+	} else {
+		entry, err = appendEntrySet(tx, workoutID, *entryID, set)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec("update workouts set last_activity_at = now() where id = $1", workoutID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, tx.Commit()
+}
+
+// appendEntrySet adds the set after the last one of the entry and refreshes
+// the legacy sets/reps/weight columns of the entry.
+func appendEntrySet(tx *sql.Tx, workoutID int, entryID int, set WorkoutSet) (*WorkoutEntry, error) {
+	entry := &WorkoutEntry{}
+	query := fmt.Sprintf(`select %s from workout_entries where id = $1 and workout_id = $2`, workoutEntryColumns)
+
+	if err := scanWorkoutEntry(tx.QueryRow(query, entryID, workoutID), entry); err != nil {
+		return nil, err
+	}
+
+	entries := []WorkoutEntry{*entry}
+
+	if err := loadWorkoutSets(tx, entries); err != nil {
+		return nil, err
+	}
+
+	entry = &entries[0]
+	set.SetNumber = len(entry.WorkoutSets) + 1
+
+	if err := insertWorkoutSet(tx, entry.ID, &set); err != nil {
+		return nil, err
+	}
+
+	entry.WorkoutSets = append(entry.WorkoutSets, set)
+	entry.syncSets()
+
+	_, err := tx.Exec(
+		"update workout_entries set sets = $2, reps = $3, weight = $4, duration_seconds = $5, updated_at = now() where id = $1",
+		entry.ID, entry.Sets, entry.Reps, entry.Weight, entry.DurationSeconds,
+	)
+
+	return entry, err
+}
+
+// CloseAbandonedSessions finishes every live session without activity for
+// longer than abandonAfter. They are closed at their last activity, so the idle
+// time never counts towards the duration.
+func (s *PostgresWorkoutSessionStore) CloseAbandonedSessions(abandonAfter time.Duration) (int, error) {
+	query := `
+		select id, last_activity_at
+		from workouts
+		where status in ('in_progress', 'paused') and deleted_at is null and last_activity_at < now() - $1::interval
+	`
+
+	type abandonedSession struct {
+		workoutID      int
+		lastActivityAt time.Time
+	}
+
+	abandoned := make([]abandonedSession, 0)
+
+	err := scanRows(s.db, query, []any{fmt.Sprintf("%d seconds", int(abandonAfter.Seconds()))}, func(rows *sql.Rows) error {
+		session := abandonedSession{}
+
+		if err := rows.Scan(&session.workoutID, &session.lastActivityAt); err != nil {
+			return err
+		}
+
+		abandoned = append(abandoned, session)
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+
+	for _, session := range abandoned {
+		err := s.closeSession(session.workoutID, session.lastActivityAt)
+
+		// the user may have finished it in the meantime
+		if errors.Is(err, internalErrors.ErrInvalidSessionTransition) {
+			continue
+		}
+
+		if err != nil {
+			return closed, err
+		}
+
+		closed++
+	}
+
+	return closed, nil
+}
+
+func (s *PostgresWorkoutSessionStore) closeSession(workoutID int, at time.Time) error {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := applySessionEvent(tx, workoutID, SessionAutoFinish, at); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// activeDuration sums the intervals opened by start or resume and closed by
+// pause or finish. A session still running counts until now.
+func activeDuration(events []SessionEvent, now time.Time) time.Duration {
+	var active time.Duration
+	var runningSince *time.Time
+
+	for _, event := range events {
+		switch event.EventType {
+		case SessionStart, SessionResume:
+			if runningSince == nil {
+				occurredAt := event.OccurredAt
+				runningSince = &occurredAt
+			}
+		default:
+			if runningSince != nil {
+				active += event.OccurredAt.Sub(*runningSince)
+				runningSince = nil
+			}
+		}
+	}
+
+	if runningSince != nil && now.After(*runningSince) {
+		active += now.Sub(*runningSince)
+	}
+
+	return active
+}
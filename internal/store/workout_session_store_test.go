@@ -0,0 +1,155 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/utils"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkoutSessionStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	workoutStore := NewPostgresWorkoutStore(db)
+	sessionStore := NewPostgresWorkoutSessionStore(db)
+	recordStore := NewPostgresPersonalRecordStore(db)
+
+	planned := utils.Must(workoutStore.CreateWorkout(&Workout{
+		Title:  "Leg day",
+		UserID: user.ID,
+		Status: WorkoutPlanned,
+		Entries: []WorkoutEntry{
+			{ExerciseName: "Squat", Sets: 1, Reps: utils.ValueToPointer(5), Weight: 100, OrderIndex: 1, UserID: user.ID},
+		},
+	}))
+
+	t.Run("Workouts are completed by default", func(t *testing.T) {
+		workout, err := workoutStore.CreateWorkout(&Workout{Title: "Logged later", UserID: user.ID})
+		assert.NoError(t, err)
+		assert.Equal(t, WorkoutCompleted, workout.Status)
+		assert.Equal(t, WorkoutPlanned, planned.Status)
+		assert.False(t, planned.Entries[0].WorkoutSets[0].Completed)
+	})
+
+	t.Run("Session lifecycle", func(t *testing.T) {
+		_, err := sessionStore.TransitionSession(planned.ID, SessionPause)
+		assert.ErrorIs(t, err, internalErrors.ErrInvalidSessionTransition)
+
+		session, err := sessionStore.TransitionSession(planned.ID, SessionStart)
+		assert.NoError(t, err)
+		assert.Equal(t, WorkoutInProgress, session.Status)
+		assert.NotNil(t, session.StartedAt)
+
+		session, err = sessionStore.TransitionSession(planned.ID, SessionPause)
+		assert.NoError(t, err)
+		assert.Equal(t, WorkoutPaused, session.Status)
+
+		session, err = sessionStore.TransitionSession(planned.ID, SessionResume)
+		assert.NoError(t, err)
+		assert.Equal(t, WorkoutInProgress, session.Status)
+		assert.Len(t, session.Events, 3)
+	})
+
+	t.Run("Only one active session per user", func(t *testing.T) {
+		other := utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Arms", UserID: user.ID, Status: WorkoutPlanned}))
+
+		_, err := sessionStore.TransitionSession(other.ID, SessionStart)
+		assert.ErrorIs(t, err, internalErrors.ErrActiveSessionExists)
+	})
+
+	t.Run("Log sets while running", func(t *testing.T) {
+		workout := utils.Must(workoutStore.GetWorkoutById(planned.ID))
+		entryID := workout.Entries[0].ID
+
+		entry, err := sessionStore.LogSessionSet(planned.ID, &entryID, nil, WorkoutSet{SetType: SetTypeWorking, Reps: utils.ValueToPointer(5), Weight: 110, Completed: true})
+		assert.NoError(t, err)
+		assert.Len(t, entry.WorkoutSets, 2)
+		assert.Equal(t, 110.0, entry.Weight)
+		assert.Empty(t, utils.Must(recordStore.GetPersonalRecords(user.ID, nil)))
+
+		entry, err = sessionStore.LogSessionSet(planned.ID, nil, &WorkoutEntry{ExerciseName: "Leg Press"}, WorkoutSet{SetType: SetTypeWorking, Reps: utils.ValueToPointer(12), Weight: 200, Completed: true})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, entry.OrderIndex)
+		assert.NotZero(t, entry.ID)
+	})
+
+	t.Run("Finish sets the duration", func(t *testing.T) {
+		session, err := sessionStore.TransitionSession(planned.ID, SessionFinish)
+		assert.NoError(t, err)
+		assert.Equal(t, WorkoutCompleted, session.Status)
+		assert.NotNil(t, session.FinishedAt)
+
+		workout := utils.Must(workoutStore.GetWorkoutById(planned.ID))
+		assert.Equal(t, session.ActiveSeconds/60, workout.DurationMinutes)
+		assert.NotEmpty(t, utils.Must(recordStore.GetPersonalRecords(user.ID, nil)))
+
+		_, err = sessionStore.LogSessionSet(planned.ID, nil, &WorkoutEntry{ExerciseName: "Lunge"}, WorkoutSet{SetType: SetTypeWorking, Reps: utils.ValueToPointer(10), Completed: true})
+		assert.ErrorIs(t, err, internalErrors.ErrInvalidSessionTransition)
+	})
+
+	t.Run("Abandoned sessions are closed at their last activity", func(t *testing.T) {
+		abandoned := utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Forgotten", UserID: user.ID, Status: WorkoutPlanned}))
+		utils.Must(sessionStore.TransitionSession(abandoned.ID, SessionStart))
+
+		_, err := db.Exec("update workouts set last_activity_at = now() - interval '5 hours' where id = $1", abandoned.ID)
+		assert.NoError(t, err)
+		_, err = db.Exec("update workout_session_events set occurred_at = now() - interval '6 hours' where workout_id = $1", abandoned.ID)
+		assert.NoError(t, err)
+
+		closed, err := sessionStore.CloseAbandonedSessions(DefaultSessionTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, closed)
+
+		session := utils.Must(sessionStore.GetSession(abandoned.ID))
+		assert.Equal(t, WorkoutCompleted, session.Status)
+		assert.Equal(t, SessionAutoFinish, session.Events[len(session.Events)-1].EventType)
+		assert.InDelta(t, time.Hour.Seconds(), float64(session.ActiveSeconds), 5)
+	})
+
+	t.Run("Deleting a live workout frees the session", func(t *testing.T) {
+		live := utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Back", UserID: user.ID, Status: WorkoutPlanned}))
+		utils.Must(sessionStore.TransitionSession(live.ID, SessionStart))
+		utils.MustIfError(workoutStore.DeleteWorkout(live.ID))
+
+		next := utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Back again", UserID: user.ID, Status: WorkoutPlanned}))
+		session, err := sessionStore.TransitionSession(next.ID, SessionStart)
+		assert.NoError(t, err)
+		assert.Equal(t, WorkoutInProgress, session.Status)
+
+		restored := utils.Must(workoutStore.RestoreWorkout(live.ID, user.ID))
+		assert.Equal(t, WorkoutPlanned, restored.Status)
+		assert.Nil(t, restored.StartedAt)
+	})
+}
+
+func TestActiveDuration(t *testing.T) {
+	start := time.Date(2025, 10, 17, 18, 0, 0, 0, time.UTC)
+
+	t.Run("Paused time is not counted", func(t *testing.T) {
+		events := []SessionEvent{
+			{EventType: SessionStart, OccurredAt: start},
+			{EventType: SessionPause, OccurredAt: start.Add(20 * time.Minute)},
+			{EventType: SessionResume, OccurredAt: start.Add(30 * time.Minute)},
+			{EventType: SessionFinish, OccurredAt: start.Add(50 * time.Minute)},
+		}
+
+		assert.Equal(t, 40*time.Minute, activeDuration(events, start.Add(2*time.Hour)))
+	})
+
+	t.Run("Running session counts until now", func(t *testing.T) {
+		events := []SessionEvent{{EventType: SessionStart, OccurredAt: start}}
+
+		assert.Equal(t, 15*time.Minute, activeDuration(events, start.Add(15*time.Minute)))
+	})
+
+	t.Run("No events", func(t *testing.T) {
+		assert.Zero(t, activeDuration(nil, start))
+	})
+}
@@ -0,0 +1,252 @@
+package store
+
+import (
+	"database/sql"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/tokens"
+	"time"
+)
+
+// WorkoutShare is a public link to a workout. Token is only known when the
+// share is created, afterwards just its hash is kept. Shares without an
+// expiry last until revoked.
+type WorkoutShare struct {
+	ID           int        `json:"id"`
+	WorkoutID    int        `json:"workout_id"`
+	WorkoutTitle string     `json:"workout_title"`
+	Token        string     `json:"token,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	CreatedAt    *time.Time `json:"created_at"`
+}
+
+// SharedWorkout is the read-only view of a workout open to anyone with the
+// link. It leaves out every id and anything about the owner.
+type SharedWorkout struct {
+	Title           string        `json:"title"`
+	Description     string        `json:"description"`
+	DurationMinutes int           `json:"duration_minutes"`
+	CaloriesBurned  int           `json:"calories_burned"`
+	Status          string        `json:"status"`
+	CreatedAt       *time.Time    `json:"created_at"`
+	StartedAt       *time.Time    `json:"started_at"`
+	FinishedAt      *time.Time    `json:"finished_at"`
+	Entries         []SharedEntry `json:"entries"`
+	Track           *SharedTrack  `json:"track,omitempty"`
+}
+
+type SharedEntry struct {
+	ExerciseName    string      `json:"exercise_name"`
+	Sets            int         `json:"sets"`
+	Reps            *int        `json:"reps"`
+	Weight          float64     `json:"weight"`
+	DurationSeconds *int        `json:"duration_seconds"`
+	Notes           string      `json:"notes"`
+	Group           *EntryGroup `json:"group"`
+	WorkoutSets     []SharedSet `json:"workout_sets"`
+}
+
+type SharedSet struct {
+	SetNumber       int      `json:"set_number"`
+	SetType         string   `json:"set_type"`
+	Reps            *int     `json:"reps"`
+	Weight          float64  `json:"weight"`
+	DurationSeconds *int     `json:"duration_seconds"`
+	RPE             *float64 `json:"rpe"`
+	RIR             *int     `json:"rir"`
+	Completed       bool     `json:"completed"`
+}
+
+type SharedTrack struct {
+	Sport           string   `json:"sport"`
+	Distance        float64  `json:"distance"`
+	DurationSeconds int      `json:"duration_seconds"`
+	AveragePace     *float64 `json:"average_pace"`
+	BestPace        *float64 `json:"best_pace"`
+	ElevationGain   float64  `json:"elevation_gain"`
+}
+
+const workoutShareColumns = `workout_shares.id, workout_shares.workout_id, workouts.title, workout_shares.expires_at, workout_shares.created_at`
+
+func scanWorkoutShare(scanner interface{ Scan(dest ...any) error }, share *WorkoutShare) error {
+	return scanner.Scan(&share.ID, &share.WorkoutID, &share.WorkoutTitle, &share.ExpiresAt, &share.CreatedAt)
+}
+
+type WorkoutShareStore interface {
+	CreateShare(workoutID int, userID int, expiresAt *time.Time) (*WorkoutShare, error)
+	GetShares(userID int) ([]WorkoutShare, error)
+	RevokeShare(id int, userID int) error
+	GetSharedWorkoutID(token string) (int, error)
+}
+
+type PostgresWorkoutShareStore struct {
+	db *sql.DB
+}
+
+func NewPostgresWorkoutShareStore(db *sql.DB) *PostgresWorkoutShareStore {
+	return &PostgresWorkoutShareStore{
+		db: db,
+	}
+}
+
+// CreateShare creates a link to a workout of the user. Workouts in the trash
+// cannot be shared and return ErrNoRows.
+func (s *PostgresWorkoutShareStore) CreateShare(workoutID int, userID int, expiresAt *time.Time) (*WorkoutShare, error) {
+	token, err := tokens.GenerateToken(userID, 0, tokens.ScopeWorkoutShare)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		with created as (
+			insert into workout_shares (hash, workout_id, user_id, expires_at)
+			select $1, id, user_id, $4
+			from workouts
+			where id = $2 and user_id = $3 and deleted_at is null
+			returning id, workout_id, expires_at, created_at
+		)
+		select ` + workoutShareColumns + `
+		from created as workout_shares
+		join workouts on workouts.id = workout_shares.workout_id
+	`
+
+	share := &WorkoutShare{}
+
+	if err := scanWorkoutShare(s.db.QueryRow(query, token.Hash, workoutID, userID, expiresAt), share); err != nil {
+		return nil, err
+	}
+
+	share.Token = token.Plaintext
+
+	return share, nil
+}
+
+// GetShares lists the shares of the user that are still open, newest first.
+func (s *PostgresWorkoutShareStore) GetShares(userID int) ([]WorkoutShare, error) {
+	query := `
+		select ` + workoutShareColumns + `
+		from workout_shares
+		join workouts on workouts.id = workout_shares.workout_id
+		where workout_shares.user_id = $1
+		  and workout_shares.revoked_at is null
+		  and (workout_shares.expires_at is null or workout_shares.expires_at > now())
+		  and workouts.deleted_at is null
+		order by workout_shares.created_at desc, workout_shares.id desc
+	`
+
+	shares := make([]WorkoutShare, 0)
+
+	err := scanRows(s.db, query, []any{userID}, func(rows *sql.Rows) error {
+		share := WorkoutShare{}
+
+		if err := scanWorkoutShare(rows, &share); err != nil {
+			return err
+		}
+
+		shares = append(shares, share)
+
+		return nil
+	})
+
+	return shares, err
+}
+
+// RevokeShare closes a share of the user. Shares that are not the user's or
+// were already revoked return ErrNoRows.
+func (s *PostgresWorkoutShareStore) RevokeShare(id int, userID int) error {
+	result, err := s.db.Exec(`
+		update workout_shares set revoked_at = now()
+		where id = $1 and user_id = $2 and revoked_at is null
+	`, id, userID)
+
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affectedRows == 0 {
+		return internalErrors.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetSharedWorkoutID finds the workout a token opens. Revoked and expired
+// tokens, and tokens of workouts moved to the trash, return ErrNoRows.
+func (s *PostgresWorkoutShareStore) GetSharedWorkoutID(token string) (int, error) {
+	query := `
+		select workout_shares.workout_id
+		from workout_shares
+		join workouts on workouts.id = workout_shares.workout_id
+		where workout_shares.hash = $1
+		  and workout_shares.revoked_at is null
+		  and (workout_shares.expires_at is null or workout_shares.expires_at > now())
+		  and workouts.deleted_at is null
+	`
+
+	var workoutID int
+	err := s.db.QueryRow(query, tokens.Hash(token)).Scan(&workoutID)
+
+	return workoutID, err
+}
+
+// NewSharedWorkout copies what the public view shows of a workout.
+func NewSharedWorkout(workout *Workout) *SharedWorkout {
+	shared := &SharedWorkout{
+		Title:           workout.Title,
+		Description:     workout.Description,
+		DurationMinutes: workout.DurationMinutes,
+		CaloriesBurned:  workout.CaloriesBurned,
+		Status:          workout.Status,
+		CreatedAt:       workout.CreatedAt,
+		StartedAt:       workout.StartedAt,
+		FinishedAt:      workout.FinishedAt,
+		Entries:         make([]SharedEntry, 0, len(workout.Entries)),
+	}
+
+	for _, entry := range workout.Entries {
+		sharedEntry := SharedEntry{
+			ExerciseName:    entry.ExerciseName,
+			Sets:            entry.Sets,
+			Reps:            entry.Reps,
+			Weight:          entry.Weight,
+			DurationSeconds: entry.DurationSeconds,
+			Notes:           entry.Notes,
+			Group:           entry.Group,
+			WorkoutSets:     make([]SharedSet, 0, len(entry.WorkoutSets)),
+		}
+
+		for _, set := range entry.WorkoutSets {
+			sharedEntry.WorkoutSets = append(sharedEntry.WorkoutSets, SharedSet{
+				SetNumber:       set.SetNumber,
+				SetType:         set.SetType,
+				Reps:            set.Reps,
+				Weight:          set.Weight,
+				DurationSeconds: set.DurationSeconds,
+				RPE:             set.RPE,
+				RIR:             set.RIR,
+				Completed:       set.Completed,
+			})
+		}
+
+		shared.Entries = append(shared.Entries, sharedEntry)
+	}
+
+	if workout.Track != nil {
+		shared.Track = &SharedTrack{
+			Sport:           workout.Track.Sport,
+			Distance:        workout.Track.Distance,
+			DurationSeconds: workout.Track.DurationSeconds,
+			AveragePace:     workout.Track.AveragePace,
+			BestPace:        workout.Track.BestPace,
+			ElevationGain:   workout.Track.ElevationGain,
+		}
+	}
+
+	return shared
+}
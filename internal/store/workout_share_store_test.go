@@ -0,0 +1,75 @@
+package store
+
+import (
+	"encoding/json"
+	testingUtils "partiuFit/internal/database/testing_utils"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/utils"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkoutShareStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	workoutStore := NewPostgresWorkoutStore(db)
+	shareStore := NewPostgresWorkoutShareStore(db)
+
+	workout := utils.Must(workoutStore.CreateWorkout(&Workout{Title: "Push", DurationMinutes: 60, UserID: user.ID}))
+
+	t.Run("A share opens the workout until revoked", func(t *testing.T) {
+		share, err := shareStore.CreateShare(workout.ID, user.ID, nil)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, share.Token)
+		assert.Equal(t, "Push", share.WorkoutTitle)
+
+		workoutID, err := shareStore.GetSharedWorkoutID(share.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, workout.ID, workoutID)
+
+		shares := utils.Must(shareStore.GetShares(user.ID))
+		assert.Len(t, shares, 1)
+		assert.Empty(t, shares[0].Token)
+
+		assert.NoError(t, shareStore.RevokeShare(share.ID, user.ID))
+		assert.ErrorIs(t, shareStore.RevokeShare(share.ID, user.ID), internalErrors.ErrNoRows)
+
+		_, err = shareStore.GetSharedWorkoutID(share.Token)
+		assert.ErrorIs(t, err, internalErrors.ErrNoRows)
+		assert.Empty(t, utils.Must(shareStore.GetShares(user.ID)))
+	})
+
+	t.Run("Expired shares do not open the workout", func(t *testing.T) {
+		share := utils.Must(shareStore.CreateShare(workout.ID, user.ID, utils.ValueToPointer(time.Now().Add(-time.Minute))))
+
+		_, err := shareStore.GetSharedWorkoutID(share.Token)
+		assert.ErrorIs(t, err, internalErrors.ErrNoRows)
+	})
+
+	t.Run("Workouts of other users cannot be shared", func(t *testing.T) {
+		_, err := shareStore.CreateShare(workout.ID, user.ID+1, nil)
+		assert.ErrorIs(t, err, internalErrors.ErrNoRows)
+	})
+}
+
+func TestNewSharedWorkout(t *testing.T) {
+	workout := &Workout{
+		ID:     1,
+		Title:  "Push",
+		UserID: 7,
+		Entries: []WorkoutEntry{
+			{ID: 2, ExerciseName: "Bench Press", UserID: 7, WorkoutSets: []WorkoutSet{{ID: 3, SetNumber: 1, Weight: 80}}},
+		},
+	}
+
+	body := string(utils.Must(json.Marshal(NewSharedWorkout(workout))))
+	assert.NotContains(t, body, "id")
+	assert.NotContains(t, body, "UserID")
+	assert.Contains(t, body, `"exercise_name":"Bench Press"`)
+}
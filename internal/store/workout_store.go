@@ -1,25 +1,77 @@
 package store
 
 import (
+	"cmp"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/units"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Workout struct {
-	ID              int            `json:"id"`
-	Title           string         `json:"title"`
-	Description     string         `json:"description"`
-	DurationMinutes int            `json:"duration_minutes"`
-	CaloriesBurned  int            `json:"calories_burned"`
-	Entries         []WorkoutEntry `json:"entries"`
-	CreatedAt       *time.Time     `json:"created_at"`
-	UpdatedAt       *time.Time     `json:"updated_at"`
-	UserID          int            `json:"user_id"`
+	ID                int              `json:"id"`
+	Title             string           `json:"title"`
+	Description       string           `json:"description"`
+	DurationMinutes   int              `json:"duration_minutes"`
+	CaloriesBurned    int              `json:"calories_burned"`
+	CaloriesEstimated bool             `json:"calories_estimated"`
+	CaloriesFormula   *string          `json:"calories_formula"`
+	Entries           []WorkoutEntry   `json:"entries" validate:"dive"`
+	Tags              []string         `json:"tags" validate:"max=20,dive,max=50"`
+	CreatedAt         *time.Time       `json:"created_at"`
+	UpdatedAt         *time.Time       `json:"updated_at"`
+	UserID            int              `json:"user_id"`
+	TemplateID        *int             `json:"template_id"`
+	Status            string           `json:"status" validate:"omitempty,oneof=planned completed"`
+	Visibility        *string          `json:"visibility" validate:"omitempty,oneof=public followers private"`
+	StartedAt         *time.Time       `json:"started_at"`
+	FinishedAt        *time.Time       `json:"finished_at"`
+	DeletedAt         *time.Time       `json:"deleted_at,omitempty"`
+	PersonalRecords   []PersonalRecord `json:"personal_records,omitempty"`
+	Track             *WorkoutTrack    `json:"track,omitempty"`
+	ImportKey         *string          `json:"-"`
+}
+
+const (
+	WorkoutPlanned    = "planned"
+	WorkoutInProgress = "in_progress"
+	WorkoutPaused     = "paused"
+	WorkoutCompleted  = "completed"
+)
+
+const workoutColumns = `id, title, description, duration_minutes, calories_burned, calories_estimated, calories_formula, created_at, updated_at, user_id, template_id, status, started_at, finished_at, deleted_at,
+	visibility`
+
+func scanWorkout(scanner interface{ Scan(dest ...any) error }, workout *Workout) error {
+	return scanner.Scan(
+		&workout.ID,
+		&workout.Title,
+		&workout.Description,
+		&workout.DurationMinutes,
+		&workout.CaloriesBurned,
+		&workout.CaloriesEstimated,
+		&workout.CaloriesFormula,
+		&workout.CreatedAt,
+		&workout.UpdatedAt,
+		&workout.UserID,
+		&workout.TemplateID,
+		&workout.Status,
+		&workout.StartedAt,
+		&workout.FinishedAt,
+		&workout.DeletedAt,
+		&workout.Visibility,
+	)
 }
 
 type WorkoutEntry struct {
 	ID              int     `json:"id"`
+	ExerciseID      *int    `json:"exercise_id"`
 	ExerciseName    string  `json:"exercise_name"`
 	Reps            *int    `json:"reps"`
 	Sets            int     `json:"sets"`
@@ -30,6 +82,361 @@ type WorkoutEntry struct {
 	CreatedAt       *time.Time
 	UpdatedAt       *time.Time
 	UserID          int
+	WorkoutSets     []WorkoutSet `json:"workout_sets" validate:"dive"`
+	Group           *EntryGroup  `json:"group"`
+}
+
+const (
+	GroupSuperset = "superset"
+	GroupCircuit  = "circuit"
+	GroupGiantSet = "giant_set"
+)
+
+// EntryGroup links consecutive entries performed back to back, such as a
+// superset. ID only identifies the group inside its workout and every member
+// repeats the same type, rounds and rest between rounds.
+type EntryGroup struct {
+	ID          int    `json:"id" validate:"min=1"`
+	Type        string `json:"type" validate:"oneof=superset circuit giant_set"`
+	Rounds      int    `json:"rounds" validate:"min=1,max=100"`
+	RestSeconds *int   `json:"rest_seconds" validate:"omitempty,min=0"`
+}
+
+// UnmarshalJSON defaults omitted rounds to a single round.
+func (g *EntryGroup) UnmarshalJSON(data []byte) error {
+	type entryGroup EntryGroup
+	group := entryGroup{Rounds: 1}
+
+	if err := json.Unmarshal(data, &group); err != nil {
+		return err
+	}
+
+	*g = EntryGroup(group)
+
+	return nil
+}
+
+// columns returns the values of the group columns, all nil for ungrouped entries.
+func (g *EntryGroup) columns() []any {
+	if g == nil {
+		return []any{nil, nil, nil, nil}
+	}
+
+	return []any{g.ID, g.Type, g.Rounds, g.RestSeconds}
+}
+
+// groupSizes bounds how many entries each group type takes; 0 means unbounded.
+var groupSizes = map[string]struct{ min, max int }{
+	GroupSuperset: {2, 2},
+	GroupCircuit:  {2, 0},
+	GroupGiantSet: {3, 0},
+}
+
+// ValidateEntryGroups rejects groups whose members disagree on type, rounds
+// or rest, are not consecutive in order_index or have the wrong size.
+func ValidateEntryGroups(entries []WorkoutEntry) error {
+	ordered := make([]*WorkoutEntry, len(entries))
+
+	for i := range entries {
+		ordered[i] = &entries[i]
+	}
+
+	slices.SortStableFunc(ordered, func(a, b *WorkoutEntry) int {
+		return cmp.Compare(a.OrderIndex, b.OrderIndex)
+	})
+
+	groups := make(map[int]*EntryGroup)
+	sizes := make(map[int]int)
+	var previous *EntryGroup
+
+	for _, entry := range ordered {
+		group := entry.Group
+
+		if group == nil {
+			previous = nil
+			continue
+		}
+
+		first, seen := groups[group.ID]
+
+		if !seen {
+			groups[group.ID] = group
+			first = group
+		} else if previous == nil || previous.ID != group.ID {
+			return fmt.Errorf("%w: os exercícios do grupo %d devem ser consecutivos", internalErrors.ErrInvalidEntryGroup, group.ID)
+		}
+
+		if group.Type != first.Type || group.Rounds != first.Rounds || !equalPointers(group.RestSeconds, first.RestSeconds) {
+			return fmt.Errorf("%w: os exercícios do grupo %d devem ter o mesmo tipo, rodadas e descanso", internalErrors.ErrInvalidEntryGroup, group.ID)
+		}
+
+		sizes[group.ID]++
+		previous = group
+	}
+
+	for id, group := range groups {
+		size, bounds := sizes[id], groupSizes[group.Type]
+
+		if size < bounds.min || (bounds.max > 0 && size > bounds.max) {
+			return fmt.Errorf("%w: o grupo %d (%s) não pode ter %d exercício(s)", internalErrors.ErrInvalidEntryGroup, id, group.Type, size)
+		}
+	}
+
+	return nil
+}
+
+func equalPointers[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+const (
+	SetTypeWarmUp  = "warm_up"
+	SetTypeWorking = "working"
+	SetTypeDrop    = "drop"
+	SetTypeFailure = "failure"
+)
+
+type WorkoutSet struct {
+	ID              int        `json:"id"`
+	SetNumber       int        `json:"set_number"`
+	SetType         string     `json:"set_type" validate:"oneof=warm_up working drop failure"`
+	Reps            *int       `json:"reps" validate:"required_without=DurationSeconds,omitempty,min=0"`
+	Weight          float64    `json:"weight" validate:"min=0"`
+	DurationSeconds *int       `json:"duration_seconds" validate:"omitempty,min=0"`
+	RPE             *float64   `json:"rpe" validate:"omitempty,min=1,max=10"`
+	RIR             *int       `json:"rir" validate:"omitempty,min=0"`
+	Completed       bool       `json:"completed"`
+	CreatedAt       *time.Time `json:"created_at"`
+	UpdatedAt       *time.Time `json:"updated_at"`
+
+	// completedOmitted tells that Completed was not sent, so it follows the
+	// status of the workout when the set is written.
+	completedOmitted bool
+}
+
+// UnmarshalJSON defaults omitted fields to a working set. Whether an omitted
+// completed flag means done is only known once the workout status is.
+func (s *WorkoutSet) UnmarshalJSON(data []byte) error {
+	type workoutSet WorkoutSet
+	set := struct {
+		workoutSet
+		Completed *bool `json:"completed"`
+	}{workoutSet: workoutSet{SetType: SetTypeWorking}}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return err
+	}
+
+	*s = WorkoutSet(set.workoutSet)
+	s.Completed = set.Completed != nil && *set.Completed
+	s.completedOmitted = set.Completed == nil
+
+	return nil
+}
+
+// syncSets keeps the legacy sets/reps/weight columns and the per-set rows in
+// agreement. Entries sent with only the old triple are expanded into identical
+// working sets, entries sent per set get the triple derived from their top set.
+// Like the triple, the legacy duration_seconds is per set: timed entries get
+// their longest set.
+func (e *WorkoutEntry) syncSets() {
+	if len(e.WorkoutSets) == 0 {
+		for i := 0; i < max(e.Sets, 1); i++ {
+			e.WorkoutSets = append(e.WorkoutSets, WorkoutSet{
+				SetType:          SetTypeWorking,
+				Reps:             e.Reps,
+				Weight:           e.Weight,
+				DurationSeconds:  e.DurationSeconds,
+				completedOmitted: true,
+			})
+		}
+
+		return
+	}
+
+	var top *WorkoutSet
+	longestDuration := 0
+	maxWeight := 0.0
+
+	for i := range e.WorkoutSets {
+		set := &e.WorkoutSets[i]
+		maxWeight = max(maxWeight, set.Weight)
+
+		if set.DurationSeconds != nil {
+			longestDuration = max(longestDuration, *set.DurationSeconds)
+		}
+
+		if set.Reps == nil {
+			continue
+		}
+
+		if top == nil || set.Weight > top.Weight || (set.Weight == top.Weight && *set.Reps > *top.Reps) {
+			top = set
+		}
+	}
+
+	e.Sets = len(e.WorkoutSets)
+
+	if top != nil {
+		reps := *top.Reps
+		e.Reps = &reps
+		e.Weight = top.Weight
+		e.DurationSeconds = nil
+
+		return
+	}
+
+	e.Reps = nil
+	e.Weight = maxWeight
+	e.DurationSeconds = &longestDuration
+}
+
+// defaultCompleted settles the sets whose completed flag was omitted: they
+// were done in a completed workout and are still to be done in a planned or
+// live one.
+func (e *WorkoutEntry) defaultCompleted(status string) {
+	for i := range e.WorkoutSets {
+		if e.WorkoutSets[i].completedOmitted {
+			e.WorkoutSets[i].Completed = status == WorkoutCompleted
+		}
+	}
+}
+
+// Duplicate copies the workout and its entries into a new workout of userID,
+// created at createdAt (now when nil). The copy is planned with none of its
+// sets done, unless completed asks to log it as finished, in which case the
+// sets keep whether they were done. Estimated calories are left out so they
+// are estimated again.
+func (w *Workout) Duplicate(userID int, createdAt *time.Time, completed bool) *Workout {
+	status := WorkoutPlanned
+
+	if completed {
+		status = WorkoutCompleted
+	}
+
+	duplicate := &Workout{
+		Title:           w.Title,
+		Description:     w.Description,
+		DurationMinutes: w.DurationMinutes,
+		UserID:          userID,
+		TemplateID:      copyPointer(w.TemplateID),
+		Status:          status,
+		CreatedAt:       createdAt,
+		Entries:         make([]WorkoutEntry, 0, len(w.Entries)),
+		Tags:            slices.Clone(w.Tags),
+		Visibility:      copyPointer(w.Visibility),
+	}
+
+	if !w.CaloriesEstimated {
+		duplicate.CaloriesBurned = w.CaloriesBurned
+	}
+
+	for _, entry := range w.Entries {
+		entry.ID, entry.CreatedAt, entry.UpdatedAt = 0, nil, nil
+		entry.UserID = userID
+		entry.ExerciseID = copyPointer(entry.ExerciseID)
+		entry.Reps = copyPointer(entry.Reps)
+		entry.DurationSeconds = copyPointer(entry.DurationSeconds)
+
+		if entry.Group != nil {
+			group := *entry.Group
+			entry.Group = &group
+		}
+
+		sets := make([]WorkoutSet, 0, len(entry.WorkoutSets))
+
+		for _, set := range entry.WorkoutSets {
+			set.ID, set.CreatedAt, set.UpdatedAt = 0, nil, nil
+			set.Completed = completed && set.Completed
+			sets = append(sets, set)
+		}
+
+		entry.WorkoutSets = sets
+		duplicate.Entries = append(duplicate.Entries, entry)
+	}
+
+	return duplicate
+}
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// DefaultTrashRetention is how long deleted workouts stay in the trash before
+// they are purged.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// ExportChunkSize is how many workouts ExportWorkouts loads per query.
+const ExportChunkSize = 200
+
+// WorkoutFilters narrows and orders the result of GetAllWorkouts. From is
+// inclusive and To is exclusive, both compared against created_at.
+type WorkoutFilters struct {
+	Limit  int
+	Cursor string
+	From   *time.Time
+	To     *time.Time
+	Search string
+	Sort   string
+	Order  string
+	// Tags keeps the workouts with any of the tags, or with all of them when
+	// TagMatch is TagMatchAll. Names are compared ignoring case.
+	Tags     []string
+	TagMatch string
+}
+
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// workoutSortColumns maps the accepted sort keys to the type used to cast cursor values.
+var workoutSortColumns = map[string]string{
+	"created_at":       "timestamptz",
+	"title":            "text",
+	"duration_minutes": "integer",
+	"calories_burned":  "integer",
+}
+
+func (f *WorkoutFilters) normalize() {
+	if f.Limit <= 0 {
+		f.Limit = DefaultPageLimit
+	}
+
+	if f.Limit > MaxPageLimit {
+		f.Limit = MaxPageLimit
+	}
+
+	if f.Sort == "" {
+		f.Sort = "created_at"
+	}
+
+	if f.Order != "asc" {
+		f.Order = "desc"
+	}
+}
+
+func workoutSortValue(workout *Workout, sort string) string {
+	switch sort {
+	case "title":
+		return workout.Title
+	case "duration_minutes":
+		return strconv.Itoa(workout.DurationMinutes)
+	case "calories_burned":
+		return strconv.Itoa(workout.CaloriesBurned)
+	default:
+		return workout.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
 }
 
 type WorkoutStore interface {
@@ -37,8 +444,19 @@ type WorkoutStore interface {
 	UpdateWorkout(id int, workout *Workout) (*Workout, error)
 	GetWorkoutById(id int) (*Workout, error)
 	DeleteWorkout(id int) error
-	GetAllWorkouts(userID int) ([]Workout, error)
+	GetTrash(userID int) ([]Workout, error)
+	RestoreWorkout(id int, userID int) (*Workout, error)
+	PurgeDeletedWorkouts(deletedBefore time.Time) (int, error)
+	GetAllWorkouts(userID int, filters WorkoutFilters) ([]Workout, *Pagination, error)
 	OwnsWorkout(id int, userID int) (bool, error)
+	ConvertPoundsHistory(userID int, before time.Time) (int, error)
+	ExportWorkouts(userID int, from, to *time.Time, fn func(workout *Workout) error) error
+	ImportWorkouts(userID int, workouts []Workout, dryRun bool) (*ImportResult, error)
+}
+
+type ImportResult struct {
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"`
 }
 
 type PostgresWorkoutStore struct {
@@ -51,18 +469,86 @@ func NewPostgresWorkoutStore(db *sql.DB) *PostgresWorkoutStore {
 	}
 }
 
-func (s *PostgresWorkoutStore) GetAllWorkouts(userID int) ([]Workout, error) {
-	query := `
-		select id, title, description, duration_minutes, calories_burned, created_at, updated_at, user_id
+func (s *PostgresWorkoutStore) GetAllWorkouts(userID int, filters WorkoutFilters) ([]Workout, *Pagination, error) {
+	filters.normalize()
+	sortType, ok := workoutSortColumns[filters.Sort]
+
+	if !ok {
+		return nil, nil, internalErrors.ErrInvalidQueryParam
+	}
+
+	conditions := []string{"user_id = $1", "deleted_at is null"}
+	args := []any{userID}
+
+	if filters.From != nil {
+		args = append(args, *filters.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	if filters.To != nil {
+		args = append(args, *filters.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	if filters.Search != "" {
+		args = append(args, "%"+escapeLike(filters.Search)+"%")
+		conditions = append(conditions, fmt.Sprintf("title ilike $%d", len(args)))
+	}
+
+	if tags := NormalizeTags(filters.Tags); len(tags) > 0 {
+		for i := range tags {
+			tags[i] = strings.ToLower(tags[i])
+		}
+
+		args = append(args, tags)
+		matchingTags := fmt.Sprintf(`
+			select count(*) from workout_tags
+			join tags on tags.id = workout_tags.tag_id
+			where workout_tags.workout_id = workouts.id and lower(tags.name) = any($%d)
+		`, len(args))
+		minimum := 1
+
+		if filters.TagMatch == TagMatchAll {
+			minimum = len(tags)
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(%s) >= %d", matchingTags, minimum))
+	}
+
+	comparator := "<"
+
+	if filters.Order == "asc" {
+		comparator = ">"
+	}
+
+	if filters.Cursor != "" {
+		c, err := decodeCursor(filters.Cursor, filters.Sort)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		args = append(args, c.Value, c.ID)
+		conditions = append(conditions, fmt.Sprintf(
+			"(%s, id) %s ($%d::%s, $%d)",
+			filters.Sort, comparator, len(args)-1, sortType, len(args),
+		))
+	}
+
+	args = append(args, filters.Limit+1)
+
+	query := fmt.Sprintf(`
+		select %s
 		from workouts
-		where user_id = $1
-		order by created_at desc
-	`
+		where %s
+		order by %s %s, id %s
+		limit $%d
+	`, workoutColumns, strings.Join(conditions, " and "), filters.Sort, filters.Order, filters.Order, len(args))
 
-	rows, err := s.db.Query(query, userID)
+	rows, err := s.db.Query(query, args...)
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer func() {
@@ -74,56 +560,54 @@ func (s *PostgresWorkoutStore) GetAllWorkouts(userID int) ([]Workout, error) {
 	for rows.Next() {
 		workout := &Workout{}
 
-		err := rows.Scan(
-			&workout.ID,
-			&workout.Title,
-			&workout.Description,
-			&workout.DurationMinutes,
-			&workout.CaloriesBurned,
-			&workout.CreatedAt,
-			&workout.UpdatedAt,
-			&workout.UserID,
-		)
-
-		if err != nil {
-			return nil, err
+		if err := scanWorkout(rows, workout); err != nil {
+			return nil, nil, err
 		}
 
 		workouts = append(workouts, *workout)
 	}
 
-	return workouts, nil
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := loadWorkoutTags(s.db, workouts); err != nil {
+		return nil, nil, err
+	}
+
+	pagination := &Pagination{Limit: filters.Limit}
+
+	if len(workouts) > filters.Limit {
+		workouts = workouts[:filters.Limit]
+		last := workouts[len(workouts)-1]
+
+		pagination.HasMore = true
+		pagination.NextCursor = encodeCursor(cursor{
+			Sort:  filters.Sort,
+			Value: workoutSortValue(&last, filters.Sort),
+			ID:    last.ID,
+		})
+	}
+
+	return workouts, pagination, nil
 }
 
 func (s *PostgresWorkoutStore) GetWorkoutById(id int) (*Workout, error) {
 	workout := &Workout{}
-	query := `
-		select id, title, description, duration_minutes, calories_burned, created_at, updated_at, user_id
-		from workouts
-		where id = $1
-	`
+	query := fmt.Sprintf(`select %s from workouts where id = $1 and deleted_at is null`, workoutColumns)
 
-	err := s.db.QueryRow(query, id).Scan(
-		&workout.ID,
-		&workout.Title,
-		&workout.Description,
-		&workout.DurationMinutes,
-		&workout.CaloriesBurned,
-		&workout.CreatedAt,
-		&workout.UpdatedAt,
-		&workout.UserID,
-	)
+	err := scanWorkout(s.db.QueryRow(query, id), workout)
 
 	if err != nil {
 		return nil, err
 	}
 
-	entriesQuery := `
-		select id, exercise_name, sets, reps, duration_seconds, weight, notes, order_index, created_at, updated_at, user_id
+	entriesQuery := fmt.Sprintf(`
+		select %s
 		from workout_entries
 		where workout_id = $1
 		order by order_index
-	`
+	`, workoutEntryColumns)
 
 	rows, err := s.db.Query(entriesQuery, id)
 
@@ -138,92 +622,41 @@ func (s *PostgresWorkoutStore) GetWorkoutById(id int) (*Workout, error) {
 	for rows.Next() {
 		entry := WorkoutEntry{}
 
-		err := rows.Scan(
-			&entry.ID,
-			&entry.ExerciseName,
-			&entry.Sets,
-			&entry.Reps,
-			&entry.DurationSeconds,
-			&entry.Weight,
-			&entry.Notes,
-			&entry.OrderIndex,
-			&entry.CreatedAt,
-			&entry.UpdatedAt,
-			&entry.UserID,
-		)
-
-		if err != nil {
+		if err := scanWorkoutEntry(rows, &entry); err != nil {
 			return nil, err
 		}
 
 		workout.Entries = append(workout.Entries, entry)
 	}
 
-	return workout, nil
-}
-
-func (s *PostgresWorkoutStore) CreateWorkout(workout *Workout) (*Workout, error) {
-	tx, err := s.db.Begin()
-
-	if err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	defer func() {
-		_ = tx.Rollback()
-	}()
-
-	query := `
-			insert into workouts (title, description, duration_minutes, calories_burned, user_id)
-			values ($1, $2, $3, $4, $5)
-			returning id, created_at, updated_at
-	`
-
-	err = tx.QueryRow(
-		query,
-		workout.Title,
-		workout.Description,
-		workout.DurationMinutes,
-		workout.CaloriesBurned,
-		workout.UserID).Scan(&workout.ID, &workout.CreatedAt, &workout.UpdatedAt)
+	err = loadWorkoutSets(s.db, workout.Entries)
 
 	if err != nil {
 		return nil, err
 	}
 
-	for _, entry := range workout.Entries {
-		query := `
-			insert into workout_entries (workout_id, exercise_name, sets, reps, duration_seconds, weight, notes, order_index, user_id)
-			values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-			returning id, created_at, updated_at
-		`
-
-		err := tx.QueryRow(
-			query,
-			workout.ID,
-			entry.ExerciseName,
-			entry.Sets,
-			entry.Reps,
-			entry.DurationSeconds,
-			entry.Weight,
-			entry.Notes,
-			entry.OrderIndex, entry.UserID).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	workout.Track, err = getTrackSummary(s.db, id)
 
-		if err != nil {
-			return nil, err
-		}
+	if err != nil {
+		return nil, err
 	}
 
-	err = tx.Commit()
+	workouts := []Workout{*workout}
 
-	if err != nil {
+	if err := loadWorkoutTags(s.db, workouts); err != nil {
 		return nil, err
 	}
 
+	workout.Tags = workouts[0].Tags
+
 	return workout, nil
 }
 
-func (s *PostgresWorkoutStore) UpdateWorkout(id int, workout *Workout) (*Workout, error) {
+func (s *PostgresWorkoutStore) CreateWorkout(workout *Workout) (*Workout, error) {
 	tx, err := s.db.Begin()
 
 	if err != nil {
@@ -234,63 +667,40 @@ func (s *PostgresWorkoutStore) UpdateWorkout(id int, workout *Workout) (*Workout
 		_ = tx.Rollback()
 	}()
 
-	query := `
-		update workouts
-		set title = $2, description = $3, duration_minutes = $4, calories_burned = $5
-		where id = $1
-	`
+	err = insertWorkout(tx, workout)
 
-	workout.ID = int(id)
+	if err != nil {
+		return nil, err
+	}
 
-	result, err := tx.Exec(query,
-		id,
-		workout.Title,
-		workout.Description,
-		workout.DurationMinutes,
-		workout.CaloriesBurned)
+	exerciseKeys, err := workoutExerciseKeys(tx, workout.ID)
 
 	if err != nil {
 		return nil, err
 	}
 
-	affectedRows, err := result.RowsAffected()
+	err = recomputePersonalRecords(tx, workout.UserID, exerciseKeys)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if affectedRows == 0 {
-		return nil, internalErrors.ErrNoRows
+	workout.PersonalRecords, err = getWorkoutPersonalRecords(tx, workout.ID)
+
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = tx.Exec("delete from workout_entries where workout_id = $1", id)
+	err = refreshGoals(tx, workout.UserID)
 
 	if err != nil {
 		return nil, err
 	}
 
-	for i, entry := range workout.Entries {
-		query := `
-			insert into workout_entries (workout_id, exercise_name, sets, reps, duration_seconds, weight, notes, order_index, user_id)
-			values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-			returning id
-		`
+	err = recordRevision(tx, workout.ID, &workout.UserID, RevisionCreate)
 
-		err := tx.QueryRow(
-			query,
-			id,
-			entry.ExerciseName,
-			entry.Sets,
-			entry.Reps,
-			entry.DurationSeconds,
-			entry.Weight,
-			entry.Notes,
-			entry.OrderIndex,
-			entry.UserID).Scan(&workout.Entries[i].ID)
-
-		if err != nil {
-			return nil, err
-		}
+	if err != nil {
+		return nil, err
 	}
 
 	err = tx.Commit()
@@ -302,34 +712,831 @@ func (s *PostgresWorkoutStore) UpdateWorkout(id int, workout *Workout) (*Workout
 	return workout, nil
 }
 
-func (s *PostgresWorkoutStore) DeleteWorkout(id int) error {
-	result, err := s.db.Exec("delete from workouts where id = $1", id)
+func (s *PostgresWorkoutStore) UpdateWorkout(id int, workout *Workout) (*Workout, error) {
+	tx, err := s.db.Begin()
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	err = recordBaselineRevision(tx, id)
 
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	err = updateWorkout(tx, id, workout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordRevision(tx, id, &workout.UserID, RevisionUpdate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return workout, nil
+}
+
+// updateWorkout overwrites the workout and its entries, then brings personal
+// records and goals up to date. Entries and sets with the id of one of the
+// workout are updated in place and keep it, the others are added and the
+// missing ones removed.
+func updateWorkout(tx *sql.Tx, id int, workout *Workout) error {
+	query := `
+		update workouts
+		set title = $2, description = $3, duration_minutes = $4, calories_burned = $5,
+		    calories_estimated = $6, calories_formula = $7, visibility = coalesce($8, visibility), updated_at = now()
+		where id = $1
+		returning user_id, visibility, status
+	`
+
+	workout.ID = int(id)
+
+	err := tx.QueryRow(query,
+		id,
+		workout.Title,
+		workout.Description,
+		workout.DurationMinutes,
+		workout.CaloriesBurned,
+		workout.CaloriesEstimated,
+		workout.CaloriesFormula,
+		workout.Visibility).Scan(&workout.UserID, &workout.Visibility, &workout.Status)
+
+	if err != nil {
+		return err
+	}
+
+	previousExerciseKeys, err := workoutExerciseKeys(tx, id)
+
+	if err != nil {
+		return err
+	}
+
+	currentIDs, err := scanIDs(tx, "select id from workout_entries where workout_id = $1", id)
+
+	if err != nil {
+		return err
+	}
+
+	keptIDs := make([]int, 0)
+
+	for i := range workout.Entries {
+		entry := &workout.Entries[i]
+
+		if !slices.Contains(currentIDs, entry.ID) || slices.Contains(keptIDs, entry.ID) {
+			entry.ID = 0
+			continue
+		}
+
+		keptIDs = append(keptIDs, entry.ID)
+	}
+
+	_, err = tx.Exec("delete from workout_entries where workout_id = $1 and id <> all($2)", id, keptIDs)
+
+	if err != nil {
+		return err
+	}
+
+	for i := range workout.Entries {
+		entry := &workout.Entries[i]
+
+		if entry.ID == 0 {
+			err = insertWorkoutEntry(tx, id, workout.Status, entry)
+		} else {
+			err = updateWorkoutEntry(tx, id, workout.Status, entry)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if workout.Tags != nil {
+		if err := setWorkoutTags(tx, id, workout.UserID, workout.Tags); err != nil {
+			return err
+		}
+
+		workout.Tags = NormalizeTags(workout.Tags)
+	}
+
+	err = estimateCalories(tx, workout)
+
+	if err != nil {
+		return err
+	}
+
+	exerciseKeys, err := workoutExerciseKeys(tx, id)
+
+	if err != nil {
+		return err
 	}
 
-	if rowsAffected == 0 {
-		return internalErrors.ErrNoRows
+	err = recomputePersonalRecords(tx, workout.UserID, append(previousExerciseKeys, exerciseKeys...))
+
+	if err != nil {
+		return err
 	}
 
-	return err
+	workout.PersonalRecords, err = getWorkoutPersonalRecords(tx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return refreshGoals(tx, workout.UserID)
+}
+
+// insertWorkout writes the workout with its entries and estimates its calories
+// when they were omitted. Personal records and goals are left to the caller,
+// which may insert several workouts at once. CreatedAt defaults to now and
+// Visibility to the current default of the account. A
+// workout whose ImportKey the user already has is not written and returns
+// ErrNoRows, even when a concurrent import wrote it first.
+func insertWorkout(tx *sql.Tx, workout *Workout) error {
+	query := `
+		insert into workouts (
+			title, description, duration_minutes, calories_burned, user_id, template_id, status,
+			created_at, started_at, finished_at, import_key, visibility
+		)
+		values (
+			$1, $2, $3, $4, $5, $6, coalesce(nullif($7, ''), 'completed'), coalesce($8, now()), $9, $10, $11,
+			coalesce($12, (select default_visibility from users where id = $5))
+		)
+		on conflict (user_id, import_key) where import_key is not null do nothing
+		returning id, created_at, updated_at, status, visibility
+	`
+
+	err := tx.QueryRow(
+		query,
+		workout.Title,
+		workout.Description,
+		workout.DurationMinutes,
+		workout.CaloriesBurned,
+		workout.UserID,
+		workout.TemplateID,
+		workout.Status,
+		workout.CreatedAt,
+		workout.StartedAt,
+		workout.FinishedAt,
+		workout.ImportKey,
+		workout.Visibility).Scan(&workout.ID, &workout.CreatedAt, &workout.UpdatedAt, &workout.Status, &workout.Visibility)
+
+	if err != nil {
+		return err
+	}
+
+	for i := range workout.Entries {
+		err := insertWorkoutEntry(tx, workout.ID, workout.Status, &workout.Entries[i])
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(workout.Tags) > 0 {
+		if err := setWorkoutTags(tx, workout.ID, workout.UserID, workout.Tags); err != nil {
+			return err
+		}
+	}
+
+	workout.Tags = NormalizeTags(workout.Tags)
+
+	return estimateCalories(tx, workout)
+}
+
+// insertWorkoutEntry links the entry to the catalog by name when no
+// exercise_id was given and writes its sets in the same transaction. status is
+// the one of the workout and settles sets sent without a completed flag.
+func insertWorkoutEntry(tx *sql.Tx, workoutID int, status string, entry *WorkoutEntry) error {
+	entry.syncSets()
+	entry.defaultCompleted(status)
+
+	query := fmt.Sprintf(`
+		insert into workout_entries (
+			workout_id, exercise_name, sets, reps, duration_seconds, weight, notes, order_index, user_id, exercise_id,
+			group_id, group_type, group_rounds, group_rest_seconds
+		)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, coalesce($10, (
+			select id from exercises
+			where (user_id is null or user_id = $9) and %s
+			order by user_id nulls last, id
+			limit 1
+		)), $11, $12, $13, $14)
+		returning id, exercise_id, created_at, updated_at
+	`, exerciseMatchesName)
+
+	args := []any{
+		workoutID,
+		entry.ExerciseName,
+		entry.Sets,
+		entry.Reps,
+		entry.DurationSeconds,
+		entry.Weight,
+		entry.Notes,
+		entry.OrderIndex,
+		entry.UserID,
+		entry.ExerciseID,
+	}
+
+	err := tx.QueryRow(query, append(args, entry.Group.columns()...)...).Scan(&entry.ID, &entry.ExerciseID, &entry.CreatedAt, &entry.UpdatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	for i := range entry.WorkoutSets {
+		entry.WorkoutSets[i].SetNumber = i + 1
+
+		if err := insertWorkoutSet(tx, entry.ID, &entry.WorkoutSets[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func insertWorkoutSet(tx *sql.Tx, entryID int, set *WorkoutSet) error {
+	query := `
+		insert into workout_sets (workout_entry_id, set_number, set_type, reps, weight, duration_seconds, rpe, rir, completed)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		returning id, created_at, updated_at
+	`
+
+	return tx.QueryRow(
+		query,
+		entryID,
+		set.SetNumber,
+		set.SetType,
+		set.Reps,
+		set.Weight,
+		set.DurationSeconds,
+		set.RPE,
+		set.RIR,
+		set.Completed).Scan(&set.ID, &set.CreatedAt, &set.UpdatedAt)
+}
+
+const workoutEntryColumns = `id, exercise_id, exercise_name, sets, reps, duration_seconds, weight, notes, order_index, created_at, updated_at, user_id,
+	group_id, group_type, group_rounds, group_rest_seconds`
+
+func scanWorkoutEntry(scanner interface{ Scan(dest ...any) error }, entry *WorkoutEntry) error {
+	var groupID, groupRounds, groupRestSeconds *int
+	var groupType *string
+
+	err := scanner.Scan(
+		&entry.ID,
+		&entry.ExerciseID,
+		&entry.ExerciseName,
+		&entry.Sets,
+		&entry.Reps,
+		&entry.DurationSeconds,
+		&entry.Weight,
+		&entry.Notes,
+		&entry.OrderIndex,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+		&entry.UserID,
+		&groupID,
+		&groupType,
+		&groupRounds,
+		&groupRestSeconds,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if groupID != nil {
+		entry.Group = &EntryGroup{ID: *groupID, Type: *groupType, Rounds: *groupRounds, RestSeconds: groupRestSeconds}
+	}
+
+	return nil
+}
+
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// loadWorkoutSets fills the sets of the given entries with a single query.
+func loadWorkoutSets(q queryer, entries []WorkoutEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	entryIDs := make([]int, len(entries))
+	entriesByID := make(map[int]*WorkoutEntry, len(entries))
+
+	for i := range entries {
+		entryIDs[i] = entries[i].ID
+		entriesByID[entries[i].ID] = &entries[i]
+		entries[i].WorkoutSets = make([]WorkoutSet, 0)
+	}
+
+	query := `
+		select workout_entry_id, id, set_number, set_type, reps, weight, duration_seconds, rpe, rir, completed, created_at, updated_at
+		from workout_sets
+		where workout_entry_id = any($1)
+		order by workout_entry_id, set_number
+	`
+
+	rows, err := q.Query(query, entryIDs)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var entryID int
+		set := WorkoutSet{}
+
+		err := rows.Scan(
+			&entryID,
+			&set.ID,
+			&set.SetNumber,
+			&set.SetType,
+			&set.Reps,
+			&set.Weight,
+			&set.DurationSeconds,
+			&set.RPE,
+			&set.RIR,
+			&set.Completed,
+			&set.CreatedAt,
+			&set.UpdatedAt,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		entry := entriesByID[entryID]
+		entry.WorkoutSets = append(entry.WorkoutSets, set)
+	}
+
+	return rows.Err()
+}
+
+// DeleteWorkout moves the workout to the trash. It stops counting towards
+// records, goals and stats right away and is purged after the retention period.
+// A live session is reset to planned, so it no longer keeps the user from
+// starting another one.
+func (s *PostgresWorkoutStore) DeleteWorkout(id int) error {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	exerciseKeys, err := workoutExerciseKeys(tx, id)
+
+	if err != nil {
+		return err
+	}
+
+	query := `
+		update workouts
+		set deleted_at = now(),
+		    status = case when status in ('in_progress', 'paused') then 'planned' else status end,
+		    started_at = case when status in ('in_progress', 'paused') then null else started_at end,
+		    last_activity_at = case when status in ('in_progress', 'paused') then null else last_activity_at end
+		where id = $1 and deleted_at is null
+		returning user_id, status
+	`
+
+	var userID int
+	var status string
+
+	if err := tx.QueryRow(query, id).Scan(&userID, &status); err != nil {
+		return err
+	}
+
+	if status == WorkoutPlanned {
+		_, err = tx.Exec("delete from workout_session_events where workout_id = $1", id)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err = recomputePersonalRecords(tx, userID, exerciseKeys)
+
+	if err != nil {
+		return err
+	}
+
+	err = refreshGoals(tx, userID)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetTrash lists the deleted workouts of the user, most recently deleted first.
+func (s *PostgresWorkoutStore) GetTrash(userID int) ([]Workout, error) {
+	query := fmt.Sprintf(`
+		select %s
+		from workouts
+		where user_id = $1 and deleted_at is not null
+		order by deleted_at desc, id desc
+	`, workoutColumns)
+
+	workouts := make([]Workout, 0)
+
+	err := scanRows(s.db, query, []any{userID}, func(rows *sql.Rows) error {
+		workout := Workout{}
+
+		if err := scanWorkout(rows, &workout); err != nil {
+			return err
+		}
+
+		workouts = append(workouts, workout)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadWorkoutTags(s.db, workouts); err != nil {
+		return nil, err
+	}
+
+	return workouts, nil
+}
+
+// RestoreWorkout takes a workout of the user out of the trash. Workouts that
+// are not in the user's trash return ErrNoRows.
+func (s *PostgresWorkoutStore) RestoreWorkout(id int, userID int) (*Workout, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.Exec(
+		"update workouts set deleted_at = null where id = $1 and user_id = $2 and deleted_at is not null", id, userID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := result.RowsAffected()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if restored == 0 {
+		return nil, internalErrors.ErrNoRows
+	}
+
+	exerciseKeys, err := workoutExerciseKeys(tx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = recomputePersonalRecords(tx, userID, exerciseKeys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = refreshGoals(tx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetWorkoutById(id)
+}
+
+// PurgeDeletedWorkouts removes for good the workouts deleted before the given
+// time, with their entries and sets. Returns how many were purged.
+func (s *PostgresWorkoutStore) PurgeDeletedWorkouts(deletedBefore time.Time) (int, error) {
+	result, err := s.db.Exec("delete from workouts where deleted_at < $1", deletedBefore)
+
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+
+	return int(purged), err
+}
+
+// ImportWorkouts writes workouts brought from another app in one transaction.
+// Workouts whose ImportKey was already imported are skipped and counted as
+// duplicates, so uploading the same file twice, even at the same time, is
+// harmless. A dry run reports the same counts and rolls everything back.
+func (s *PostgresWorkoutStore) ImportWorkouts(userID int, workouts []Workout, dryRun bool) (*ImportResult, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result := &ImportResult{}
+
+	for i := range workouts {
+		workout := &workouts[i]
+		workout.UserID = userID
+
+		err := insertWorkout(tx, workout)
+
+		if errors.Is(err, internalErrors.ErrNoRows) {
+			result.Duplicates++
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		result.Imported++
+	}
+
+	if result.Imported == 0 || dryRun {
+		return result, nil
+	}
+
+	exerciseKeys, err := userExerciseKeys(tx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recomputePersonalRecords(tx, userID, exerciseKeys); err != nil {
+		return nil, err
+	}
+
+	if err := refreshGoals(tx, userID); err != nil {
+		return nil, err
+	}
+
+	return result, tx.Commit()
+}
+
+// ExportWorkouts hands every workout of the user in the range to fn, oldest
+// first and with its entries and sets. Workouts are read ExportChunkSize at a
+// time, so memory stays flat however long the history is.
+func (s *PostgresWorkoutStore) ExportWorkouts(userID int, from, to *time.Time, fn func(workout *Workout) error) error {
+	var lastCreatedAt *time.Time
+	lastID := 0
+
+	for {
+		query := fmt.Sprintf(`
+			select %s
+			from workouts
+			where user_id = $1
+			  and deleted_at is null
+			  and ($2::timestamptz is null or created_at >= $2)
+			  and ($3::timestamptz is null or created_at < $3)
+			  and ($4::timestamptz is null or (created_at, id) > ($4, $5))
+			order by created_at, id
+			limit $6
+		`, workoutColumns)
+
+		workouts := make([]Workout, 0, ExportChunkSize)
+
+		err := scanRows(s.db, query, []any{userID, from, to, lastCreatedAt, lastID, ExportChunkSize}, func(rows *sql.Rows) error {
+			workout := Workout{}
+
+			if err := scanWorkout(rows, &workout); err != nil {
+				return err
+			}
+
+			workouts = append(workouts, workout)
+
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if err := loadWorkoutEntries(s.db, workouts); err != nil {
+			return err
+		}
+
+		for i := range workouts {
+			if err := fn(&workouts[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(workouts) < ExportChunkSize {
+			return nil
+		}
+
+		lastCreatedAt = workouts[len(workouts)-1].CreatedAt
+		lastID = workouts[len(workouts)-1].ID
+	}
+}
+
+// loadWorkoutEntries fills the entries and sets of the given workouts with one
+// query for each.
+func loadWorkoutEntries(q queryer, workouts []Workout) error {
+	if len(workouts) == 0 {
+		return nil
+	}
+
+	workoutIDs := make([]int, len(workouts))
+	workoutsByID := make(map[int]*Workout, len(workouts))
+
+	for i := range workouts {
+		workoutIDs[i] = workouts[i].ID
+		workoutsByID[workouts[i].ID] = &workouts[i]
+		workouts[i].Entries = make([]WorkoutEntry, 0)
+	}
+
+	query := fmt.Sprintf(`
+		select workout_id, %s
+		from workout_entries
+		where workout_id = any($1)
+		order by workout_id, order_index
+	`, workoutEntryColumns)
+
+	entries := make([]WorkoutEntry, 0)
+	entryWorkoutIDs := make([]int, 0)
+
+	err := scanRows(q, query, []any{workoutIDs}, func(rows *sql.Rows) error {
+		var workoutID int
+		entry := WorkoutEntry{}
+
+		if err := scanWorkoutEntry(prefixedScanner{rows, &workoutID}, &entry); err != nil {
+			return err
+		}
+
+		entries = append(entries, entry)
+		entryWorkoutIDs = append(entryWorkoutIDs, workoutID)
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := loadWorkoutSets(q, entries); err != nil {
+		return err
+	}
+
+	for i, entry := range entries {
+		workout := workoutsByID[entryWorkoutIDs[i]]
+		workout.Entries = append(workout.Entries, entry)
+	}
+
+	return nil
+}
+
+// prefixedScanner scans a leading column into prefix before handing the rest
+// of the row to a scanX function.
+type prefixedScanner struct {
+	scanner interface{ Scan(dest ...any) error }
+	prefix  any
+}
+
+func (p prefixedScanner) Scan(dest ...any) error {
+	return p.scanner.Scan(append([]any{p.prefix}, dest...)...)
 }
 
 func (s *PostgresWorkoutStore) OwnsWorkout(id int, userID int) (bool, error) {
 	var owns bool
 
 	query := `
-		select exists(select 1 from workouts where id = $1 and user_id = $2)
+		select exists(select 1 from workouts where id = $1 and user_id = $2 and deleted_at is null)
 	`
 
 	err := s.db.QueryRow(query, id, userID).Scan(&owns)
 
 	return owns, err
 }
+
+// ConvertPoundsHistory reinterprets every weight the user logged before the
+// given time as pounds and stores it in kilograms. It exists for history
+// recorded before units did, so before is capped at the user's
+// units_enabled_at and imported workouts, whose weights were converted on import, are left alone.
+// It runs once per user; a second run returns ErrWeightsAlreadyConverted.
+// Returns how many workouts were converted.
+func (s *PostgresWorkoutStore) ConvertPoundsHistory(userID int, before time.Time) (int, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var unitsEnabledAt time.Time
+	err = tx.QueryRow(`
+		update users set weights_converted_at = now()
+		where id = $1 and weights_converted_at is null
+		returning units_enabled_at
+	`, userID).Scan(&unitsEnabledAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, internalErrors.ErrWeightsAlreadyConverted
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if before.After(unitsEnabledAt) {
+		before = unitsEnabledAt
+	}
+
+	queries := []string{
+		`update workout_sets set weight = round(workout_sets.weight / $3, 3)
+		 from workout_entries, workouts
+		 where workout_entries.id = workout_sets.workout_entry_id and workouts.id = workout_entries.workout_id
+		   and workouts.user_id = $1 and workouts.created_at < $2 and workouts.import_key is null`,
+		`update workout_entries set weight = round(workout_entries.weight / $3, 3)
+		 from workouts
+		 where workouts.id = workout_entries.workout_id and workouts.user_id = $1 and workouts.created_at < $2
+		   and workouts.import_key is null`,
+		`update workout_template_entries set target_weight = round(workout_template_entries.target_weight / $3, 3)
+		 from workout_templates
+		 where workout_templates.id = workout_template_entries.template_id
+		   and workout_templates.user_id = $1 and workout_templates.created_at < $2`,
+		`update goals set target = round(target / $3, 3)
+		 where user_id = $1 and goal_type = 'lift_weight' and created_at < $2`,
+		`update body_measurements set weight = round(weight / $3, 3)
+		 where user_id = $1 and weight is not null and measured_at < $2`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.Exec(query, userID, before, units.PoundsPerKilogram); err != nil {
+			return 0, err
+		}
+	}
+
+	var converted int
+	err = tx.QueryRow(
+		"select count(*) from workouts where user_id = $1 and created_at < $2 and import_key is null", userID, before,
+	).Scan(&converted)
+
+	if err != nil {
+		return 0, err
+	}
+
+	exerciseKeys, err := userExerciseKeys(tx, userID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	err = recomputePersonalRecords(tx, userID, exerciseKeys)
+
+	if err != nil {
+		return 0, err
+	}
+
+	err = refreshGoals(tx, userID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return converted, tx.Commit()
+}
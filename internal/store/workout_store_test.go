@@ -1,9 +1,13 @@
 package store
 
 import (
+	"encoding/json"
 	testingUtils "partiuFit/internal/database/testing_utils"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/fitness"
 	"partiuFit/internal/utils"
 	"testing"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/stretchr/testify/assert"
@@ -48,6 +52,8 @@ func TestWorkoutStore(t *testing.T) {
 		assert.Equal(t, 1, len(createdWorkout.Entries))
 		assert.Equal(t, "Bench Press", createdWorkout.Entries[0].ExerciseName)
 		assert.NotEmpty(t, createdWorkout.Entries)
+		assert.NotZero(t, createdWorkout.Entries[0].ID)
+		assert.NotNil(t, createdWorkout.Entries[0].ExerciseID, "entry should be linked to the catalog by name")
 	})
 
 	t.Run("Create with invalid", func(t *testing.T) {
@@ -168,12 +174,413 @@ func TestWorkoutStore(t *testing.T) {
 	})
 
 	t.Run("Get all", func(t *testing.T) {
-		workouts := utils.Must(workutStore.GetAllWorkouts(user.ID))
+		workouts, pagination, err := workutStore.GetAllWorkouts(user.ID, WorkoutFilters{})
 
+		assert.NoError(t, err)
 		assert.Len(t, workouts, 3)
+		assert.False(t, pagination.HasMore)
+		assert.Empty(t, pagination.NextCursor)
 
 		assert.Equal(t, "Test Workout 3", workouts[0].Title)
 		assert.Equal(t, "Updated Test Workout", workouts[1].Title)
 		assert.Equal(t, "Test Workout", workouts[2].Title)
 	})
+
+	t.Run("Get all paginated with cursor", func(t *testing.T) {
+		firstPage, pagination, err := workutStore.GetAllWorkouts(user.ID, WorkoutFilters{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Len(t, firstPage, 2)
+		assert.True(t, pagination.HasMore)
+		assert.NotEmpty(t, pagination.NextCursor)
+
+		secondPage, pagination, err := workutStore.GetAllWorkouts(user.ID, WorkoutFilters{Limit: 2, Cursor: pagination.NextCursor})
+
+		assert.NoError(t, err)
+		assert.Len(t, secondPage, 1)
+		assert.False(t, pagination.HasMore)
+		assert.Equal(t, "Test Workout", secondPage[0].Title)
+	})
+
+	t.Run("Get all filtered by title and sorted", func(t *testing.T) {
+		workouts, _, err := workutStore.GetAllWorkouts(user.ID, WorkoutFilters{Search: "updated", Sort: "title", Order: "asc"})
+
+		assert.NoError(t, err)
+		assert.Len(t, workouts, 1)
+		assert.Equal(t, "Updated Test Workout", workouts[0].Title)
+	})
+
+	t.Run("Get all with cursor from another sort", func(t *testing.T) {
+		_, pagination, _ := workutStore.GetAllWorkouts(user.ID, WorkoutFilters{Limit: 1})
+
+		_, _, err := workutStore.GetAllWorkouts(user.ID, WorkoutFilters{Limit: 1, Sort: "title", Cursor: pagination.NextCursor})
+
+		assert.ErrorIs(t, err, internalErrors.ErrInvalidCursor)
+	})
+
+	t.Run("Create with per-set logging", func(t *testing.T) {
+		workout := &Workout{
+			Title:           "Pyramid",
+			Description:     "Bench pyramid",
+			DurationMinutes: 45,
+			CaloriesBurned:  300,
+			UserID:          user.ID,
+			Entries: []WorkoutEntry{
+				{
+					ExerciseName: "Bench Press",
+					OrderIndex:   1,
+					UserID:       user.ID,
+					WorkoutSets: []WorkoutSet{
+						{SetType: SetTypeWarmUp, Reps: utils.ValueToPointer(12), Weight: 40, Completed: true},
+						{SetType: SetTypeWorking, Reps: utils.ValueToPointer(8), Weight: 70, RPE: utils.ValueToPointer(8.5), Completed: true},
+						{SetType: SetTypeFailure, Reps: utils.ValueToPointer(3), Weight: 80, RIR: utils.ValueToPointer(0), Completed: false},
+					},
+				},
+			},
+		}
+
+		createdWorkout, err := workutStore.CreateWorkout(workout)
+		assert.NoError(t, err)
+
+		retrievedWorkout, err := workutStore.GetWorkoutById(createdWorkout.ID)
+		assert.NoError(t, err)
+
+		entry := retrievedWorkout.Entries[0]
+		assert.Equal(t, 3, entry.Sets)
+		assert.Equal(t, 3, *entry.Reps)
+		assert.Equal(t, 80.0, entry.Weight)
+		assert.Len(t, entry.WorkoutSets, 3)
+		assert.Equal(t, SetTypeWarmUp, entry.WorkoutSets[0].SetType)
+		assert.Equal(t, 8.5, *entry.WorkoutSets[1].RPE)
+		assert.False(t, entry.WorkoutSets[2].Completed)
+	})
+
+	t.Run("Update replaces sets", func(t *testing.T) {
+		workouts, _, err := workutStore.GetAllWorkouts(user.ID, WorkoutFilters{Search: "Pyramid"})
+		assert.NoError(t, err)
+		workout := utils.Must(workutStore.GetWorkoutById(workouts[0].ID))
+
+		workout.Entries[0].WorkoutSets = workout.Entries[0].WorkoutSets[:1]
+
+		_, err = workutStore.UpdateWorkout(workout.ID, workout)
+		assert.NoError(t, err)
+
+		retrievedWorkout := utils.Must(workutStore.GetWorkoutById(workout.ID))
+		assert.Len(t, retrievedWorkout.Entries[0].WorkoutSets, 1)
+		assert.Equal(t, 1, retrievedWorkout.Entries[0].Sets)
+	})
+
+	t.Run("Update keeps entry and set ids", func(t *testing.T) {
+		workout := utils.Must(workutStore.CreateWorkout(&Workout{
+			Title:  "Stable ids",
+			UserID: user.ID,
+			Entries: []WorkoutEntry{
+				{ExerciseName: "Squat", Sets: 2, Reps: utils.ValueToPointer(5), Weight: 100, OrderIndex: 1, UserID: user.ID},
+				{ExerciseName: "Lunge", Sets: 1, Reps: utils.ValueToPointer(10), Weight: 20, OrderIndex: 2, UserID: user.ID},
+			},
+		}))
+		squat := workout.Entries[0]
+
+		workout.Entries[0].WorkoutSets[1].Weight = 105
+		workout.Entries = []WorkoutEntry{
+			workout.Entries[0],
+			{ExerciseName: "Leg Curl", Sets: 1, Reps: utils.ValueToPointer(12), Weight: 30, OrderIndex: 2, UserID: user.ID},
+		}
+
+		_, err := workutStore.UpdateWorkout(workout.ID, workout)
+		assert.NoError(t, err)
+
+		retrievedWorkout := utils.Must(workutStore.GetWorkoutById(workout.ID))
+		assert.Len(t, retrievedWorkout.Entries, 2)
+		assert.Equal(t, squat.ID, retrievedWorkout.Entries[0].ID)
+		assert.Equal(t, squat.WorkoutSets[1].ID, retrievedWorkout.Entries[0].WorkoutSets[1].ID)
+		assert.Equal(t, 105.0, retrievedWorkout.Entries[0].WorkoutSets[1].Weight)
+		assert.Equal(t, "Leg Curl", retrievedWorkout.Entries[1].ExerciseName)
+	})
+
+	t.Run("Entry groups round-trip", func(t *testing.T) {
+		superset := &EntryGroup{ID: 1, Type: GroupSuperset, Rounds: 3, RestSeconds: utils.ValueToPointer(90)}
+		workout := utils.Must(workutStore.CreateWorkout(&Workout{
+			Title:  "Arms",
+			UserID: user.ID,
+			Entries: []WorkoutEntry{
+				{ExerciseName: "Barbell Curl", Sets: 3, Reps: utils.ValueToPointer(10), Weight: 30, OrderIndex: 1, UserID: user.ID, Group: superset},
+				{ExerciseName: "Triceps Extension", Sets: 3, Reps: utils.ValueToPointer(12), Weight: 20, OrderIndex: 2, UserID: user.ID, Group: superset},
+				{ExerciseName: "Plank", Sets: 1, DurationSeconds: utils.ValueToPointer(60), OrderIndex: 3, UserID: user.ID},
+			},
+		}))
+
+		retrievedWorkout := utils.Must(workutStore.GetWorkoutById(workout.ID))
+		assert.Equal(t, superset, retrievedWorkout.Entries[0].Group)
+		assert.Equal(t, superset, retrievedWorkout.Entries[1].Group)
+		assert.Nil(t, retrievedWorkout.Entries[2].Group)
+
+		retrievedWorkout.Entries[1].Group = nil
+		retrievedWorkout.Entries[0].Group = nil
+		_, err := workutStore.UpdateWorkout(workout.ID, retrievedWorkout)
+		assert.NoError(t, err)
+		assert.Nil(t, utils.Must(workutStore.GetWorkoutById(workout.ID)).Entries[0].Group)
+	})
+
+	t.Run("Convert history logged in pounds", func(t *testing.T) {
+		deadlift := func(title string, createdAt time.Time) *Workout {
+			return utils.Must(workutStore.CreateWorkout(&Workout{
+				Title:     title,
+				UserID:    user.ID,
+				CreatedAt: &createdAt,
+				Entries: []WorkoutEntry{
+					{ExerciseName: "Deadlift", Sets: 1, Reps: utils.ValueToPointer(5), Weight: 135, OrderIndex: 1, UserID: user.ID},
+				},
+			}))
+		}
+
+		var unitsEnabledAt time.Time
+		assert.NoError(t, db.QueryRow("select units_enabled_at from users where id = $1", user.ID).Scan(&unitsEnabledAt))
+
+		legacy := deadlift("Logged in pounds", unitsEnabledAt.AddDate(0, -1, 0))
+		recent := deadlift("Logged in kilograms", unitsEnabledAt.Add(time.Minute))
+
+		converted, err := workutStore.ConvertPoundsHistory(user.ID, time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, converted)
+
+		retrievedWorkout := utils.Must(workutStore.GetWorkoutById(legacy.ID))
+		assert.Equal(t, 61.235, retrievedWorkout.Entries[0].Weight)
+		assert.Equal(t, 61.235, retrievedWorkout.Entries[0].WorkoutSets[0].Weight)
+		assert.Equal(t, 135.0, utils.Must(workutStore.GetWorkoutById(recent.ID)).Entries[0].Weight, "before is capped at units_enabled_at")
+
+		_, err = workutStore.ConvertPoundsHistory(user.ID, time.Now())
+		assert.ErrorIs(t, err, internalErrors.ErrWeightsAlreadyConverted)
+		assert.Equal(t, 61.235, utils.Must(workutStore.GetWorkoutById(legacy.ID)).Entries[0].Weight)
+	})
+
+	t.Run("Calories are estimated when omitted", func(t *testing.T) {
+		_, err := db.Exec("insert into body_measurements (user_id, weight) values ($1, 80)", user.ID)
+		assert.NoError(t, err)
+
+		workout := utils.Must(workutStore.CreateWorkout(&Workout{
+			Title:           "Long run",
+			DurationMinutes: 45,
+			UserID:          user.ID,
+			Entries: []WorkoutEntry{
+				{ExerciseName: "Running", Sets: 1, DurationSeconds: utils.ValueToPointer(1800), OrderIndex: 1, UserID: user.ID},
+			},
+		}))
+
+		// Running is 9.8 MET: 9.8 × 80 kg × 0.5 h
+		assert.Equal(t, 392, workout.CaloriesBurned)
+		assert.True(t, workout.CaloriesEstimated)
+		assert.Equal(t, fitness.CaloriesFormulaMET, *workout.CaloriesFormula)
+
+		workout.CaloriesBurned = 500
+		workout.CaloriesEstimated = false
+		workout.CaloriesFormula = nil
+		updatedWorkout := utils.Must(workutStore.UpdateWorkout(workout.ID, workout))
+		assert.Equal(t, 500, updatedWorkout.CaloriesBurned)
+		assert.False(t, updatedWorkout.CaloriesEstimated)
+
+		retrievedWorkout := utils.Must(workutStore.GetWorkoutById(workout.ID))
+		assert.Equal(t, 500, retrievedWorkout.CaloriesBurned)
+		assert.Nil(t, retrievedWorkout.CaloriesFormula)
+	})
+
+	t.Run("Export walks every workout oldest first", func(t *testing.T) {
+		exported := make([]*Workout, 0)
+
+		err := workutStore.ExportWorkouts(user.ID, nil, nil, func(workout *Workout) error {
+			exported = append(exported, workout)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		all, _, err := workutStore.GetAllWorkouts(user.ID, WorkoutFilters{Limit: MaxPageLimit})
+		assert.NoError(t, err)
+		assert.Len(t, exported, len(all))
+
+		for i := 1; i < len(exported); i++ {
+			assert.False(t, exported[i].CreatedAt.Before(*exported[i-1].CreatedAt))
+		}
+
+		for _, workout := range exported {
+			for _, entry := range workout.Entries {
+				assert.NotEmpty(t, entry.WorkoutSets)
+			}
+		}
+
+		future := time.Now().Add(time.Hour)
+		err = workutStore.ExportWorkouts(user.ID, &future, nil, func(workout *Workout) error {
+			t.Errorf("unexpected workout %d", workout.ID)
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Import is idempotent and dry runs write nothing", func(t *testing.T) {
+		startedAt := time.Date(2024, 3, 4, 21, 30, 0, 0, time.UTC)
+		imported := func() []Workout {
+			return []Workout{{
+				Title:           "Push",
+				DurationMinutes: 65,
+				CreatedAt:       &startedAt,
+				StartedAt:       &startedAt,
+				ImportKey:       utils.ValueToPointer("strong:2024-03-04 18:30:00:Push"),
+				Entries: []WorkoutEntry{{
+					ExerciseName: "Bench Press",
+					OrderIndex:   1,
+					UserID:       user.ID,
+					WorkoutSets:  []WorkoutSet{{SetType: SetTypeWorking, Reps: utils.ValueToPointer(8), Weight: 80, Completed: true}},
+				}},
+			}}
+		}
+
+		dryRun, err := workutStore.ImportWorkouts(user.ID, imported(), true)
+		assert.NoError(t, err)
+		assert.Equal(t, &ImportResult{Imported: 1}, dryRun)
+
+		result, err := workutStore.ImportWorkouts(user.ID, imported(), false)
+		assert.NoError(t, err)
+		assert.Equal(t, &ImportResult{Imported: 1}, result)
+
+		again, err := workutStore.ImportWorkouts(user.ID, imported(), false)
+		assert.NoError(t, err)
+		assert.Equal(t, &ImportResult{Duplicates: 1}, again)
+
+		workouts, _, err := workutStore.GetAllWorkouts(user.ID, WorkoutFilters{Search: "Push", From: &startedAt})
+		assert.NoError(t, err)
+		assert.Len(t, workouts, 1)
+		assert.True(t, workouts[0].CreatedAt.Equal(startedAt), "imported workouts keep their original date")
+	})
+	t.Run("Deleted workouts go to the trash until restored or purged", func(t *testing.T) {
+		workout := utils.Must(workutStore.CreateWorkout(&Workout{Title: "Fat-fingered", UserID: user.ID}))
+		utils.MustIfError(workutStore.DeleteWorkout(workout.ID))
+
+		owns, err := workutStore.OwnsWorkout(workout.ID, user.ID)
+		assert.NoError(t, err)
+		assert.False(t, owns)
+
+		trash, err := workutStore.GetTrash(user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, workout.ID, trash[0].ID)
+		assert.NotNil(t, trash[0].DeletedAt)
+
+		_, err = workutStore.RestoreWorkout(workout.ID, user.ID+1)
+		assert.ErrorIs(t, err, internalErrors.ErrNoRows)
+
+		restored, err := workutStore.RestoreWorkout(workout.ID, user.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+
+		utils.MustIfError(workutStore.DeleteWorkout(workout.ID))
+		purged, err := workutStore.PurgeDeletedWorkouts(time.Now().Add(time.Minute))
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, 1)
+
+		_, err = workutStore.RestoreWorkout(workout.ID, user.ID)
+		assert.ErrorIs(t, err, internalErrors.ErrNoRows)
+	})
+}
+
+func TestWorkoutEntrySyncSets(t *testing.T) {
+	t.Run("Legacy fields are expanded into sets", func(t *testing.T) {
+		entry := &WorkoutEntry{Sets: 3, Reps: utils.ValueToPointer(10), Weight: 50}
+
+		entry.syncSets()
+
+		assert.Len(t, entry.WorkoutSets, 3)
+		assert.Equal(t, SetTypeWorking, entry.WorkoutSets[2].SetType)
+		assert.Equal(t, 10, *entry.WorkoutSets[2].Reps)
+		assert.Equal(t, 50.0, entry.WorkoutSets[2].Weight)
+	})
+
+	t.Run("Legacy fields are derived from the top set", func(t *testing.T) {
+		entry := &WorkoutEntry{WorkoutSets: []WorkoutSet{
+			{Reps: utils.ValueToPointer(10), Weight: 60},
+			{Reps: utils.ValueToPointer(6), Weight: 80},
+			{Reps: utils.ValueToPointer(8), Weight: 80},
+			{Reps: utils.ValueToPointer(12), Weight: 50},
+		}}
+
+		entry.syncSets()
+
+		assert.Equal(t, 4, entry.Sets)
+		assert.Equal(t, 8, *entry.Reps)
+		assert.Equal(t, 80.0, entry.Weight)
+		assert.Nil(t, entry.DurationSeconds)
+	})
+
+	t.Run("Omitted completed flags follow the workout status", func(t *testing.T) {
+		entry := &WorkoutEntry{}
+		utils.MustIfError(json.Unmarshal([]byte(`{"workout_sets": [{"reps": 5}, {"reps": 5, "completed": true}]}`), entry))
+
+		entry.defaultCompleted(WorkoutPlanned)
+		assert.Equal(t, SetTypeWorking, entry.WorkoutSets[0].SetType)
+		assert.False(t, entry.WorkoutSets[0].Completed)
+		assert.True(t, entry.WorkoutSets[1].Completed)
+
+		entry.defaultCompleted(WorkoutCompleted)
+		assert.True(t, entry.WorkoutSets[0].Completed)
+
+		legacy := &WorkoutEntry{Sets: 2, Reps: utils.ValueToPointer(10)}
+		legacy.syncSets()
+		legacy.defaultCompleted(WorkoutInProgress)
+		assert.False(t, legacy.WorkoutSets[1].Completed)
+	})
+
+	t.Run("Timed sets keep their longest duration", func(t *testing.T) {
+		entry := &WorkoutEntry{WorkoutSets: []WorkoutSet{
+			{DurationSeconds: utils.ValueToPointer(60)},
+			{DurationSeconds: utils.ValueToPointer(45)},
+		}}
+
+		entry.syncSets()
+
+		assert.Equal(t, 2, entry.Sets)
+		assert.Nil(t, entry.Reps)
+		assert.Equal(t, 60, *entry.DurationSeconds)
+
+		entry.WorkoutSets = nil
+		entry.syncSets()
+		assert.Len(t, entry.WorkoutSets, 2)
+		assert.Equal(t, 60, *entry.WorkoutSets[1].DurationSeconds, "a round trip through the legacy fields keeps the duration per set")
+	})
+}
+
+func TestValidateEntryGroups(t *testing.T) {
+	grouped := func(orderIndex int, group EntryGroup) WorkoutEntry {
+		return WorkoutEntry{OrderIndex: orderIndex, Group: &group}
+	}
+	superset := EntryGroup{ID: 1, Type: GroupSuperset, Rounds: 3}
+	circuit := EntryGroup{ID: 2, Type: GroupCircuit, Rounds: 2, RestSeconds: utils.ValueToPointer(120)}
+
+	t.Run("Valid groups", func(t *testing.T) {
+		assert.NoError(t, ValidateEntryGroups([]WorkoutEntry{
+			grouped(2, superset),
+			grouped(1, superset),
+			{OrderIndex: 3},
+			grouped(4, circuit),
+			grouped(5, circuit),
+			grouped(6, circuit),
+		}))
+	})
+
+	t.Run("Members must be consecutive", func(t *testing.T) {
+		err := ValidateEntryGroups([]WorkoutEntry{grouped(1, superset), {OrderIndex: 2}, grouped(3, superset)})
+		assert.ErrorIs(t, err, internalErrors.ErrInvalidEntryGroup)
+	})
+
+	t.Run("Members must agree", func(t *testing.T) {
+		otherRounds := superset
+		otherRounds.Rounds = 4
+
+		err := ValidateEntryGroups([]WorkoutEntry{grouped(1, superset), grouped(2, otherRounds)})
+		assert.ErrorIs(t, err, internalErrors.ErrInvalidEntryGroup)
+	})
+
+	t.Run("Group sizes are enforced", func(t *testing.T) {
+		giantSet := EntryGroup{ID: 3, Type: GroupGiantSet, Rounds: 1}
+
+		assert.ErrorIs(t, ValidateEntryGroups([]WorkoutEntry{grouped(1, superset)}), internalErrors.ErrInvalidEntryGroup)
+		assert.ErrorIs(t, ValidateEntryGroups([]WorkoutEntry{grouped(1, superset), grouped(2, superset), grouped(3, superset)}), internalErrors.ErrInvalidEntryGroup)
+		assert.ErrorIs(t, ValidateEntryGroups([]WorkoutEntry{grouped(1, giantSet), grouped(2, giantSet)}), internalErrors.ErrInvalidEntryGroup)
+	})
 }
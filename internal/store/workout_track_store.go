@@ -0,0 +1,163 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/tracks"
+	"time"
+)
+
+// WorkoutTrack is the cardio part of a workout imported from a GPX or TCX
+// file. Distance is in kilometers, paces in seconds per kilometer and the
+// elevation gain in meters.
+type WorkoutTrack struct {
+	ID              int            `json:"id"`
+	WorkoutID       int            `json:"workout_id"`
+	Sport           string         `json:"sport"`
+	SourceFormat    string         `json:"source_format"`
+	Distance        float64        `json:"distance"`
+	DurationSeconds int            `json:"duration_seconds"`
+	AveragePace     *float64       `json:"average_pace"`
+	BestPace        *float64       `json:"best_pace"`
+	ElevationGain   float64        `json:"elevation_gain"`
+	Points          []tracks.Point `json:"points,omitempty"`
+	CreatedAt       *time.Time     `json:"created_at"`
+}
+
+// workoutTrackColumns leaves the points out, they are only loaded by GetTrack.
+const workoutTrackColumns = `id, workout_id, sport, source_format, distance_km, duration_seconds, average_pace, best_pace, elevation_gain, created_at`
+
+func scanWorkoutTrack(scanner interface{ Scan(dest ...any) error }, track *WorkoutTrack, extra ...any) error {
+	return scanner.Scan(append([]any{
+		&track.ID,
+		&track.WorkoutID,
+		&track.Sport,
+		&track.SourceFormat,
+		&track.Distance,
+		&track.DurationSeconds,
+		&track.AveragePace,
+		&track.BestPace,
+		&track.ElevationGain,
+		&track.CreatedAt,
+	}, extra...)...)
+}
+
+type WorkoutTrackStore interface {
+	CreateTrackWorkout(workout *Workout, track *WorkoutTrack) (*Workout, error)
+	GetTrack(workoutID int) (*WorkoutTrack, error)
+}
+
+type PostgresWorkoutTrackStore struct {
+	db *sql.DB
+}
+
+func NewPostgresWorkoutTrackStore(db *sql.DB) *PostgresWorkoutTrackStore {
+	return &PostgresWorkoutTrackStore{
+		db: db,
+	}
+}
+
+// CreateTrackWorkout writes the workout and its track together. Importing the
+// same file twice fails with ErrTrackAlreadyImported, through the import key
+// of the workout.
+func (s *PostgresWorkoutTrackStore) CreateTrackWorkout(workout *Workout, track *WorkoutTrack) (*Workout, error) {
+	tx, err := s.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	err = insertWorkout(tx, workout)
+
+	if errors.Is(err, internalErrors.ErrNoRows) {
+		return nil, internalErrors.ErrTrackAlreadyImported
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := json.Marshal(track.Points)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		insert into workout_tracks (
+			workout_id, sport, source_format, distance_km, duration_seconds, average_pace, best_pace, elevation_gain, points
+		)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		returning id, workout_id, created_at
+	`
+
+	err = tx.QueryRow(
+		query,
+		workout.ID,
+		track.Sport,
+		track.SourceFormat,
+		track.Distance,
+		track.DurationSeconds,
+		track.AveragePace,
+		track.BestPace,
+		track.ElevationGain,
+		string(points),
+	).Scan(&track.ID, &track.WorkoutID, &track.CreatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := refreshGoals(tx, workout.UserID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	workout.Track = track
+
+	return workout, nil
+}
+
+func (s *PostgresWorkoutTrackStore) GetTrack(workoutID int) (*WorkoutTrack, error) {
+	track := &WorkoutTrack{}
+	var points []byte
+	query := `select ` + workoutTrackColumns + `, points from workout_tracks where workout_id = $1`
+
+	if err := scanWorkoutTrack(s.db.QueryRow(query, workoutID), track, &points); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(points, &track.Points); err != nil {
+		return nil, err
+	}
+
+	return track, nil
+}
+
+// getTrackSummary returns the track of a workout without its points, or nil
+// for workouts that were not imported from a track file.
+func getTrackSummary(q dbtx, workoutID int) (*WorkoutTrack, error) {
+	track := &WorkoutTrack{}
+	query := `select ` + workoutTrackColumns + ` from workout_tracks where workout_id = $1`
+
+	err := scanWorkoutTrack(q.QueryRow(query, workoutID), track)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return track, nil
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	testingUtils "partiuFit/internal/database/testing_utils"
+	internalErrors "partiuFit/internal/errors"
+	"partiuFit/internal/tracks"
+	"partiuFit/internal/utils"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkoutTrackStore(t *testing.T) {
+	db := utils.Must(testingUtils.SetupTestDB())
+	utils.MustIfError(testingUtils.SeedDB(db))
+	user, _ := testingUtils.CreateToken(db, "johndoe")
+	defer func() { _ = testingUtils.TeardownTestDB(db) }()
+
+	workoutStore := NewPostgresWorkoutStore(db)
+	trackStore := NewPostgresWorkoutTrackStore(db)
+
+	startedAt := time.Date(2025, 10, 5, 9, 0, 0, 0, time.UTC)
+	newWorkout := func() *Workout {
+		return &Workout{
+			Title:           "Morning run",
+			UserID:          user.ID,
+			DurationMinutes: 10,
+			CreatedAt:       &startedAt,
+			StartedAt:       &startedAt,
+			ImportKey:       utils.ValueToPointer("gpx:2025-10-05T09:00:00Z"),
+			Entries: []WorkoutEntry{
+				{ExerciseName: "Running", Sets: 1, DurationSeconds: utils.ValueToPointer(600), OrderIndex: 1, UserID: user.ID},
+			},
+		}
+	}
+	newTrack := func() *WorkoutTrack {
+		return &WorkoutTrack{
+			Sport:           tracks.SportRunning,
+			SourceFormat:    tracks.FormatGPX,
+			Distance:        2.5,
+			DurationSeconds: 600,
+			AveragePace:     utils.ValueToPointer(240.0),
+			ElevationGain:   12.5,
+			Points: []tracks.Point{
+				{Latitude: utils.ValueToPointer(-23.5), Longitude: utils.ValueToPointer(-46.6), Time: &startedAt},
+			},
+		}
+	}
+
+	workout, err := trackStore.CreateTrackWorkout(newWorkout(), newTrack())
+	assert.NoError(t, err)
+	assert.Equal(t, workout.ID, workout.Track.WorkoutID)
+
+	t.Run("The workout comes with the track summary", func(t *testing.T) {
+		found, err := workoutStore.GetWorkoutById(workout.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2.5, found.Track.Distance)
+		assert.Equal(t, 240.0, *found.Track.AveragePace)
+		assert.Nil(t, found.Track.BestPace)
+		assert.Empty(t, found.Track.Points)
+	})
+
+	t.Run("Get track loads the points", func(t *testing.T) {
+		track, err := trackStore.GetTrack(workout.ID)
+		assert.NoError(t, err)
+		assert.Len(t, track.Points, 1)
+		assert.Equal(t, -23.5, *track.Points[0].Latitude)
+	})
+
+	t.Run("The same file is not imported twice", func(t *testing.T) {
+		_, err := trackStore.CreateTrackWorkout(newWorkout(), newTrack())
+		assert.ErrorIs(t, err, internalErrors.ErrTrackAlreadyImported)
+	})
+}
@@ -9,6 +9,7 @@ import (
 
 const (
 	ScopeAuthentication = "authentication"
+	ScopeWorkoutShare   = "workout_share"
 )
 
 type Token struct {
@@ -33,8 +34,14 @@ func GenerateToken(userID int, ttl time.Duration, scope string) (*Token, error)
 	}
 
 	token.Plaintext = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes)
-	hash := sha256.Sum256([]byte(token.Plaintext))
-	token.Hash = hash[:]
+	token.Hash = Hash(token.Plaintext)
 
 	return token, nil
 }
+
+// Hash is how tokens are stored and looked up, the plaintext is never saved.
+func Hash(plaintext string) []byte {
+	hash := sha256.Sum256([]byte(plaintext))
+
+	return hash[:]
+}
@@ -0,0 +1,210 @@
+package tracks
+
+import (
+	"math"
+	"time"
+)
+
+const earthRadiusMeters = 6371008.8
+
+// elevationNoise is the climb or drop that has to accumulate before it counts,
+// so GPS jitter on flat ground does not add up to a hill.
+const elevationNoise = 2.0
+
+const kilometer = 1000.0
+
+// Summary describes a track. Paces are in seconds per kilometer and are nil
+// when the track has no timestamps.
+type Summary struct {
+	StartedAt       *time.Time
+	DistanceMeters  float64
+	DurationSeconds int
+	AveragePace     *float64
+	BestPace        *float64
+	ElevationGain   float64
+}
+
+type Split struct {
+	Number          int      `json:"number"`
+	Distance        float64  `json:"distance"`
+	DurationSeconds int      `json:"duration_seconds"`
+	Pace            *float64 `json:"pace"`
+	ElevationGain   float64  `json:"elevation_gain"`
+}
+
+// Analyze computes the distance, elapsed time, average pace, elevation gain and
+// best pace of a track. The best pace is the one of the fastest full kilometer,
+// or the average pace for tracks shorter than that.
+func Analyze(points []Point) Summary {
+	summary := Summary{}
+	distances := cumulativeDistances(points)
+
+	if len(points) == 0 {
+		return summary
+	}
+
+	summary.DistanceMeters = distances[len(distances)-1]
+	summary.ElevationGain = elevationGain(points)
+
+	first, last := timeRange(points)
+
+	if first == nil {
+		return summary
+	}
+
+	summary.StartedAt = first
+	summary.DurationSeconds = int(last.Sub(*first).Seconds())
+	summary.AveragePace = pace(float64(summary.DurationSeconds), summary.DistanceMeters)
+	summary.BestPace = summary.AveragePace
+
+	for _, split := range Splits(points, kilometer) {
+		if split.Distance < 1 || split.Pace == nil {
+			continue
+		}
+
+		if summary.BestPace == nil || *split.Pace < *summary.BestPace {
+			summary.BestPace = split.Pace
+		}
+	}
+
+	return summary
+}
+
+// Splits cuts the track every splitMeters (1000 for kilometers, 1609.344 for
+// miles), the last split being whatever is left. The time at each boundary is
+// interpolated between the two points around it. Distances are in kilometers
+// and paces in seconds per kilometer, like the rest of the summary.
+func Splits(points []Point, splitMeters float64) []Split {
+	splits := make([]Split, 0)
+	distances := cumulativeDistances(points)
+
+	if len(points) < 2 || points[0].Time == nil || splitMeters <= 0 {
+		return splits
+	}
+
+	splitStartDistance := 0.0
+	splitStartTime := *points[0].Time
+	splitStartIndex := 0
+
+	closeSplit := func(endDistance float64, endTime time.Time, endIndex int) {
+		distance := endDistance - splitStartDistance
+		duration := endTime.Sub(splitStartTime).Seconds()
+
+		splits = append(splits, Split{
+			Number:          len(splits) + 1,
+			Distance:        math.Round(distance) / kilometer,
+			DurationSeconds: int(math.Round(duration)),
+			Pace:            pace(duration, distance),
+			ElevationGain:   elevationGain(points[splitStartIndex : endIndex+1]),
+		})
+
+		splitStartDistance = endDistance
+		splitStartTime = endTime
+		splitStartIndex = endIndex
+	}
+
+	for i := 1; i < len(points); i++ {
+		if points[i].Time == nil || points[i-1].Time == nil {
+			continue
+		}
+
+		for distances[i] >= splitStartDistance+splitMeters {
+			boundary := splitStartDistance + splitMeters
+			ratio := (boundary - distances[i-1]) / (distances[i] - distances[i-1])
+			elapsed := points[i].Time.Sub(*points[i-1].Time)
+			closeSplit(boundary, points[i-1].Time.Add(time.Duration(ratio*float64(elapsed))), i)
+		}
+	}
+
+	last := len(points) - 1
+
+	if distances[last]-splitStartDistance > 0 && points[last].Time != nil {
+		closeSplit(distances[last], *points[last].Time, last)
+	}
+
+	return splits
+}
+
+// cumulativeDistances prefers the distance measured by the device and falls
+// back to the great-circle distance between positions.
+func cumulativeDistances(points []Point) []float64 {
+	distances := make([]float64, len(points))
+
+	for i := 1; i < len(points); i++ {
+		previous, current := points[i-1], points[i]
+		distances[i] = distances[i-1]
+
+		switch {
+		case current.Distance != nil:
+			distances[i] = math.Max(*current.Distance, distances[i-1])
+		case current.hasPosition() && previous.hasPosition():
+			distances[i] += haversine(*previous.Latitude, *previous.Longitude, *current.Latitude, *current.Longitude)
+		}
+	}
+
+	return distances
+}
+
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a))
+}
+
+func elevationGain(points []Point) float64 {
+	gain := 0.0
+	var reference *float64
+
+	for _, point := range points {
+		if point.Elevation == nil {
+			continue
+		}
+
+		elevation := *point.Elevation
+
+		switch {
+		case reference == nil:
+			reference = &elevation
+		case elevation-*reference >= elevationNoise:
+			gain += elevation - *reference
+			reference = &elevation
+		case *reference-elevation >= elevationNoise:
+			reference = &elevation
+		}
+	}
+
+	return math.Round(gain*10) / 10
+}
+
+func timeRange(points []Point) (*time.Time, *time.Time) {
+	var first, last *time.Time
+
+	for _, point := range points {
+		if point.Time == nil {
+			continue
+		}
+
+		if first == nil {
+			first = point.Time
+		}
+
+		last = point.Time
+	}
+
+	return first, last
+}
+
+func pace(seconds float64, meters float64) *float64 {
+	if seconds <= 0 || meters <= 0 {
+		return nil
+	}
+
+	secondsPerKilometer := math.Round(seconds/(meters/kilometer)*10) / 10
+
+	return &secondsPerKilometer
+}
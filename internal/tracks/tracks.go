@@ -0,0 +1,218 @@
+package tracks
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	FormatGPX = "gpx"
+	FormatTCX = "tcx"
+)
+
+const (
+	SportRunning = "running"
+	SportCycling = "cycling"
+	SportWalking = "walking"
+	SportOther   = "other"
+)
+
+var ErrEmptyTrack = errors.New("o arquivo não tem pontos de trajeto")
+
+// Point is a recorded position. Distance is the cumulative distance in meters
+// measured by the device, which TCX files carry and treadmill runs only have.
+type Point struct {
+	Latitude  *float64   `json:"lat,omitempty"`
+	Longitude *float64   `json:"lon,omitempty"`
+	Elevation *float64   `json:"ele,omitempty"`
+	Distance  *float64   `json:"dist,omitempty"`
+	Time      *time.Time `json:"time,omitempty"`
+}
+
+func (p Point) hasPosition() bool {
+	return p.Latitude != nil && p.Longitude != nil
+}
+
+type Track struct {
+	Format string
+	Name   string
+	Sport  string
+	Points []Point
+}
+
+type gpxFile struct {
+	Metadata struct {
+		Name string `xml:"name"`
+	} `xml:"metadata"`
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []struct {
+				Latitude  float64    `xml:"lat,attr"`
+				Longitude float64    `xml:"lon,attr"`
+				Elevation *float64   `xml:"ele"`
+				Time      *time.Time `xml:"time"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type tcxFile struct {
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		Notes string `xml:"Notes"`
+		Laps  []struct {
+			Tracks []struct {
+				Points []struct {
+					Time     *time.Time `xml:"Time"`
+					Position *struct {
+						Latitude  float64 `xml:"LatitudeDegrees"`
+						Longitude float64 `xml:"LongitudeDegrees"`
+					} `xml:"Position"`
+					Altitude *float64 `xml:"AltitudeMeters"`
+					Distance *float64 `xml:"DistanceMeters"`
+				} `xml:"Trackpoint"`
+			} `xml:"Track"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+// Parse reads a GPX or a TCX file, told apart by their root element. Every
+// track, segment, activity and lap is joined into a single list of points.
+func Parse(data []byte) (*Track, error) {
+	root, err := rootElement(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var track *Track
+
+	switch root {
+	case "gpx":
+		track, err = parseGPX(data)
+	case "TrainingCenterDatabase":
+		track, err = parseTCX(data)
+	default:
+		return nil, fmt.Errorf("arquivo de trajeto não suportado: <%s>", root)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(track.Points) == 0 {
+		return nil, ErrEmptyTrack
+	}
+
+	return track, nil
+}
+
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return "", fmt.Errorf("arquivo de trajeto inválido: %w", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func parseGPX(data []byte) (*Track, error) {
+	file := &gpxFile{}
+
+	if err := xml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("arquivo gpx inválido: %w", err)
+	}
+
+	track := &Track{Format: FormatGPX, Name: file.Metadata.Name, Sport: SportOther, Points: make([]Point, 0)}
+
+	for _, gpxTrack := range file.Tracks {
+		if track.Name == "" {
+			track.Name = gpxTrack.Name
+		}
+
+		if gpxTrack.Type != "" {
+			track.Sport = NormalizeSport(gpxTrack.Type)
+		}
+
+		for _, segment := range gpxTrack.Segments {
+			for _, point := range segment.Points {
+				track.Points = append(track.Points, Point{
+					Latitude:  &point.Latitude,
+					Longitude: &point.Longitude,
+					Elevation: point.Elevation,
+					Time:      point.Time,
+				})
+			}
+		}
+	}
+
+	return track, nil
+}
+
+func parseTCX(data []byte) (*Track, error) {
+	file := &tcxFile{}
+
+	if err := xml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("arquivo tcx inválido: %w", err)
+	}
+
+	track := &Track{Format: FormatTCX, Sport: SportOther, Points: make([]Point, 0)}
+
+	for _, activity := range file.Activities {
+		track.Sport = NormalizeSport(activity.Sport)
+
+		if track.Name == "" {
+			track.Name = activity.Notes
+		}
+
+		for _, lap := range activity.Laps {
+			for _, tcxTrack := range lap.Tracks {
+				for _, trackpoint := range tcxTrack.Points {
+					point := Point{Elevation: trackpoint.Altitude, Distance: trackpoint.Distance, Time: trackpoint.Time}
+
+					if trackpoint.Position != nil {
+						point.Latitude = &trackpoint.Position.Latitude
+						point.Longitude = &trackpoint.Position.Longitude
+					}
+
+					// heart rate only samples carry neither a position nor a distance
+					if point.hasPosition() || point.Distance != nil {
+						track.Points = append(track.Points, point)
+					}
+				}
+			}
+		}
+	}
+
+	return track, nil
+}
+
+// NormalizeSport maps the activity types written by devices and apps
+// ("Running", "Biking", "ride", "hiking"...) to the sports we track.
+func NormalizeSport(sport string) string {
+	sport = strings.ToLower(sport)
+
+	switch {
+	case strings.Contains(sport, "run"):
+		return SportRunning
+	case strings.Contains(sport, "bik"), strings.Contains(sport, "cycl"), strings.Contains(sport, "ride"):
+		return SportCycling
+	case strings.Contains(sport, "walk"), strings.Contains(sport, "hik"):
+		return SportWalking
+	default:
+		return SportOther
+	}
+}
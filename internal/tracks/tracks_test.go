@@ -0,0 +1,115 @@
+package tracks
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readFixture(t *testing.T, name string) *Track {
+	data, err := os.ReadFile("testdata/" + name)
+	assert.NoError(t, err)
+
+	track, err := Parse(data)
+	assert.NoError(t, err)
+
+	return track
+}
+
+func TestParseGPX(t *testing.T) {
+	track := readFixture(t, "run.gpx")
+
+	assert.Equal(t, FormatGPX, track.Format)
+	assert.Equal(t, "Morning Run", track.Name)
+	assert.Equal(t, SportRunning, track.Sport)
+	assert.Len(t, track.Points, 26)
+	assert.Equal(t, 100.0, *track.Points[0].Elevation)
+	assert.Equal(t, time.Date(2025, 10, 12, 9, 0, 0, 0, time.UTC), *track.Points[0].Time)
+}
+
+func TestParseTCX(t *testing.T) {
+	track := readFixture(t, "treadmill.tcx")
+
+	assert.Equal(t, FormatTCX, track.Format)
+	assert.Equal(t, "Treadmill", track.Name)
+	assert.Equal(t, SportRunning, track.Sport)
+	assert.Len(t, track.Points, 5, "samples with heart rate only are skipped")
+	assert.Nil(t, track.Points[0].Latitude)
+	assert.Equal(t, 2000.0, *track.Points[4].Distance)
+}
+
+func TestParseInvalidFiles(t *testing.T) {
+	_, err := Parse([]byte("not xml"))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte(`<kml></kml>`))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte(`<gpx><trk><trkseg></trkseg></trk></gpx>`))
+	assert.ErrorIs(t, err, ErrEmptyTrack)
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Run("GPS track", func(t *testing.T) {
+		summary := Analyze(readFixture(t, "run.gpx").Points)
+
+		assert.InDelta(t, 2501.9, summary.DistanceMeters, 0.1)
+		assert.Equal(t, 660, summary.DurationSeconds)
+		assert.Equal(t, 263.8, *summary.AveragePace)
+		assert.Equal(t, 239.9, *summary.BestPace, "the second kilometer is the fastest")
+		assert.Equal(t, 20.0, summary.ElevationGain, "the 1 m jitter on the flat part is ignored")
+	})
+
+	t.Run("Device distance", func(t *testing.T) {
+		summary := Analyze(readFixture(t, "treadmill.tcx").Points)
+
+		assert.Equal(t, 2000.0, summary.DistanceMeters)
+		assert.Equal(t, 300.0, *summary.AveragePace)
+		assert.Zero(t, summary.ElevationGain)
+	})
+
+	t.Run("No timestamps", func(t *testing.T) {
+		points := readFixture(t, "run.gpx").Points
+
+		for i := range points {
+			points[i].Time = nil
+		}
+
+		summary := Analyze(points)
+		assert.InDelta(t, 2501.9, summary.DistanceMeters, 0.1)
+		assert.Nil(t, summary.AveragePace)
+		assert.Nil(t, summary.StartedAt)
+	})
+}
+
+func TestSplits(t *testing.T) {
+	points := readFixture(t, "run.gpx").Points
+
+	t.Run("Kilometers", func(t *testing.T) {
+		splits := Splits(points, 1000)
+
+		assert.Len(t, splits, 3)
+		assert.Equal(t, Split{Number: 1, Distance: 1, DurationSeconds: 300, Pace: splits[0].Pace, ElevationGain: 20}, splits[0])
+		assert.Equal(t, 299.8, *splits[0].Pace)
+		assert.Equal(t, 240, splits[1].DurationSeconds)
+		assert.Equal(t, 0.502, splits[2].Distance)
+	})
+
+	t.Run("Miles", func(t *testing.T) {
+		splits := Splits(points, 1609.344)
+
+		assert.Len(t, splits, 2)
+		assert.Equal(t, 1.609, splits[0].Distance)
+		assert.Equal(t, 446, splits[0].DurationSeconds)
+	})
+}
+
+func TestNormalizeSport(t *testing.T) {
+	assert.Equal(t, SportRunning, NormalizeSport("Running"))
+	assert.Equal(t, SportCycling, NormalizeSport("Biking"))
+	assert.Equal(t, SportCycling, NormalizeSport("ride"))
+	assert.Equal(t, SportWalking, NormalizeSport("hiking"))
+	assert.Equal(t, SportOther, NormalizeSport("9"))
+}
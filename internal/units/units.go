@@ -0,0 +1,82 @@
+package units
+
+import "math"
+
+const (
+	Metric   = "metric"
+	Imperial = "imperial"
+)
+
+const (
+	PoundsPerKilogram = 2.20462262185
+	MilesPerKilometer = 0.621371192237
+)
+
+// ToKilograms converts a weight sent in the given system to kilograms, the
+// unit every weight is stored in. Incoming values keep the storage precision
+// (3 decimals) and outgoing ones are rounded to 2, so 135 lb round-trips as 135.
+func ToKilograms(weight float64, system string) float64 {
+	if system != Imperial {
+		return weight
+	}
+
+	return round(weight/PoundsPerKilogram, 3)
+}
+
+func FromKilograms(weight float64, system string) float64 {
+	if system != Imperial {
+		return weight
+	}
+
+	return round(weight*PoundsPerKilogram, 2)
+}
+
+// ToKilometers converts a distance sent in the given system to kilometers.
+func ToKilometers(distance float64, system string) float64 {
+	if system != Imperial {
+		return distance
+	}
+
+	return round(distance/MilesPerKilometer, 3)
+}
+
+func FromKilometers(distance float64, system string) float64 {
+	if system != Imperial {
+		return distance
+	}
+
+	return round(distance*MilesPerKilometer, 2)
+}
+
+// PaceFromSecondsPerKilometer converts a pace to seconds per mile for the
+// imperial system.
+func PaceFromSecondsPerKilometer(pace float64, system string) float64 {
+	if system != Imperial {
+		return pace
+	}
+
+	return round(pace/MilesPerKilometer, 1)
+}
+
+// WeightUnit and DistanceUnit name the units of a system, as shown to clients.
+func WeightUnit(system string) string {
+	if system == Imperial {
+		return "lb"
+	}
+
+	return "kg"
+}
+
+func DistanceUnit(system string) string {
+	if system == Imperial {
+		return "mi"
+	}
+
+	return "km"
+}
+
+func round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,41 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeights(t *testing.T) {
+	t.Run("Metric is the storage unit", func(t *testing.T) {
+		assert.Equal(t, 102.5, ToKilograms(102.5, Metric))
+		assert.Equal(t, 102.5, FromKilograms(102.5, Metric))
+		assert.Equal(t, 102.5, FromKilograms(102.5, ""), "unknown systems fall back to metric")
+	})
+
+	t.Run("Pounds round-trip", func(t *testing.T) {
+		for _, pounds := range []float64{45, 135, 225, 315, 2.5} {
+			assert.Equal(t, pounds, FromKilograms(ToKilograms(pounds, Imperial), Imperial))
+		}
+
+		assert.Equal(t, 61.235, ToKilograms(135, Imperial))
+	})
+}
+
+func TestDistances(t *testing.T) {
+	assert.Equal(t, 10.0, ToKilometers(10, Metric))
+	assert.Equal(t, 5.0, FromKilometers(ToKilometers(5, Imperial), Imperial))
+	assert.Equal(t, 6.21, FromKilometers(10, Imperial))
+}
+
+func TestPaces(t *testing.T) {
+	assert.Equal(t, 300.0, PaceFromSecondsPerKilometer(300, Metric))
+	assert.Equal(t, 482.8, PaceFromSecondsPerKilometer(300, Imperial))
+}
+
+func TestUnitNames(t *testing.T) {
+	assert.Equal(t, "kg", WeightUnit(Metric))
+	assert.Equal(t, "lb", WeightUnit(Imperial))
+	assert.Equal(t, "km", DistanceUnit(Metric))
+	assert.Equal(t, "mi", DistanceUnit(Imperial))
+}
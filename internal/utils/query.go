@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	internalErrors "partiuFit/internal/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+func ReadIntQueryParam(r *http.Request, key string, defaultValue int) (int, error) {
+	value := r.URL.Query().Get(key)
+
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	intValue, err := strconv.Atoi(value)
+
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", internalErrors.ErrInvalidQueryParam, key)
+	}
+
+	return intValue, nil
+}
+
+func ReadBoolQueryParam(r *http.Request, key string) (bool, error) {
+	value := r.URL.Query().Get(key)
+
+	if value == "" {
+		return false, nil
+	}
+
+	boolValue, err := strconv.ParseBool(value)
+
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", internalErrors.ErrInvalidQueryParam, key)
+	}
+
+	return boolValue, nil
+}
+
+// ReadListQueryParam reads a comma separated parameter, such as tags=push,legs,
+// as well as the parameter repeated (tags=push&tags=legs).
+func ReadListQueryParam(r *http.Request, key string) []string {
+	values := make([]string, 0)
+
+	for _, value := range r.URL.Query()[key] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+
+	return values
+}
+
+// ReadTimeQueryParam accepts either a full RFC 3339 timestamp or a plain date (YYYY-MM-DD).
+func ReadTimeQueryParam(r *http.Request, key string) (*time.Time, error) {
+	value := r.URL.Query().Get(key)
+
+	if value == "" {
+		return nil, nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed, nil
+	}
+
+	parsed, err := time.Parse(dateLayout, value)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", internalErrors.ErrInvalidQueryParam, key)
+	}
+
+	return &parsed, nil
+}
+
+// ReadTimeRangeQueryParams reads the "from" and "to" query params. A plain
+// date in "to" covers the whole day, so the returned upper bound is exclusive.
+func ReadTimeRangeQueryParams(r *http.Request) (*time.Time, *time.Time, error) {
+	from, err := ReadTimeQueryParam(r, "from")
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	to, err := ReadTimeQueryParam(r, "to")
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if to != nil {
+		if _, err := time.Parse(dateLayout, r.URL.Query().Get("to")); err == nil {
+			to = ValueToPointer(to.AddDate(0, 0, 1))
+		}
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, fmt.Errorf("%w: from must be before to", internalErrors.ErrInvalidQueryParam)
+	}
+
+	return from, to, nil
+}
+
+// SetNextLinkHeader writes a RFC 8288 Link header pointing to the next page of the current request.
+func SetNextLinkHeader(w http.ResponseWriter, r *http.Request, cursor string) {
+	if cursor == "" {
+		return
+	}
+
+	next := url.URL{Path: r.URL.Path}
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+	next.RawQuery = query.Encode()
+
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+}
@@ -3,8 +3,10 @@ package utils
 import (
 	"encoding/json"
 	"net/http"
+	"os"
 	internalErrors "partiuFit/internal/errors"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -39,7 +41,12 @@ func MustReadJSON(w http.ResponseWriter, r *http.Request, data interface{}) {
 }
 
 func ReadIDParam(r *http.Request) (int, error) {
-	idParam := chi.URLParam(r, "id")
+	return ReadIntParam(r, "id")
+}
+
+// ReadIntParam reads a numeric URL param other than {id}, such as {entryID}.
+func ReadIntParam(r *http.Request, key string) (int, error) {
+	idParam := chi.URLParam(r, key)
 	if idParam == "" {
 		return 0, internalErrors.ErrInvalidIDParam
 	}
@@ -72,3 +79,15 @@ func StringToInt(value interface{}) int {
 	intValue := Must(strconv.Atoi(value.(string)))
 	return intValue
 }
+
+// ReadDurationEnv parses an env var such as "4h" or "30m", falling back to
+// defaultValue when it is not set.
+func ReadDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+
+	if value == "" {
+		return defaultValue
+	}
+
+	return Must(time.ParseDuration(value))
+}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"partiuFit/internal/rrule"
+
 	"github.com/go-playground/locales/pt_BR"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
@@ -14,9 +16,28 @@ var Trans, _ = uni.GetTranslator("pt_BR")
 func MakeValidator() *validator.Validate {
 	Validate := validator.New()
 	MustIfError(ptTranslations.RegisterDefaultTranslations(Validate, Trans))
+	registerCustomValidations(Validate)
 	return Validate
 }
 
+func registerCustomValidations(validate *validator.Validate) {
+	MustIfError(validate.RegisterValidation("rrule", func(fl validator.FieldLevel) bool {
+		_, err := rrule.Parse(fl.Field().String())
+		return err == nil
+	}))
+	registerTranslation(validate, "rrule", "{0} deve ser uma regra de recorrência RFC 5545 válida")
+	registerTranslation(validate, "timezone", "{0} deve ser um fuso horário IANA válido, como America/Sao_Paulo")
+}
+
+func registerTranslation(validate *validator.Validate, tag string, message string) {
+	MustIfError(validate.RegisterTranslation(tag, Trans, func(ut ut.Translator) error {
+		return ut.Add(tag, message, true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		translated, _ := ut.T(tag, fe.Field())
+		return translated
+	}))
+}
+
 var Validation = MakeValidator()
 
 func MustValidateStruct(data interface{}) {
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+create index if not exists workouts_user_id_created_at_idx on workouts (user_id, created_at desc, id desc);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists workouts_user_id_created_at_idx;
+-- +goose StatementEnd
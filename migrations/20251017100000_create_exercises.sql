@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists exercises (
+    id serial primary key,
+    user_id integer references users(id) on delete cascade,
+    name varchar(255) not null,
+    aliases text[] not null default '{}',
+    primary_muscle_groups text[] not null default '{}',
+    secondary_muscle_groups text[] not null default '{}',
+    equipment varchar(50) not null,
+    movement_type varchar(50) not null,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now(),
+
+    constraint valid_movement_type check (movement_type in ('compound', 'isolation', 'cardio', 'mobility'))
+);
+
+-- global catalog entries have no owner, custom ones belong to a user
+create unique index if not exists exercises_global_name_idx on exercises (lower(name)) where user_id is null;
+create unique index if not exists exercises_user_name_idx on exercises (user_id, lower(name)) where user_id is not null;
+
+insert into exercises (name, aliases, primary_muscle_groups, secondary_muscle_groups, equipment, movement_type) values
+    ('Bench Press', '{"Barbell Bench Press","Flat Bench Press","Supino reto","Supino"}', '{chest}', '{triceps,shoulders}', 'barbell', 'compound'),
+    ('Incline Bench Press', '{"Incline Barbell Bench Press","Supino inclinado"}', '{chest}', '{triceps,shoulders}', 'barbell', 'compound'),
+    ('Dumbbell Bench Press', '{"Supino reto com halteres"}', '{chest}', '{triceps,shoulders}', 'dumbbell', 'compound'),
+    ('Dumbbell Fly', '{"Chest Fly","Crucifixo"}', '{chest}', '{shoulders}', 'dumbbell', 'isolation'),
+    ('Push Up', '{"Push-up","Pushup","Flexão","Flexão de braço"}', '{chest}', '{triceps,shoulders}', 'bodyweight', 'compound'),
+    ('Squat', '{"Back Squat","Barbell Squat","Agachamento","Agachamento livre"}', '{quadriceps,glutes}', '{hamstrings,lower_back}', 'barbell', 'compound'),
+    ('Front Squat', '{"Agachamento frontal"}', '{quadriceps}', '{glutes,abs}', 'barbell', 'compound'),
+    ('Leg Press', '{"Leg press 45"}', '{quadriceps}', '{glutes,hamstrings}', 'machine', 'compound'),
+    ('Leg Extension', '{"Cadeira extensora","Extensora"}', '{quadriceps}', '{}', 'machine', 'isolation'),
+    ('Leg Curl', '{"Lying Leg Curl","Mesa flexora","Flexora"}', '{hamstrings}', '{}', 'machine', 'isolation'),
+    ('Deadlift', '{"Barbell Deadlift","Conventional Deadlift","Levantamento terra","Terra"}', '{hamstrings,glutes,lower_back}', '{back,forearms}', 'barbell', 'compound'),
+    ('Romanian Deadlift', '{"RDL","Stiff","Levantamento terra romeno"}', '{hamstrings}', '{glutes,lower_back}', 'barbell', 'compound'),
+    ('Hip Thrust', '{"Barbell Hip Thrust","Elevação pélvica"}', '{glutes}', '{hamstrings}', 'barbell', 'compound'),
+    ('Lunge', '{"Lunges","Afundo","Passada"}', '{quadriceps,glutes}', '{hamstrings}', 'dumbbell', 'compound'),
+    ('Standing Calf Raise', '{"Calf Raise","Panturrilha em pé","Elevação de panturrilha"}', '{calves}', '{}', 'machine', 'isolation'),
+    ('Overhead Press', '{"Military Press","Shoulder Press","Desenvolvimento","Desenvolvimento militar"}', '{shoulders}', '{triceps}', 'barbell', 'compound'),
+    ('Lateral Raise', '{"Dumbbell Lateral Raise","Elevação lateral"}', '{shoulders}', '{}', 'dumbbell', 'isolation'),
+    ('Pull Up', '{"Pull-up","Pullup","Chin Up","Barra fixa"}', '{back}', '{biceps}', 'bodyweight', 'compound'),
+    ('Lat Pulldown', '{"Pulldown","Puxada","Puxada frontal","Puxada alta"}', '{back}', '{biceps}', 'cable', 'compound'),
+    ('Barbell Row', '{"Bent Over Row","Remada curvada"}', '{back}', '{biceps,lower_back}', 'barbell', 'compound'),
+    ('Seated Cable Row', '{"Cable Row","Remada baixa","Remada sentada"}', '{back}', '{biceps}', 'cable', 'compound'),
+    ('Dumbbell Row', '{"One Arm Dumbbell Row","Remada unilateral","Serrote"}', '{back}', '{biceps}', 'dumbbell', 'compound'),
+    ('Barbell Curl', '{"Biceps Curl","Rosca direta"}', '{biceps}', '{forearms}', 'barbell', 'isolation'),
+    ('Dumbbell Curl', '{"Rosca alternada","Rosca com halteres"}', '{biceps}', '{forearms}', 'dumbbell', 'isolation'),
+    ('Hammer Curl', '{"Rosca martelo"}', '{biceps,forearms}', '{}', 'dumbbell', 'isolation'),
+    ('Triceps Pushdown', '{"Cable Pushdown","Tríceps pulley","Tríceps corda"}', '{triceps}', '{}', 'cable', 'isolation'),
+    ('Skull Crusher', '{"Lying Triceps Extension","Tríceps testa"}', '{triceps}', '{}', 'barbell', 'isolation'),
+    ('Overhead Triceps Extension', '{"Tríceps francês"}', '{triceps}', '{}', 'dumbbell', 'isolation'),
+    ('Dips', '{"Dip","Paralelas","Mergulho"}', '{triceps,chest}', '{shoulders}', 'bodyweight', 'compound'),
+    ('Plank', '{"Prancha"}', '{abs}', '{lower_back}', 'bodyweight', 'isolation'),
+    ('Crunch', '{"Abdominal","Abdominal supra"}', '{abs}', '{}', 'bodyweight', 'isolation'),
+    ('Running', '{"Run","Corrida","Treadmill","Esteira"}', '{cardio}', '{quadriceps,calves}', 'none', 'cardio'),
+    ('Cycling', '{"Bike","Ciclismo","Bicicleta","Bicicleta ergométrica"}', '{cardio}', '{quadriceps}', 'none', 'cardio'),
+    ('Rowing Machine', '{"Rowing","Remo","Remo ergômetro"}', '{cardio}', '{back}', 'machine', 'cardio'),
+    ('Jump Rope', '{"Pular corda"}', '{cardio}', '{calves}', 'none', 'cardio'),
+    ('Stretching', '{"Alongamento"}', '{full_body}', '{}', 'none', 'mobility');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists exercises;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table workout_entries add column exercise_id integer references exercises(id) on delete set null;
+create index if not exists workout_entries_exercise_id_idx on workout_entries (exercise_id);
+
+-- backfill existing entries by matching the free-text name against the catalog names and aliases
+update workout_entries
+set exercise_id = exercises.id
+from exercises
+where exercises.user_id is null
+  and (
+      lower(exercises.name) = lower(trim(workout_entries.exercise_name))
+      or exists (select 1 from unnest(exercises.aliases) alias where lower(alias) = lower(trim(workout_entries.exercise_name)))
+  );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists workout_entries_exercise_id_idx;
+alter table workout_entries drop column if exists exercise_id;
+-- +goose StatementEnd
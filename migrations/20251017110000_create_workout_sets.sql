@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists workout_sets (
+    id serial primary key,
+    workout_entry_id integer not null references workout_entries(id) on delete cascade,
+    set_number integer not null,
+    set_type varchar(20) not null default 'working',
+    reps integer,
+    weight decimal(6, 2) not null default 0,
+    duration_seconds integer,
+    rpe decimal(3, 1),
+    rir integer,
+    completed boolean not null default true,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now(),
+
+    constraint valid_workout_set check (reps is not null or duration_seconds is not null),
+    constraint valid_set_type check (set_type in ('warm_up', 'working', 'drop', 'failure')),
+    constraint valid_rpe check (rpe is null or (rpe >= 1 and rpe <= 10)),
+    constraint valid_rir check (rir is null or rir >= 0),
+    constraint unique_set_number unique (workout_entry_id, set_number)
+);
+
+-- existing entries become N identical working sets
+insert into workout_sets (workout_entry_id, set_number, set_type, reps, weight, duration_seconds)
+select workout_entries.id, set_number, 'working', workout_entries.reps, workout_entries.weight, workout_entries.duration_seconds
+from workout_entries
+cross join lateral generate_series(1, greatest(workout_entries.sets, 1)) as set_number;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists workout_sets;
+-- +goose StatementEnd
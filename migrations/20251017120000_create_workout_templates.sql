@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists workout_templates (
+    id serial primary key,
+    user_id integer not null references users(id) on delete cascade,
+    title varchar(255) not null,
+    description text not null default '',
+    estimated_duration_minutes integer,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now()
+);
+
+create table if not exists workout_template_entries (
+    id serial primary key,
+    template_id integer not null references workout_templates(id) on delete cascade,
+    exercise_id integer references exercises(id) on delete set null,
+    exercise_name varchar(255) not null,
+    target_sets integer not null,
+    target_reps integer,
+    target_duration_seconds integer,
+    target_weight decimal(6, 2) not null default 0,
+    rest_seconds integer,
+    notes text not null default '',
+    order_index integer not null,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now(),
+
+    constraint valid_template_entry check (target_reps is not null or target_duration_seconds is not null)
+);
+
+create index if not exists workout_templates_user_id_idx on workout_templates (user_id);
+
+-- workouts remember the template they were started from, but copy its values
+alter table workouts add column template_id integer references workout_templates(id) on delete set null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table workouts drop column if exists template_id;
+drop table if exists workout_template_entries;
+drop table if exists workout_templates;
+-- +goose StatementEnd
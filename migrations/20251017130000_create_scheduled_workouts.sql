@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists scheduled_workouts (
+    id serial primary key,
+    user_id integer not null references users(id) on delete cascade,
+    template_id integer references workout_templates(id) on delete set null,
+    title varchar(255) not null,
+    notes text not null default '',
+    starts_on date not null,
+    rrule text,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now()
+);
+
+create index if not exists scheduled_workouts_user_id_idx on scheduled_workouts (user_id);
+
+create table if not exists scheduled_workout_completions (
+    id serial primary key,
+    scheduled_workout_id integer not null references scheduled_workouts(id) on delete cascade,
+    occurrence_date date not null,
+    workout_id integer references workouts(id) on delete cascade,
+    created_at timestamp with time zone not null default now(),
+
+    constraint unique_scheduled_occurrence unique (scheduled_workout_id, occurrence_date)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists scheduled_workout_completions;
+drop table if exists scheduled_workouts;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists personal_records (
+    id serial primary key,
+    user_id integer not null references users(id) on delete cascade,
+    exercise_key varchar(300) not null,
+    exercise_id integer references exercises(id) on delete set null,
+    exercise_name varchar(255) not null,
+    record_type varchar(50) not null,
+    value decimal(10, 2) not null,
+    weight decimal(6, 2),
+    reps integer,
+    workout_id integer not null references workouts(id) on delete cascade,
+    workout_entry_id integer not null references workout_entries(id) on delete cascade,
+    workout_set_id integer references workout_sets(id) on delete cascade,
+    achieved_at timestamp with time zone not null,
+    created_at timestamp with time zone not null default now(),
+
+    constraint valid_record_type check (record_type in ('heaviest_weight', 'max_reps', 'estimated_1rm', 'best_volume'))
+);
+
+create index if not exists personal_records_user_exercise_idx on personal_records (user_id, exercise_key, record_type);
+create index if not exists personal_records_workout_id_idx on personal_records (workout_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists personal_records;
+-- +goose StatementEnd
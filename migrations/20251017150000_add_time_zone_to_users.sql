@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table users add column if not exists time_zone varchar(64) not null default 'UTC';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table users drop column if exists time_zone;
+-- +goose StatementEnd
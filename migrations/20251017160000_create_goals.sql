@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists goals (
+    id serial primary key,
+    user_id integer not null references users(id) on delete cascade,
+    title varchar(255) not null,
+    goal_type varchar(50) not null,
+    target decimal(10, 2) not null,
+    period varchar(20),
+    exercise_id integer references exercises(id) on delete cascade,
+    deadline date,
+    status varchar(20) not null default 'active',
+    achieved_at timestamp with time zone,
+    evaluated_at timestamp with time zone,
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now(),
+
+    constraint valid_goal_type check (goal_type in ('workouts', 'calories_burned', 'duration_minutes', 'lift_weight')),
+    constraint valid_goal_period check (period in ('week', 'month')),
+    constraint valid_goal_status check (status in ('active', 'achieved', 'failed')),
+    constraint positive_goal_target check (target > 0),
+    constraint periodic_goal_has_period check ((goal_type = 'lift_weight') = (period is null)),
+    constraint lift_goal_has_exercise check ((goal_type = 'lift_weight') = (exercise_id is not null))
+);
+
+create index if not exists goals_user_id_idx on goals (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists goals;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+create table if not exists body_measurements (
+    id serial primary key,
+    user_id integer not null references users(id) on delete cascade,
+    measured_at timestamp with time zone not null default now(),
+    weight decimal(5, 2),
+    body_fat_percentage decimal(4, 1),
+    waist decimal(5, 1),
+    chest decimal(5, 1),
+    hips decimal(5, 1),
+    neck decimal(5, 1),
+    arm decimal(5, 1),
+    forearm decimal(5, 1),
+    thigh decimal(5, 1),
+    calf decimal(5, 1),
+    notes text not null default '',
+    created_at timestamp with time zone not null default now(),
+    updated_at timestamp with time zone not null default now(),
+
+    constraint has_measurement check (
+        num_nonnulls(weight, body_fat_percentage, waist, chest, hips, neck, arm, forearm, thigh, calf) > 0
+    )
+);
+
+create index if not exists body_measurements_user_measured_at_idx on body_measurements (user_id, measured_at desc);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists body_measurements;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+alter table users add column if not exists unit_system varchar(20) not null default 'metric';
+alter table users add constraint valid_unit_system check (unit_system in ('metric', 'imperial'));
+-- weights logged from here on are in kilograms; existing accounts get the time this migration ran
+alter table users add column if not exists units_enabled_at timestamp with time zone not null default now();
+
+-- every weight is stored in kilograms; the extra decimal keeps pounds exact on a round trip
+alter table workout_entries alter column weight type decimal(8, 3);
+alter table workout_sets alter column weight type decimal(8, 3);
+alter table workout_template_entries alter column target_weight type decimal(8, 3);
+alter table personal_records alter column weight type decimal(8, 3);
+alter table personal_records alter column value type decimal(12, 3);
+alter table goals alter column target type decimal(12, 3);
+alter table body_measurements alter column weight type decimal(6, 3);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table body_measurements alter column weight type decimal(5, 2);
+alter table goals alter column target type decimal(10, 2);
+alter table personal_records alter column value type decimal(10, 2);
+alter table personal_records alter column weight type decimal(6, 2);
+alter table workout_template_entries alter column target_weight type decimal(6, 2);
+alter table workout_sets alter column weight type decimal(6, 2);
+alter table workout_entries alter column weight type decimal(5, 2);
+alter table users drop column if exists units_enabled_at;
+alter table users drop constraint if exists valid_unit_system;
+alter table users drop column if exists unit_system;
+-- +goose StatementEnd
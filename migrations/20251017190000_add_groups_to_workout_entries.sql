@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- group_id only identifies the group inside its workout, the other columns are repeated on every member
+alter table workout_entries
+    add column if not exists group_id integer,
+    add column if not exists group_type varchar(20),
+    add column if not exists group_rounds integer,
+    add column if not exists group_rest_seconds integer;
+
+alter table workout_entries add constraint valid_entry_group check (
+    (group_id is null and group_type is null and group_rounds is null and group_rest_seconds is null) or
+    (
+        group_id > 0 and
+        group_type in ('superset', 'circuit', 'giant_set') and
+        group_rounds > 0 and
+        (group_rest_seconds is null or group_rest_seconds >= 0)
+    )
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table workout_entries drop constraint if exists valid_entry_group;
+alter table workout_entries
+    drop column if exists group_rest_seconds,
+    drop column if exists group_rounds,
+    drop column if exists group_type,
+    drop column if exists group_id;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- workouts logged after the fact are completed right away; live ones go planned -> in_progress <-> paused -> completed
+alter table workouts
+    add column if not exists status varchar(20) not null default 'completed',
+    add column if not exists started_at timestamp with time zone,
+    add column if not exists finished_at timestamp with time zone,
+    add column if not exists last_activity_at timestamp with time zone;
+
+alter table workouts add constraint valid_workout_status check (status in ('planned', 'in_progress', 'paused', 'completed'));
+
+create unique index if not exists workouts_one_active_session_idx on workouts (user_id) where status in ('in_progress', 'paused');
+
+create table if not exists workout_session_events (
+    id serial primary key,
+    workout_id integer not null references workouts(id) on delete cascade,
+    event_type varchar(20) not null,
+    occurred_at timestamp with time zone not null default now(),
+
+    constraint valid_session_event_type check (event_type in ('start', 'pause', 'resume', 'finish', 'auto_finish'))
+);
+
+create index if not exists workout_session_events_workout_id_idx on workout_session_events (workout_id, occurred_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists workout_session_events;
+drop index if exists workouts_one_active_session_idx;
+alter table workouts drop constraint if exists valid_workout_status;
+alter table workouts
+    drop column if exists last_activity_at,
+    drop column if exists finished_at,
+    drop column if exists started_at,
+    drop column if exists status;
+-- +goose StatementEnd
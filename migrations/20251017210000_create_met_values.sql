@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+-- MET values used to estimate calories: per exercise, per movement type, and
+-- one default row (both null) for entries that are not in the catalog
+create table if not exists met_values (
+    id serial primary key,
+    exercise_id integer references exercises(id) on delete cascade,
+    movement_type varchar(50),
+    met decimal(4, 1) not null,
+
+    constraint valid_met check (met > 0),
+    constraint single_met_target check (num_nonnulls(exercise_id, movement_type) <= 1)
+);
+
+create unique index if not exists met_values_exercise_idx on met_values (exercise_id) where exercise_id is not null;
+create unique index if not exists met_values_movement_type_idx on met_values (movement_type) where movement_type is not null;
+create unique index if not exists met_values_default_idx on met_values ((true)) where exercise_id is null and movement_type is null;
+
+insert into met_values (movement_type, met) values
+    (null, 4.0),
+    ('compound', 5.0),
+    ('isolation', 3.5),
+    ('cardio', 7.0),
+    ('mobility', 2.5);
+
+insert into met_values (exercise_id, met)
+select id, met
+from exercises
+join (values
+    ('Running', 9.8),
+    ('Cycling', 7.5),
+    ('Rowing Machine', 7.0),
+    ('Jump Rope', 11.8),
+    ('Stretching', 2.3),
+    ('Plank', 3.8),
+    ('Push Up', 3.8),
+    ('Pull Up', 8.0)
+) as seeded (name, met) on lower(exercises.name) = lower(seeded.name)
+where exercises.user_id is null;
+
+alter table workouts
+    add column if not exists calories_estimated boolean not null default false,
+    add column if not exists calories_formula varchar(20);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table workouts
+    drop column if exists calories_formula,
+    drop column if exists calories_estimated;
+
+drop table if exists met_values;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- identifies workouts brought from another app, so importing the same file
+-- twice does not duplicate them
+alter table workouts add column if not exists import_key varchar(255);
+
+create unique index if not exists workouts_import_key_idx on workouts (user_id, import_key) where import_key is not null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists workouts_import_key_idx;
+alter table workouts drop column if exists import_key;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- cardio workouts imported from GPX/TCX files: the summary computed at import
+-- and the raw points, kept to render the route and recompute splits
+create table if not exists workout_tracks (
+    id serial primary key,
+    workout_id integer not null unique references workouts(id) on delete cascade,
+    sport varchar(20) not null,
+    source_format varchar(10) not null,
+    distance_km decimal(9, 3) not null,
+    duration_seconds integer not null,
+    average_pace decimal(8, 1),
+    best_pace decimal(8, 1),
+    elevation_gain decimal(7, 1) not null default 0,
+    points jsonb not null default '[]',
+    created_at timestamp with time zone not null default now(),
+
+    constraint valid_track_sport check (sport in ('running', 'cycling', 'walking', 'other')),
+    constraint valid_track_format check (source_format in ('gpx', 'tcx'))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists workout_tracks;
+-- +goose StatementEnd
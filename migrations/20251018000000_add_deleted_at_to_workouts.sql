@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- deleted workouts stay in the trash until restored or purged
+alter table workouts add column if not exists deleted_at timestamptz;
+
+create index if not exists workouts_deleted_at_idx on workouts (deleted_at) where deleted_at is not null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists workouts_deleted_at_idx;
+alter table workouts drop column if exists deleted_at;
+-- +goose StatementEnd
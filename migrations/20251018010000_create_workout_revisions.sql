@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every change to a workout keeps a full snapshot of it, entries and sets included
+create table if not exists workout_revisions (
+    id serial primary key,
+    workout_id integer not null references workouts(id) on delete cascade,
+    revision integer not null,
+    changed_by integer references users(id) on delete set null,
+    action varchar(20) not null,
+    snapshot jsonb not null,
+    created_at timestamp with time zone default current_timestamp,
+
+    constraint valid_revision_action check (action in ('create', 'update', 'revert', 'snapshot')),
+    unique (workout_id, revision)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists workout_revisions;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- user-defined labels such as "deload" or "travel"; names are unique per user regardless of case
+create table if not exists tags (
+    id serial primary key,
+    user_id integer not null references users(id) on delete cascade,
+    name varchar(50) not null,
+    created_at timestamp with time zone not null default now()
+);
+
+create unique index if not exists tags_user_id_name_idx on tags (user_id, lower(name));
+
+create table if not exists workout_tags (
+    workout_id integer not null references workouts(id) on delete cascade,
+    tag_id integer not null references tags(id) on delete cascade,
+
+    primary key (workout_id, tag_id)
+);
+
+create index if not exists workout_tags_tag_id_idx on workout_tags (tag_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists workout_tags;
+drop table if exists tags;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- users write in Portuguese and in English, so every text is indexed with both stemmers
+alter table workouts add column if not exists search_vector tsvector generated always as (
+    setweight(to_tsvector('portuguese', title), 'A') || setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('portuguese', description), 'B') || setweight(to_tsvector('english', description), 'B')
+) stored;
+
+alter table workout_entries add column if not exists search_vector tsvector generated always as (
+    setweight(to_tsvector('portuguese', exercise_name), 'A') || setweight(to_tsvector('english', exercise_name), 'A') ||
+    setweight(to_tsvector('portuguese', coalesce(notes, '')), 'B') || setweight(to_tsvector('english', coalesce(notes, '')), 'B')
+) stored;
+
+create index if not exists workouts_search_vector_idx on workouts using gin (search_vector);
+create index if not exists workout_entries_search_vector_idx on workout_entries using gin (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists workout_entries_search_vector_idx;
+drop index if exists workouts_search_vector_idx;
+alter table workout_entries drop column if exists search_vector;
+alter table workouts drop column if exists search_vector;
+-- +goose StatementEnd
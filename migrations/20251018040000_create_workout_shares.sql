@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- public links to a single workout; only the hash of the token is stored, like in tokens
+create table if not exists workout_shares (
+    id serial primary key,
+    hash bytea not null unique,
+    workout_id integer not null references workouts(id) on delete cascade,
+    user_id integer not null references users(id) on delete cascade,
+    expires_at timestamp with time zone,
+    revoked_at timestamp with time zone,
+    created_at timestamp with time zone not null default now()
+);
+
+create index if not exists workout_shares_user_id_idx on workout_shares (user_id) where revoked_at is null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists workout_shares;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- workouts without a visibility of their own follow the default of the account
+alter table users add column if not exists default_visibility varchar(20) not null default 'private'
+    check (default_visibility in ('public', 'followers', 'private'));
+
+alter table workouts add column if not exists visibility varchar(20)
+    check (visibility in ('public', 'followers', 'private'));
+
+create table if not exists follows (
+    follower_id integer not null references users(id) on delete cascade,
+    followee_id integer not null references users(id) on delete cascade,
+    created_at timestamp with time zone not null default now(),
+
+    primary key (follower_id, followee_id),
+    check (follower_id <> followee_id)
+);
+
+create index if not exists follows_followee_id_idx on follows (followee_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists follows;
+alter table workouts drop column if exists visibility;
+alter table users drop column if exists default_visibility;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the conversion of a history logged in pounds runs once per account
+alter table users add column if not exists weights_converted_at timestamp with time zone;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table users drop column if exists weights_converted_at;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a follow only counts once the followed user approves it; the follows that
+-- already exist are kept as approved so no feed empties on deploy
+alter table follows add column if not exists status varchar(20) not null default 'approved'
+    check (status in ('pending', 'approved'));
+
+alter table follows alter column status set default 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table follows drop column if exists status;
+-- +goose StatementEnd
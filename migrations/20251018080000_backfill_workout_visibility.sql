@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every workout keeps the account default it was created under, so changing the
+-- default later does not publish the history
+update workouts set visibility = users.default_visibility
+from users
+where users.id = workouts.user_id and workouts.visibility is null;
+
+alter table workouts alter column visibility set not null;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+alter table workouts alter column visibility drop not null;
+-- +goose StatementEnd